@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 
@@ -19,6 +20,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // @title						product
@@ -60,6 +62,14 @@ func main() {
 
 	l.Info("Successfully connected to the database")
 
+	// Run Migrations
+	err = db.RunMigrations(ctx, &config.Database)
+	if err != nil {
+		l.Error("Error running database migrations", zap.Error(err))
+		os.Exit(1)
+	}
+	l.Info("Successfully run database migrations")
+
 	// Init cache service
 	cache, err := redis.New(ctx, &config.Redis)
 	if err != nil {
@@ -70,8 +80,13 @@ func main() {
 
 	l.Info("Successfully connected to the cache server")
 
-	// Init token service
-	tokenService := jwt.New(&config.Token)
+	// Init token service. Tokens are checked with the owner-service as well, which knows the revoked ones
+	tokenService, err := service.NewTokenService(jwt.New(&config.Token), &config.Discovery)
+	if err != nil {
+		l.Error("Error initializing token service", zap.Error(err))
+		os.Exit(1)
+	}
+	defer tokenService.Close()
 
 	// Dependency injection
 	// Ping
@@ -111,6 +126,39 @@ func main() {
 	l.Info("Starting consumer on", zap.String("queue", queue))
 	go consumer1.Consume(ctx, queue, orderService.UpdateOrdersFromQueue)
 
+	// Message Queue Consumer 2
+	consumer2, err := rabbitmq.New(ctx, &config.Rabbitmq)
+	if err != nil {
+		l.Error("Error initializing Message Broker consumer", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Start consumer
+	eventsQueue := "product-events"
+	l.Info("Starting consumer on", zap.String("queue", eventsQueue))
+	go consumer2.Consume(ctx, eventsQueue, orderService.HandleProductEventFromQueue)
+
+	// Products bought together
+	go orderService.RunCoPurchaseJob(ctx)
+
+	// Init GRPC server
+	grpcListAddr := fmt.Sprintf("%s:%s", config.Server.GrpcUrl, config.Server.GrpcPort)
+	list, err := net.Listen("tcp", grpcListAddr)
+	if err != nil {
+		l.Error("Error listening for the GRPC server", zap.Error(err))
+		os.Exit(1)
+	}
+	l.Info("Starting the GRPC server", zap.String("listen_address", list.Addr().String()))
+
+	server, err := service.NewGRPCServer(&service.Config{Clients: config.Grpc.Clients}, orderRepo, grpc.EmptyServerOption{})
+	if err != nil {
+		l.Error("Error initializing the GRPC server", zap.Error(err))
+		os.Exit(1)
+	}
+	go func() {
+		l.Error("Error starting grpc server", zap.Error(server.Serve(list)))
+	}()
+
 	// Start server
 	listenAddr := fmt.Sprintf("%s:%s", config.Server.HttpUrl, config.Server.HttpPort)
 	l.Info("Starting the HTTP server", zap.String("listen_address", listenAddr))
@@ -1,5 +1,7 @@
 package config
 
+import "pkg/grpcauth"
+
 type RedisConfiguration struct {
 	Address  string
 	Password string
@@ -20,6 +22,8 @@ type ServerConfiguration struct {
 	HttpUrl            string
 	HttpPort           string
 	HttpAllowedOrigins string
+	GrpcUrl            string
+	GrpcPort           string
 }
 
 type AppConfiguration struct {
@@ -36,6 +40,10 @@ type TokenConfiguration struct {
 type DiscoveryConfiguration struct {
 	OwnerUrl   string
 	ProductUrl string
+	// OwnerToken authenticates the service with the gRPC server of the owner-service
+	OwnerToken string
+	// ProductToken authenticates the service with the gRPC server of the product-service
+	ProductToken string
 }
 
 type RabbitMqConfiguration struct {
@@ -44,6 +52,16 @@ type RabbitMqConfiguration struct {
 	Host     string
 }
 
+type SchedulerConfiguration struct {
+	// CoPurchaseInterval is how often the products bought together are computed again
+	CoPurchaseInterval string
+}
+
+type GrpcConfiguration struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
+}
+
 type Configuration struct {
 	App       AppConfiguration
 	Server    ServerConfiguration
@@ -51,5 +69,7 @@ type Configuration struct {
 	Redis     RedisConfiguration
 	Token     TokenConfiguration
 	Discovery DiscoveryConfiguration
+	Grpc      GrpcConfiguration
 	Rabbitmq  RabbitMqConfiguration
+	Scheduler SchedulerConfiguration
 }
@@ -145,6 +145,7 @@ func adminMiddleware(next http.Handler, token port.TokenService, logger *zap.Log
 		email, role := identifier[0], identifier[len(identifier)-1]
 		if role != domain.RAdmin {
 			handleError(w, domain.ErrInvalidToken)
+			return
 		}
 
 		// Set details from token in context
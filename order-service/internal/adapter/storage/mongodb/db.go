@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"order-service/internal/adapter/config"
+	"pkg/migrate"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -80,3 +81,33 @@ func (db *DB) Close() {
 func (db *DB) Url() string {
 	return db.url
 }
+
+// RunMigrations applies all pending migrations to the service database
+func (db *DB) RunMigrations(ctx context.Context, config *config.DatabaseConfiguration) error {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// RollbackMigrations rolls back the service database to the target migration version
+func (db *DB) RollbackMigrations(ctx context.Context, config *config.DatabaseConfiguration, target int64) error {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Down(ctx, target)
+}
+
+// MigrationVersion returns the latest migration version applied to the service database
+func (db *DB) MigrationVersion(ctx context.Context, config *config.DatabaseConfiguration) (int64, error) {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	return migrator.Version(ctx)
+}
@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"context"
+
+	"pkg/migrate"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists every migration of the service database. Append new migrations
+// with the next version and never change one that has been released
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "create order and order item indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Orders of a user, newest first
+			err := migrate.CreateIndexes("orders", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+					Options: options.Index().SetName("user_id_created_at_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return migrate.CreateIndexes("orderItems", []mongo.IndexModel{
+				// Items lookup of an order
+				{
+					Keys:    bson.D{{Key: "order_id", Value: 1}},
+					Options: options.Index().SetName("order_id_index"),
+				},
+				// Product name updates from the queue
+				{
+					Keys:    bson.D{{Key: "product_id", Value: 1}},
+					Options: options.Index().SetName("product_id_index"),
+				},
+			})(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := migrate.DropIndexes("orderItems", "order_id_index", "product_id_index")(ctx, db); err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("orders", "user_id_created_at_index")(ctx, db)
+		},
+	},
+	{
+		Version:     2,
+		Description: "create order status index",
+		Up: migrate.CreateIndexes("orders", []mongo.IndexModel{
+			// Delivered orders are read to compute the products bought together
+			{
+				Keys:    bson.D{{Key: "status", Value: 1}},
+				Options: options.Index().SetName("status_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("orders", "status_index"),
+	},
+}
@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 /**
@@ -18,15 +19,17 @@ import (
  * and provides an access to the MongoDB database
  */
 type OrderRepository struct {
-	ordersCol *mongo.Collection
-	itemsCol  *mongo.Collection
+	ordersCol      *mongo.Collection
+	itemsCol       *mongo.Collection
+	coPurchasesCol *mongo.Collection
 }
 
 // NewOrderRepository creates a new order repository instance
 func NewOrderRepository(db *mongodb.DB) *OrderRepository {
 	return &OrderRepository{
-		ordersCol: db.Client.Database(config.GetConfig().Database.Name).Collection("orders"),
-		itemsCol:  db.Client.Database(config.GetConfig().Database.Name).Collection("orderItems"),
+		ordersCol:      db.Client.Database(config.GetConfig().Database.Name).Collection("orders"),
+		itemsCol:       db.Client.Database(config.GetConfig().Database.Name).Collection("orderItems"),
+		coPurchasesCol: db.Client.Database(config.GetConfig().Database.Name).Collection("coPurchases"),
 	}
 }
 
@@ -46,7 +49,9 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, order *domain.Order)
 			return err
 		}
 
-		order.ID = primitive.NewObjectID()
+		if order.ID.IsZero() {
+			order.ID = primitive.NewObjectID()
+		}
 		order.CreatedAt = time.Now()
 		order.UpdatedAt = time.Now()
 
@@ -195,3 +200,125 @@ func (or *OrderRepository) UpdateOrderProducts(ctx context.Context, prod *domain
 
 	return res.MatchedCount, nil
 }
+
+func (or *OrderRepository) MarkOrderProductsDeleted(ctx context.Context, productID primitive.ObjectID) (int64, domain.CError) {
+	filter := bson.M{"product_id": productID}
+	update := bson.M{
+		"$set": bson.M{
+			"product_deleted": true,
+		},
+	}
+
+	res, err := or.itemsCol.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return -1, domain.NewInternalCError("error updating order: " + err.Error())
+	}
+
+	return res.MatchedCount, nil
+}
+
+func (or *OrderRepository) HasDeliveredProduct(ctx context.Context, userID, productID primitive.ObjectID) (bool, domain.CError) {
+	filter := bson.M{"user_id": userID, "status": domain.OrderStatusDelivered}
+	cursor, err := or.ordersCol.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return false, domain.NewInternalCError("error finding orders: " + err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	var orders []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &orders); err != nil {
+		return false, domain.NewInternalCError("error decoding orders: " + err.Error())
+	}
+
+	if len(orders) == 0 {
+		return false, nil
+	}
+
+	orderIDs := make([]primitive.ObjectID, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+	}
+
+	count, err := or.itemsCol.CountDocuments(ctx, bson.M{"order_id": bson.M{"$in": orderIDs}, "product_id": productID}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, domain.NewInternalCError("error counting order items: " + err.Error())
+	}
+
+	return count > 0, nil
+}
+
+// ComputeCoPurchases counts, for every pair of products, the delivered orders containing both, and keeps
+// the products most often bought with each product. The results replace the previous ones at once when
+// they are complete, so they are never read half computed
+func (or *OrderRepository) ComputeCoPurchases(ctx context.Context) domain.CError {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": domain.OrderStatusDelivered}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "orderItems",
+			"localField":   "_id",
+			"foreignField": "order_id",
+			"as":           "items",
+		}}},
+		// The distinct products of each order, leaving out the deleted ones
+		{{Key: "$project", Value: bson.M{
+			"_id": 0,
+			"products": bson.M{"$setUnion": bson.A{
+				bson.M{"$map": bson.M{
+					"input": bson.M{"$filter": bson.M{
+						"input": "$items",
+						"cond":  bson.M{"$ne": bson.A{"$$this.product_deleted", true}},
+					}},
+					"in": "$$this.product_id",
+				}},
+				bson.A{},
+			}},
+		}}},
+		{{Key: "$match", Value: bson.M{"products.1": bson.M{"$exists": true}}}},
+		// Every ordered pair of distinct products of an order
+		{{Key: "$project", Value: bson.M{"product_id": "$products", "related_id": "$products"}}},
+		{{Key: "$unwind", Value: "$product_id"}},
+		{{Key: "$unwind", Value: "$related_id"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$ne": bson.A{"$product_id", "$related_id"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"product_id": "$product_id", "related_id": "$related_id"},
+			"orders": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.product_id", Value: 1}, {Key: "orders", Value: -1}, {Key: "_id.related_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$_id.product_id",
+			"related": bson.M{"$push": bson.M{"product_id": "$_id.related_id", "orders": "$orders"}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"related":     bson.M{"$slice": bson.A{"$related", domain.MaxCoPurchases}},
+			"computed_at": time.Now(),
+		}}},
+		{{Key: "$out", Value: or.coPurchasesCol.Name()}},
+	}
+
+	cursor, err := or.ordersCol.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return domain.NewInternalCError("error computing co-purchases: " + err.Error())
+	}
+	if err := cursor.Close(ctx); err != nil {
+		return domain.NewInternalCError("error computing co-purchases: " + err.Error())
+	}
+
+	return nil
+}
+
+func (or *OrderRepository) ListCoPurchases(ctx context.Context, productID primitive.ObjectID, limit int) ([]domain.CoPurchase, domain.CError) {
+	var coPurchases domain.CoPurchases
+
+	opts := options.FindOne().SetProjection(bson.M{"related": bson.M{"$slice": limit}})
+	err := or.coPurchasesCol.FindOne(ctx, bson.M{"_id": productID}, opts).Decode(&coPurchases)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return []domain.CoPurchase{}, nil
+		}
+		return nil, domain.NewInternalCError("error finding co-purchases: " + err.Error())
+	}
+
+	return coPurchases.Related, nil
+}
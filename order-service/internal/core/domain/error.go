@@ -48,6 +48,8 @@ var (
 	ErrInternal = NewCError(http.StatusInternalServerError, "internal server error")
 	// ErrDataNotFound is an error for when requested data is not found
 	ErrDataNotFound = NewCError(http.StatusNotFound, "data not found")
+	// ErrUserNotFound is an error for when a user does not exist or was deleted
+	ErrUserNotFound = NewCError(http.StatusNotFound, "user not found")
 	// ErrConflictingData is an error for when data conflicts with existing data
 	ErrConflictingData = NewCError(http.StatusConflict, "data conflicts with existing data in unique column")
 	// ErrForeignKeyViolation is an error for when there is a foreign key violation
@@ -60,6 +62,10 @@ var (
 	ErrExpiredToken = NewUnauthorizedCError("access token has expired")
 	// ErrInvalidToken is an error for when the access token is invalid
 	ErrInvalidToken = NewUnauthorizedCError("access token is invalid")
+	// ErrRevokedToken is an error for when the access token was revoked by a password change or an account deletion
+	ErrRevokedToken = NewUnauthorizedCError("access token has been revoked")
+	// ErrAuthUnavailable is an error for when the access token cannot be checked with the owner-service
+	ErrAuthUnavailable = NewCError(http.StatusServiceUnavailable, "could not check the access token, try again later")
 	// ErrEmptyAuthorizationHeader is an error for when the authorization header is empty
 	ErrEmptyAuthorizationHeader = NewUnauthorizedCError("authorization header is not provided")
 	// ErrInvalidAuthorizationHeader is an error for when the authorization header is invalid
@@ -52,14 +52,33 @@ type OrderItem struct {
 	OrderID     primitive.ObjectID `json:"order_id" bson:"order_id"`
 	ProductID   primitive.ObjectID `json:"product_id" bson:"product_id"`
 	ProductName string             `json:"product_name" bson:"product_name"`
-	Quantity    int32              `json:"quantity" bson:"quantity"`
-	UnitPrice   float64            `json:"unit_price" bson:"unit_price"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	// VariantID, SKU and VariantOptions record the variant that was bought, when the product has variants
+	VariantID      *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	SKU            string              `json:"sku,omitempty" bson:"sku,omitempty"`
+	VariantOptions map[string]string   `json:"variant_options,omitempty" bson:"variant_options,omitempty"`
+	Quantity       int32               `json:"quantity" bson:"quantity"`
+	UnitPrice      float64             `json:"unit_price" bson:"unit_price"`
+	// Components are the products of a bundle, with the quantity of each in the whole item
+	Components []OrderItemComponent `json:"components,omitempty" bson:"components,omitempty"`
+	// ProductDeleted is set once the product has been deleted from the product-service
+	ProductDeleted bool      `json:"product_deleted,omitempty" bson:"product_deleted,omitempty"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// OrderItemComponent is a product, or one of its variants, in a bundle that was bought
+type OrderItemComponent struct {
+	ProductID   primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID   *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	ProductName string              `json:"product_name" bson:"product_name"`
+	SKU         string              `json:"sku,omitempty" bson:"sku,omitempty"`
+	Quantity    int32               `json:"quantity" bson:"quantity"`
 }
 
 type ProductInfo struct {
 	ProductID string `json:"product_id" validate:"required"`
+	// VariantID is required for products that have variants
+	VariantID string `json:"variant_id" validate:"omitempty,mongodb"`
 	Quantity  int    `json:"quantity" validate:"required,gte=1"`
 }
 
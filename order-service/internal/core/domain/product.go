@@ -1,5 +1,7 @@
 package domain
 
+import "encoding/json"
+
 type ProductStatus int32
 
 type ProductUpdateFromQueue struct {
@@ -17,3 +19,18 @@ type ProductUpdateFromQueue struct {
 	UpdatedAt     string        `json:"updated_at,omitempty"`
 	NameIsUpdated bool          `json:"name_is_updated"`
 }
+
+// Types of the events received from the "product-events" queue
+const (
+	EventProductCreated = "product.created"
+	EventProductDeleted = "product.deleted"
+)
+
+// ProductEvent is a message received from the "product-events" queue. Product holds the created
+// product of a product.created event, in the form it is cached in, and ProductID the product of a
+// product.deleted event
+type ProductEvent struct {
+	Event     string          `json:"event"`
+	Product   json.RawMessage `json:"product,omitempty"`
+	ProductID string          `json:"product_id,omitempty"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxCoPurchases is the number of products kept as frequently bought with each product
+	MaxCoPurchases = 50
+	// DefaultCoPurchases is the number of frequently bought products returned when no limit is asked for
+	DefaultCoPurchases = 10
+)
+
+// CoPurchases are the products most often in the same delivered orders as a product, from the most
+// often. They are computed again periodically from all delivered orders
+type CoPurchases struct {
+	ProductID  primitive.ObjectID `json:"product_id" bson:"_id"`
+	Related    []CoPurchase       `json:"related" bson:"related"`
+	ComputedAt time.Time          `json:"computed_at" bson:"computed_at"`
+}
+
+// CoPurchase is a product bought together with another one
+type CoPurchase struct {
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	// Orders is the number of delivered orders containing both products
+	Orders int64 `json:"orders" bson:"orders"`
+}
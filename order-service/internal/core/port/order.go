@@ -20,6 +20,14 @@ type OrderRepository interface {
 	UpdateOrder(ctx context.Context, order *domain.Order) (*domain.Order, domain.CError)
 	// UpdateOrderProductsupdates the name of all order items with the prod
 	UpdateOrderProducts(ctx context.Context, prod *domain.ProductUpdateFromQueue) (int64, domain.CError)
+	// MarkOrderProductsDeleted marks the order items of a product as deleted
+	MarkOrderProductsDeleted(ctx context.Context, productID primitive.ObjectID) (int64, domain.CError)
+	// HasDeliveredProduct reports whether a user has a delivered order containing a product
+	HasDeliveredProduct(ctx context.Context, userID, productID primitive.ObjectID) (bool, domain.CError)
+	// ComputeCoPurchases computes again the products bought together from all delivered orders
+	ComputeCoPurchases(ctx context.Context) domain.CError
+	// ListCoPurchases returns at most limit products most often bought together with a product
+	ListCoPurchases(ctx context.Context, productID primitive.ObjectID, limit int) ([]domain.CoPurchase, domain.CError)
 }
 
 // OrderService is an interface for interacting with order-related business logic
@@ -9,6 +9,7 @@ import (
 	"order-service/internal/adapter/logger"
 	"order-service/internal/core/domain"
 	"order-service/internal/core/port"
+	"order-service/internal/core/service/product"
 	"order-service/internal/core/util"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,6 +23,8 @@ type OrderService struct {
 	repo     port.OrderRepository
 	cache    port.CacheRepository
 	cacheTtl time.Duration
+	// coPurchaseInterval is how often the products bought together are computed again
+	coPurchaseInterval time.Duration
 }
 
 // NewOrderService creates a new order service instance
@@ -36,27 +39,50 @@ func NewOrderService(
 		cacheTtl = 24 * time.Hour
 	}
 
+	coPurchaseInterval, err := time.ParseDuration(config.GetConfig().Scheduler.CoPurchaseInterval)
+	if err != nil || coPurchaseInterval <= 0 {
+		zap.L().Info("Error parsing co-purchase interval, defaulting to 1h", zap.Error(err))
+		coPurchaseInterval = time.Hour
+	}
+
 	return &OrderService{
 		repo,
 		cache,
 		cacheTtl,
+		coPurchaseInterval,
 	}
 }
 
 func (os *OrderService) PlaceOrder(ctx context.Context, userId primitive.ObjectID, req *domain.CreateOrderRequest) (*domain.Order, domain.CError) {
 	log := logger.FromCtx(ctx)
-	_, err := os.GetUser(ctx, userId)
+	exists, err := os.GetUser(ctx, userId)
 	if err != nil {
 		log.Error("Error fetching user", zap.Error(err))
 		return nil, domain.ErrInternal
 	}
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
 
-	var productIDMap = make(map[string]int)
+	// Quantities are keyed by product and variant so a product can be ordered in several variants
+	var quantities = make(map[orderLine]int)
+	var lines = make([]orderLine, 0, len(req.Products))
+	var seenProducts = make(map[string]bool)
 	validProductIDs := make([]string, 0)
 	for _, v := range req.Products {
-		if _, err := primitive.ObjectIDFromHex(v.ProductID); err == nil {
+		if _, err := primitive.ObjectIDFromHex(v.ProductID); err != nil {
+			continue
+		}
+
+		line := orderLine{productID: v.ProductID, variantID: v.VariantID}
+		if _, ok := quantities[line]; !ok {
+			lines = append(lines, line)
+		}
+		quantities[line] += v.Quantity
+
+		if !seenProducts[v.ProductID] {
+			seenProducts[v.ProductID] = true
 			validProductIDs = append(validProductIDs, v.ProductID)
-			productIDMap[v.ProductID] = v.Quantity
 		}
 	}
 
@@ -66,7 +92,14 @@ func (os *OrderService) PlaceOrder(ctx context.Context, userId primitive.ObjectI
 		return nil, domain.ErrInternal
 	}
 
-	if len(products) == 0 {
+	productMap := make(map[string]*product.ProductResponse, len(products))
+	for _, v := range products {
+		if v != nil {
+			productMap[v.Id] = v
+		}
+	}
+
+	if len(productMap) == 0 {
 		return nil, domain.NewBadRequestCError("none of the products specified was found")
 	}
 
@@ -76,34 +109,81 @@ func (os *OrderService) PlaceOrder(ctx context.Context, userId primitive.ObjectI
 	var totalAmount float64
 	var orderItems = make([]domain.OrderItem, 0)
 
-	for _, v := range products {
-		quantityOrdered := productIDMap[v.Id]
-
-		if int(v.Quantity)-quantityOrdered < 0 {
-			errMsg := fmt.Sprintf("The quantity specified for '%s' is more than the quantity in stock: %v (specified) for %v (in stock)",
-				v.Name, productIDMap[v.Id], v.Quantity)
-			return nil, domain.NewBadRequestCError(errMsg)
+	for _, line := range lines {
+		v, ok := productMap[line.productID]
+		if !ok {
+			continue
 		}
-
-		totalAmount += (v.Price * float64(quantityOrdered))
+		// A cached product can lag behind its deactivation or deletion, and the product-service
+		// refuses to reserve it as well
+		if v.Status == product.ProductStatus_PRODUCT_STATUS_INACTIVE {
+			return nil, domain.NewBadRequestCError(fmt.Sprintf("'%s' is not available", v.Name))
+		}
+		quantityOrdered := quantities[line]
 		productId, _ := primitive.ObjectIDFromHex(v.Id)
-		orderItems = append(orderItems, domain.OrderItem{
+
+		item := domain.OrderItem{
 			ProductID:   productId,
 			ProductName: v.Name,
 			Quantity:    int32(quantityOrdered),
 			UnitPrice:   v.Price,
-		})
+		}
+		inStock := v.Quantity
+		name := v.Name
+
+		if len(v.Variants) > 0 || line.variantID != "" {
+			variant := findVariant(v, line.variantID)
+			if variant == nil {
+				if line.variantID == "" {
+					return nil, domain.NewBadRequestCError(fmt.Sprintf("A variant must be specified for '%s'", v.Name))
+				}
+				return nil, domain.NewBadRequestCError(fmt.Sprintf("The variant '%s' was not found for '%s'", line.variantID, v.Name))
+			}
+
+			variantId, _ := primitive.ObjectIDFromHex(variant.Id)
+			item.VariantID = &variantId
+			item.SKU = variant.Sku
+			item.VariantOptions = variant.Options
+			item.UnitPrice = variant.Price
+			inStock = variant.Quantity
+			name = fmt.Sprintf("%s (%s)", v.Name, variant.Sku)
+		}
+
+		if v.Status == product.ProductStatus_PRODUCT_STATUS_OUT_OF_STOCK || inStock <= 0 {
+			return nil, domain.NewBadRequestCError(fmt.Sprintf("'%s' is out of stock", name))
+		}
+
+		if int(inStock)-quantityOrdered < 0 {
+			errMsg := fmt.Sprintf("The quantity specified for '%s' is more than the quantity in stock: %v (specified) for %v (in stock)",
+				name, quantityOrdered, inStock)
+			return nil, domain.NewBadRequestCError(errMsg)
+		}
+
+		// The product-service reserves a bundle as the stock of its components
+		item.Components = bundleComponents(v, item.Quantity)
+
+		totalAmount += (item.UnitPrice * float64(quantityOrdered))
+		orderItems = append(orderItems, item)
 	}
 
 	order := domain.Order{
+		ID:          primitive.NewObjectID(),
 		UserID:      userId,
 		TotalAmount: totalAmount,
 		OrderItems:  orderItems,
 		Status:      domain.OrderStatusPending,
 	}
 
+	// Stock is reserved under the order id before the order is saved, and given back if saving fails
+	if cerr := os.reserveStock(ctx, &order); cerr != nil {
+		return nil, cerr
+	}
+
 	retOrder, cerr := os.repo.CreateOrder(ctx, &order)
 	if cerr != nil {
+		if rerr := os.releaseStock(ctx, &order); rerr != nil {
+			log.Error("Could not release the stock of an order that failed to save", zap.String("order_id", order.ID.Hex()), zap.Error(rerr))
+		}
 		if cerr.Code() == 500 {
 			logger.FromCtx(ctx).Error("Error placing orders", zap.Error(cerr))
 			return nil, domain.ErrInternal
@@ -114,6 +194,54 @@ func (os *OrderService) PlaceOrder(ctx context.Context, userId primitive.ObjectI
 	return retOrder, nil
 }
 
+// releaseCancelledStock gives back the stock of a cancelled order. The order stays cancelled when this
+// fails, and since releasing is idempotent in the product-service it can safely be retried
+func (os *OrderService) releaseCancelledStock(ctx context.Context, order *domain.Order) {
+	if cerr := os.releaseStock(ctx, order); cerr != nil {
+		logger.FromCtx(ctx).Error("Could not release the stock of a cancelled order", zap.String("order_id", order.ID.Hex()), zap.Error(cerr))
+	}
+}
+
+// orderLine identifies a line of an order by its product and, for products with variants, its variant
+type orderLine struct {
+	productID string
+	variantID string
+}
+
+// bundleComponents returns the components of a bundle for an order item of the quantity. Products that
+// are not bundles have none
+func bundleComponents(prod *product.ProductResponse, quantity int32) []domain.OrderItemComponent {
+	if len(prod.Components) == 0 {
+		return nil
+	}
+
+	components := make([]domain.OrderItemComponent, 0, len(prod.Components))
+	for _, c := range prod.Components {
+		productID, _ := primitive.ObjectIDFromHex(c.ProductId)
+		component := domain.OrderItemComponent{
+			ProductID:   productID,
+			ProductName: c.Name,
+			SKU:         c.Sku,
+			Quantity:    c.Quantity * quantity,
+		}
+		if variantID, err := primitive.ObjectIDFromHex(c.VariantId); err == nil {
+			component.VariantID = &variantID
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// findVariant returns the variant of the product with the id, or nil when there is none
+func findVariant(prod *product.ProductResponse, variantID string) *product.ProductVariant {
+	for _, v := range prod.Variants {
+		if v.Id == variantID {
+			return v
+		}
+	}
+	return nil
+}
+
 func (os *OrderService) GetOrder(ctx context.Context, id primitive.ObjectID) (*domain.Order, domain.CError) {
 	order, cerr := os.repo.GetOrder(ctx, id)
 	if cerr != nil {
@@ -166,6 +294,10 @@ func (os *OrderService) UpdateOrderStatus(ctx context.Context, orderId primitive
 		return nil, cerr
 	}
 
+	if order.Status == domain.OrderStatusCancelled && retOrder.Status != domain.OrderStatusCancelled {
+		os.releaseCancelledStock(ctx, retOrder)
+	}
+
 	retOrder.Status = order.Status // Add the updated status to the order struct to be returned
 	return retOrder, nil
 }
@@ -195,6 +327,8 @@ func (os *OrderService) CancelOrder(ctx context.Context, id primitive.ObjectID)
 		return nil, cerr
 	}
 
+	os.releaseCancelledStock(ctx, retOrder)
+
 	retOrder.Status = order.Status // Add the updated status to the order struct to be returned
 	return retOrder, nil
 }
@@ -232,3 +366,61 @@ func (os *OrderService) UpdateOrdersFromQueue(log *zap.Logger, msg []byte) error
 
 	return nil
 }
+
+// HandleProductEventFromQueue keeps the cached products and the orders in step with products
+// created and deleted in the product-service
+func (os *OrderService) HandleProductEventFromQueue(log *zap.Logger, msg []byte) error {
+	log.Info("Received a new message", zap.String("event", string(msg)))
+	ctx := context.Background()
+
+	var event domain.ProductEvent
+	err := util.Deserialize(msg, &event)
+	if err != nil {
+		log.Error("Could not deserialize product event", zap.Error(err))
+		return err
+	}
+
+	switch event.Event {
+	case domain.EventProductCreated:
+		var prod domain.ProductUpdateFromQueue
+		if err := util.Deserialize(event.Product, &prod); err != nil {
+			log.Error("Could not deserialize the created product", zap.Error(err))
+			return err
+		}
+
+		log.Info("Saving the created product to cache...", zap.String("product_id", prod.Id))
+		cacheKey := util.GenerateCacheKey("product", prod.Id)
+		if err := os.cache.Set(ctx, cacheKey, event.Product, os.cacheTtl); err != nil {
+			log.Error("Could not save the created product in cache", zap.Error(err))
+		}
+
+	case domain.EventProductDeleted:
+		productID, err := primitive.ObjectIDFromHex(event.ProductID)
+		if err != nil {
+			// The event can never be handled, so it is dropped instead of requeued
+			log.Error("Received product.deleted event with an invalid product id", zap.String("product_id", event.ProductID))
+			return nil
+		}
+
+		// The cached product is evicted first, so orders cannot be placed for it while the orders are updated
+		log.Info("Evicting the deleted product from cache...", zap.String("product_id", event.ProductID))
+		cacheKey := util.GenerateCacheKey("product", event.ProductID)
+		if err := os.cache.Delete(ctx, cacheKey); err != nil {
+			log.Error("Could not evict the deleted product from cache", zap.Error(err))
+			return err
+		}
+
+		updatedOrderItems, cerr := os.repo.MarkOrderProductsDeleted(ctx, productID)
+		if cerr != nil {
+			log.Error("Could not mark the deleted product in orders", zap.Error(cerr))
+			return cerr
+		}
+
+		log.Info(fmt.Sprintf("Successfully marked %v order items of the deleted product", updatedOrderItems))
+
+	default:
+		log.Warn("Ignoring product event of unknown type", zap.String("event", event.Event))
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"order-service/internal/core/domain"
+	"order-service/internal/core/port"
+	"order-service/internal/core/service/order"
+	"pkg/grpcauth"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ order.OrderServer = (*grpcServer)(nil)
+
+type Config struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
+}
+
+type grpcServer struct {
+	order.UnimplementedOrderServer
+	config    *Config
+	orderRepo port.OrderRepository
+}
+
+func NewGRPCServer(config *Config, orderRepo port.OrderRepository, opts ...grpc.ServerOption) (*grpc.Server, error) {
+
+	logger := zap.L().Named("grpc_server")
+	zapOpts := []grpc_zap.Option{
+		grpc_zap.WithDurationField(
+			func(duration time.Duration) zapcore.Field {
+				return zap.Int64(
+					"grpc.time_ns",
+					duration.Nanoseconds(),
+				)
+			},
+		),
+	}
+
+	opts = append(opts,
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
+			grpc_ctxtags.UnaryServerInterceptor(),
+			grpc_zap.UnaryServerInterceptor(logger, zapOpts...),
+			grpcauth.UnaryServerInterceptor(config.Clients),
+		)),
+	)
+
+	gsrv := grpc.NewServer(opts...)
+	srv, err := newgrpcServer(config, orderRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	order.RegisterOrderServer(gsrv, srv)
+	return gsrv, nil
+}
+
+func newgrpcServer(config *Config, orderRepo port.OrderRepository) (srv *grpcServer, err error) {
+	srv = &grpcServer{
+		config:    config,
+		orderRepo: orderRepo,
+	}
+	return srv, nil
+}
+
+// HasDeliveredProduct reports whether a user has a delivered order containing a product. The product
+// service asks it before letting a user review a product
+func (s *grpcServer) HasDeliveredProduct(ctx context.Context, req *order.DeliveredProductRequest) (*order.DeliveredProductResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received HasDeliveredProduct request", zap.String("user_id", req.UserId), zap.String("product_id", req.ProductId))
+
+	userID, err := primitive.ObjectIDFromHex(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	productID, err := primitive.ObjectIDFromHex(req.ProductId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid product id")
+	}
+
+	delivered, cerr := s.orderRepo.HasDeliveredProduct(ctx, userID, productID)
+	if cerr != nil {
+		logger.Error("Failed to check delivered orders", zap.Error(cerr))
+		return nil, status.Error(codes.Internal, cerr.Error())
+	}
+
+	return &order.DeliveredProductResponse{Delivered: delivered}, nil
+}
+
+// FrequentlyBoughtTogether lists the products most often in the same delivered orders as a product,
+// from the most often. The product service filters them to the products that can be bought
+func (s *grpcServer) FrequentlyBoughtTogether(ctx context.Context, req *order.FrequentlyBoughtTogetherRequest) (*order.FrequentlyBoughtTogetherResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received FrequentlyBoughtTogether request", zap.String("product_id", req.ProductId), zap.Int32("limit", req.Limit))
+
+	productID, err := primitive.ObjectIDFromHex(req.ProductId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid product id")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = domain.DefaultCoPurchases
+	}
+	limit = min(limit, domain.MaxCoPurchases)
+
+	coPurchases, cerr := s.orderRepo.ListCoPurchases(ctx, productID, limit)
+	if cerr != nil {
+		logger.Error("Failed to list co-purchases", zap.Error(cerr))
+		return nil, status.Error(codes.Internal, cerr.Error())
+	}
+
+	resp := &order.FrequentlyBoughtTogetherResponse{
+		Products: make([]*order.CoPurchasedProduct, 0, len(coPurchases)),
+	}
+	for _, c := range coPurchases {
+		resp.Products = append(resp.Products, &order.CoPurchasedProduct{
+			ProductId: c.ProductID.Hex(),
+			Orders:    c.Orders,
+		})
+	}
+
+	return resp, nil
+}
@@ -8,18 +8,24 @@ import (
 	"order-service/internal/core/domain"
 	"order-service/internal/core/service/product"
 	"order-service/internal/core/util"
+	"pkg/grpcauth"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 var cacheDuraction time.Duration
 
 func newProductClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, product.ProductClient, error) {
-	conn, err := grpc.NewClient(conf.ProductUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(conf.ProductUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(grpcauth.Token(conf.ProductToken)),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create a new connection: %w", err)
 	}
@@ -195,3 +201,77 @@ func (os *OrderService) saveProductsToCache(products map[string]*product.Product
 
 	return os.cache.MSet(context.Background(), pMap, os.cacheTtl)
 }
+
+// reserveStock reserves the stock of the order items with the product-service
+func (os *OrderService) reserveStock(ctx context.Context, order *domain.Order) domain.CError {
+	log := logger.FromCtx(ctx)
+
+	pConn, pClient, err := newProductClient(&config.GetConfig().Discovery)
+	if err != nil {
+		log.Error("Error creating product client", zap.Error(err))
+		return domain.ErrInternal
+	}
+	defer pConn.Close()
+
+	lines := make([]*product.StockLine, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		line := &product.StockLine{ProductId: item.ProductID.Hex(), Quantity: item.Quantity}
+		if item.VariantID != nil {
+			line.VariantId = item.VariantID.Hex()
+		}
+		lines = append(lines, line)
+	}
+
+	log.Info("Making request to reserve stock", zap.String("order_id", order.ID.Hex()))
+	_, err = pClient.ReserveStock(ctx, &product.ReserveStockRequest{
+		OrderId: order.ID.Hex(),
+		UserId:  order.UserID.Hex(),
+		Lines:   lines,
+	})
+	if err != nil {
+		log.Error("Error reserving stock", zap.Error(err))
+		return cerrorFromStatus(err)
+	}
+
+	return nil
+}
+
+// releaseStock gives back the stock reserved for the order to the product-service
+func (os *OrderService) releaseStock(ctx context.Context, order *domain.Order) domain.CError {
+	log := logger.FromCtx(ctx)
+
+	pConn, pClient, err := newProductClient(&config.GetConfig().Discovery)
+	if err != nil {
+		log.Error("Error creating product client", zap.Error(err))
+		return domain.ErrInternal
+	}
+	defer pConn.Close()
+
+	log.Info("Making request to release stock", zap.String("order_id", order.ID.Hex()))
+	_, err = pClient.ReleaseStock(ctx, &product.ReleaseStockRequest{
+		OrderId: order.ID.Hex(),
+		UserId:  order.UserID.Hex(),
+	})
+	if err != nil {
+		log.Error("Error releasing stock", zap.Error(err))
+		return cerrorFromStatus(err)
+	}
+
+	return nil
+}
+
+// cerrorFromStatus converts a gRPC error of the product-service into a custom error. Errors caused
+// by the request keep their message, any other error is reported as an internal error
+func cerrorFromStatus(err error) domain.CError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return domain.ErrInternal
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition:
+		return domain.NewBadRequestCError(st.Message())
+	default:
+		return domain.ErrInternal
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"order-service/internal/core/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCerrorFromStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantMsg  string
+	}{
+		{name: "invalid argument", err: status.Error(codes.InvalidArgument, "invalid product id"), wantCode: http.StatusBadRequest, wantMsg: "invalid product id"},
+		{name: "not found", err: status.Error(codes.NotFound, "product not found"), wantCode: http.StatusBadRequest, wantMsg: "product not found"},
+		{name: "failed precondition", err: status.Error(codes.FailedPrecondition, "insufficient stock"), wantCode: http.StatusBadRequest, wantMsg: "insufficient stock"},
+		{name: "internal", err: status.Error(codes.Internal, "database is down"), wantCode: http.StatusInternalServerError, wantMsg: domain.ErrInternal.Error()},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "connection refused"), wantCode: http.StatusInternalServerError, wantMsg: domain.ErrInternal.Error()},
+		{name: "not authenticated", err: status.Error(codes.Unauthenticated, "the calling service is not authenticated"), wantCode: http.StatusInternalServerError, wantMsg: domain.ErrInternal.Error()},
+		{name: "not a status", err: errors.New("boom"), wantCode: http.StatusInternalServerError, wantMsg: domain.ErrInternal.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cerr := cerrorFromStatus(tt.err)
+			if cerr.Code() != tt.wantCode || cerr.Error() != tt.wantMsg {
+				t.Errorf("cerrorFromStatus() = %d %q, want %d %q", cerr.Code(), cerr.Error(), tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"order-service/internal/adapter/logger"
+
+	"go.uber.org/zap"
+)
+
+// RunCoPurchaseJob computes the products bought together from the delivered orders, and again
+// periodically, until the context is cancelled. Several instances can run it at once, as each run
+// replaces the results with ones computed from all delivered orders
+func (os *OrderService) RunCoPurchaseJob(ctx context.Context) {
+	ticker := time.NewTicker(os.coPurchaseInterval)
+	defer ticker.Stop()
+
+	for {
+		os.computeCoPurchases(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (os *OrderService) computeCoPurchases(ctx context.Context) {
+	log := logger.FromCtx(ctx)
+
+	start := time.Now()
+	if cerr := os.repo.ComputeCoPurchases(ctx); cerr != nil {
+		// The previous results are kept until the next run
+		log.Error("Error computing co-purchases", zap.Error(cerr))
+		return
+	}
+
+	log.Info("Computed co-purchases", zap.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"order-service/internal/adapter/config"
+	"order-service/internal/core/domain"
+	"order-service/internal/core/port"
+	userv2 "order-service/internal/core/service/user/v2"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// tokenCheckTimeout bounds the revocation check of a token with the owner-service
+	tokenCheckTimeout = time.Second
+	// tokenCheckTTL is how long the result of a check is reused before the token is checked again.
+	// It is also how long a revoked token can still be used
+	tokenCheckTTL = 30 * time.Second
+	// tokenCheckGrace is how long a token last checked as valid is still accepted while the
+	// owner-service cannot be reached
+	tokenCheckGrace = 5 * time.Minute
+	// tokenCheckCacheSize bounds the number of checks kept, past which stale checks are dropped
+	tokenCheckCacheSize = 10000
+)
+
+// tokenCheck is the result of checking a token with the owner-service
+type tokenCheck struct {
+	revoked   bool
+	checkedAt time.Time
+}
+
+/**
+ * TokenService implements port.TokenService interface. It checks tokens locally
+ * and then with the owner-service, which rejects the tokens revoked by a password
+ * change or an account deletion. Checks are cached for tokenCheckTTL. When the
+ * owner-service cannot be reached, tokens checked as valid within tokenCheckGrace
+ * are still accepted and the others are rejected as unavailable
+ */
+type TokenService struct {
+	token  port.TokenService
+	conn   *grpc.ClientConn
+	client userv2.UserClient
+
+	mu     sync.Mutex
+	checks map[[sha256.Size]byte]tokenCheck
+	now    func() time.Time
+}
+
+// NewTokenService creates a token service that checks the tokens verified by token with the owner-service
+func NewTokenService(token port.TokenService, conf *config.DiscoveryConfiguration) (*TokenService, error) {
+	conn, client, err := newUserClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := newTokenService(token, client)
+	ts.conn = conn
+	return ts, nil
+}
+
+func newTokenService(token port.TokenService, client userv2.UserClient) *TokenService {
+	return &TokenService{
+		token:  token,
+		client: client,
+		checks: make(map[[sha256.Size]byte]tokenCheck),
+		now:    time.Now,
+	}
+}
+
+// Close closes the connection to the owner-service
+func (ts *TokenService) Close() error {
+	return ts.conn.Close()
+}
+
+// VerifyToken verifies the signature and claims of a token, and then that it was not revoked
+func (ts *TokenService) VerifyToken(tokenString string) (domain.Claims, domain.CError) {
+	claims, cerr := ts.token.VerifyToken(tokenString)
+	if cerr != nil {
+		return domain.Claims{}, cerr
+	}
+
+	key := sha256.Sum256([]byte(tokenString))
+	now := ts.now()
+
+	ts.mu.Lock()
+	last, checked := ts.checks[key]
+	ts.mu.Unlock()
+
+	if checked && now.Sub(last.checkedAt) < tokenCheckTTL {
+		if last.revoked {
+			return domain.Claims{}, domain.ErrRevokedToken
+		}
+		return claims, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCheckTimeout)
+	defer cancel()
+
+	_, err := ts.client.VerifyToken(ctx, &userv2.VerifyTokenRequest{Token: tokenString})
+	if err == nil || status.Code(err) == codes.Unauthenticated {
+		revoked := err != nil
+		ts.saveCheck(key, tokenCheck{revoked: revoked, checkedAt: now})
+		if revoked {
+			return domain.Claims{}, domain.ErrRevokedToken
+		}
+		return claims, nil
+	}
+
+	if checked && !last.revoked && now.Sub(last.checkedAt) < tokenCheckGrace {
+		zap.L().Warn("Failed to check the token with the owner-service, accepting its last check", zap.Error(err))
+		return claims, nil
+	}
+
+	zap.L().Error("Failed to check the token with the owner-service", zap.Error(err))
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return domain.Claims{}, domain.ErrAuthUnavailable
+	default:
+		return domain.Claims{}, domain.ErrInternal
+	}
+}
+
+// saveCheck caches the check of a token. Once the cache is full, the checks too old to be used
+// are dropped, and all of them when that does not free any room
+func (ts *TokenService) saveCheck(key [sha256.Size]byte, check tokenCheck) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if _, ok := ts.checks[key]; !ok && len(ts.checks) >= tokenCheckCacheSize {
+		for k, c := range ts.checks {
+			if check.checkedAt.Sub(c.checkedAt) >= tokenCheckGrace {
+				delete(ts.checks, k)
+			}
+		}
+		if len(ts.checks) >= tokenCheckCacheSize {
+			clear(ts.checks)
+		}
+	}
+
+	ts.checks[key] = check
+}
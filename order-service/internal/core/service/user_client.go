@@ -6,8 +6,9 @@ import (
 	"order-service/internal/adapter/config"
 	"order-service/internal/adapter/logger"
 	"order-service/internal/core/domain"
-	"order-service/internal/core/service/user"
+	userv2 "order-service/internal/core/service/user/v2"
 	"order-service/internal/core/util"
+	"pkg/grpcauth"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
@@ -15,22 +16,26 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func newUserClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, user.UserClient, error) {
-	conn, err := grpc.NewClient(conf.OwnerUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func newUserClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, userv2.UserClient, error) {
+	conn, err := grpc.NewClient(conf.OwnerUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(grpcauth.Token(conf.OwnerToken)),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create a new connection: %w", err)
 	}
 
-	userClient := user.NewUserClient(conn)
+	userClient := userv2.NewUserClient(conn)
 	return conn, userClient, nil
 }
 
+// GetUser reports whether a user exists. Users that were deleted do not exist
 func (os *OrderService) GetUser(ctx context.Context, userId primitive.ObjectID) (exists bool, cerr domain.CError) {
 	log := logger.FromCtx(ctx)
 
 	log.Info("Checking if user exists in cache", zap.String("user_id", userId.Hex()))
 
-	cacheKey := util.GenerateCacheKey("user", userId.Hex())
+	cacheKey := util.GenerateCacheKey("user-profile", userId.Hex())
 	_, err := os.cache.Get(ctx, cacheKey)
 	if err == nil {
 		log.Info("User with id found in cache", zap.String("user_id", userId.Hex()))
@@ -47,16 +52,23 @@ func (os *OrderService) GetUser(ctx context.Context, userId primitive.ObjectID)
 	log.Info("Created new grpc user client")
 	log.Info("Making request to fetch user", zap.String("user_id", userId.Hex()))
 
-	user, err := grpcClient.Get(context.Background(), &user.UserRequest{UserId: userId.Hex()})
+	// GetMany leaves out unknown and deleted users instead of failing, which tells a missing
+	// user apart from an owner-service error
+	resp, err := grpcClient.GetMany(ctx, &userv2.GetUsersRequest{UserIds: []string{userId.Hex()}})
 	if err != nil {
 		log.Error("Error fetching user", zap.Error(err))
 		return false, domain.ErrInternal
 	}
 
+	if len(resp.Users) == 0 {
+		log.Info("User does not exist", zap.String("user_id", userId.Hex()))
+		return false, nil
+	}
+
 	log.Info("Successfully fetched user")
 	log.Info("Saving user to cache")
 
-	sUser, err := util.Serialize(user)
+	sUser, err := util.Serialize(resp.Users[0])
 	if err != nil {
 		log.Error("Error serializing user", zap.Error(err))
 		return false, domain.ErrInternal
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	_ "owner-service/docs"
 	"owner-service/internal/adapter/auth/jwt"
@@ -13,11 +14,13 @@ import (
 	"owner-service/internal/adapter/config"
 	httpLib "owner-service/internal/adapter/handler/http"
 	"owner-service/internal/adapter/logger"
+	"owner-service/internal/adapter/mail/smtp"
 	"owner-service/internal/adapter/storage/mongodb"
 	"owner-service/internal/adapter/storage/mongodb/repository"
 	"owner-service/internal/core/service"
 
 	"github.com/go-playground/validator/v10"
+	gojwt "github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -68,7 +71,10 @@ func main() {
 	}
 	l.Info("Successfully run database migrations")
 
-	// Init token service
+	// Init token service. Issue times are encoded to the millisecond, as revocation times are
+	// stored, so a token issued in the second its user's tokens are revoked can be told apart
+	// from the ones issued after. This is process-wide and must be set before any token is created
+	gojwt.TimePrecision = time.Millisecond
 	tokenService := jwt.New(&config.Token)
 
 	// Message Queue Producer
@@ -95,14 +101,21 @@ func main() {
 	authService := service.NewAuthService(userRepo, tokenService)
 	authHandler := httpLib.NewAuthHandler(authService, validator.New())
 
+	// Organization
+	organizationRepo := repository.NewOrganizationRepository(db)
+	mailer := smtp.New(&config.Mail)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo, producer, mailer)
+	organizationHandler := httpLib.NewOrganizationHandler(organizationService, validator.New())
+
 	// Init router
 	router, err := httpLib.NewRouter(
 		&config.Server,
-		tokenService,
+		authService,
 		l,
 		*pingHandler,
 		*userHandler,
 		*authHandler,
+		*organizationHandler,
 	)
 	if err != nil {
 		l.Error("Error initializing router ", zap.Error(err))
@@ -121,12 +134,26 @@ func main() {
 		l.Info("Successfully created admin user with email: ", zap.String("email", config.Admin.Email))
 	}
 
+	// Message Queue Consumer
+	consumer, err := rabbitmq.New(ctx, &config.Rabbitmq)
+	if err != nil {
+		l.Error("Error initializing Message Broker consumer", zap.Error(err))
+		os.Exit(1)
+	}
+
+	l.Info("Successfully connected to the message broker and created consumer")
+
+	// Start consumer
+	queue := "organization-invitations"
+	l.Info("Starting consumer on", zap.String("queue", queue))
+	go consumer.Consume(ctx, queue, organizationService.SendInvitationFromQueue)
+
 	// Init GRPC server
 	grpcListAddr := fmt.Sprintf("%s:%s", config.Server.GrpcUrl, config.Server.GrpcPort)
 	list, err := net.Listen("tcp", grpcListAddr)
 	l.Info("Starting the GRPC server", zap.String("listen_address", list.Addr().String()))
 
-	server, err := service.NewGRPCServer(&service.Config{}, userRepo, grpc.EmptyServerOption{})
+	server, err := service.NewGRPCServer(&service.Config{Clients: config.Grpc.Clients}, userRepo, organizationRepo, authService, grpc.EmptyServerOption{})
 	go func() {
 		l.Error("Error starting grpc server", zap.Error(server.Serve(list)))
 	}()
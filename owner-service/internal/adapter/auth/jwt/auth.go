@@ -72,9 +72,15 @@ func (jt *JwtToken) VerifyToken(tokenString string) (domain.Claims, domain.CErro
 		return domain.Claims{}, domain.ErrInvalidToken
 	}
 
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
 	return domain.Claims{
-		Email:  claims.Subject,
-		Issuer: claims.Issuer,
-		ID:     claims.ID,
+		Email:    claims.Subject,
+		Issuer:   claims.Issuer,
+		ID:       claims.ID,
+		IssuedAt: issuedAt,
 	}, nil
 }
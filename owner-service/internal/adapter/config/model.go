@@ -1,5 +1,7 @@
 package config
 
+import "pkg/grpcauth"
+
 type RedisConfiguration struct {
 	Address  string
 	Password string
@@ -41,6 +43,23 @@ type RabbitMqConfiguration struct {
 	User     string
 	Password string
 	Host     string
+	// PublishLegacyUserUpdates also publishes v1 messages to "user-updates". No service of this repository
+	// reads them, so it is only a temporary dual-publish for outside consumers that have not moved to
+	// "user-updates.v2" yet, off by default, and is removed with the v1 messages once none are left
+	PublishLegacyUserUpdates bool
+}
+
+type MailConfiguration struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+type GrpcConfiguration struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
 }
 
 type Configuration struct {
@@ -51,4 +70,6 @@ type Configuration struct {
 	Token    TokenConfiguration
 	Admin    AdminConfiguration
 	Rabbitmq RabbitMqConfiguration
+	Grpc     GrpcConfiguration
+	Mail     MailConfiguration
 }
@@ -24,7 +24,7 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(next http.Handler, token port.TokenService, logger *zap.Logger) http.HandlerFunc {
+func authMiddleware(next http.Handler, auth port.AuthService, logger *zap.Logger) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenString := getToken(r, authorizationHeaderKey)
 		if tokenString == "" {
@@ -39,7 +39,7 @@ func authMiddleware(next http.Handler, token port.TokenService, logger *zap.Logg
 			return
 		}
 
-		claims, err := token.VerifyToken(fields[1])
+		claims, err := auth.VerifyToken(r.Context(), fields[1])
 		if err != nil {
 			logger.Error("error verifying token", zap.Error(err))
 			handleError(w, err)
@@ -110,7 +110,7 @@ func requestLogger(next http.Handler) http.Handler {
 	})
 }
 
-func adminMiddleware(next http.Handler, token port.TokenService, logger *zap.Logger) http.HandlerFunc {
+func adminMiddleware(next http.Handler, auth port.AuthService, logger *zap.Logger) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tokenString := getToken(r, authorizationHeaderKey)
 		if tokenString == "" {
@@ -125,7 +125,7 @@ func adminMiddleware(next http.Handler, token port.TokenService, logger *zap.Log
 			return
 		}
 
-		claims, err := token.VerifyToken(fields[1])
+		claims, err := auth.VerifyToken(r.Context(), fields[1])
 		if err != nil {
 			logger.Error("error verifying token", zap.Error(err))
 			handleError(w, err)
@@ -142,6 +142,7 @@ func adminMiddleware(next http.Handler, token port.TokenService, logger *zap.Log
 		email, role := identifier[0], identifier[len(identifier)-1]
 		if role != domain.RAdmin.String() {
 			handleError(w, domain.ErrUnauthorized)
+			return
 		}
 
 		// Set details from token in context
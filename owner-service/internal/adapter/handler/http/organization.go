@@ -0,0 +1,383 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"owner-service/internal/adapter/logger"
+	"owner-service/internal/core/domain"
+	"owner-service/internal/core/port"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// OrganizationHandler represents the HTTP handler for organization-related requests
+type OrganizationHandler struct {
+	svc      port.OrganizationService
+	validate *validator.Validate
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler instance
+func NewOrganizationHandler(svc port.OrganizationService, vld *validator.Validate) *OrganizationHandler {
+	return &OrganizationHandler{
+		svc,
+		vld,
+	}
+}
+
+// currentUserID returns the id of the authenticated user
+func currentUserID(r *http.Request) (primitive.ObjectID, domain.CError) {
+	ctxInfo := r.Context().Value(domain.AuthContextKey).(contextInfo)
+	id, err := primitive.ObjectIDFromHex(ctxInfo.ID)
+	if err != nil {
+		logger.FromCtx(r.Context()).Error("Error parsing user id", zap.String("user_id", ctxInfo.ID), zap.Error(err))
+		return primitive.NilObjectID, domain.ErrInternal
+	}
+	return id, nil
+}
+
+// objectIDParam parses an object id from the url
+func objectIDParam(r *http.Request, name, errMsg string) (primitive.ObjectID, domain.CError) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, name))
+	if err != nil {
+		return primitive.NilObjectID, domain.NewBadRequestCError(errMsg)
+	}
+	return id, nil
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create an organization
+//	@Description	create an organization owned by the authenticated user
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.CreateOrganizationRequest	body		domain.CreateOrganizationRequest	true	"Organization"
+//	@Success		201									{object}	response							"Organization created successfully"
+//	@Failure		400									{object}	errorResponse						"Validation error"
+//	@Failure		401									{object}	errorResponse						"Unauthorized error"
+//	@Failure		500									{object}	errorResponse						"Internal server error"
+//	@Router			/organization [post]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := oh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := oh.svc.CreateOrganization(r.Context(), userID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Organization created successfully")
+}
+
+// ListOrganizations godoc
+//
+//	@Summary		List the current user's organizations
+//	@Description	list the organizations the authenticated user is a member of
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	response		"Success"
+//	@Failure		401	{object}	errorResponse	"Unauthorized error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/organizations [get]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := oh.svc.ListUserOrganizations(r.Context(), userID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// GetOrganization godoc
+//
+//	@Summary		Get an organization by id
+//	@Description	fetch an organization the authenticated user is a member of
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Organization id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/organization/{id} [get]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := oh.svc.GetOrganization(r.Context(), userID, orgID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// ListMembers godoc
+//
+//	@Summary		List the members of an organization
+//	@Description	list the members and their roles of an organization the authenticated user is a member of
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Organization id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/organization/{id}/members [get]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := oh.svc.ListMembers(r.Context(), userID, orgID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// InviteMember godoc
+//
+//	@Summary		Invite a member to an organization
+//	@Description	invite a user by email. The invitation token is sent to the email address and expires after 7 days
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Organization id"
+//	@Param			domain.InviteMemberRequest	body		domain.InviteMemberRequest	true	"Invitation"
+//	@Success		201							{object}	response					"Invitation sent successfully"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		403							{object}	errorResponse				"Forbidden error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/organization/{id}/invitations [post]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.InviteMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := oh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := oh.svc.InviteMember(r.Context(), userID, orgID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Invitation sent successfully")
+}
+
+// AcceptInvitation godoc
+//
+//	@Summary		Accept an organization invitation
+//	@Description	join the organization using the token from the invitation email. The invitation must have been sent to the authenticated user's email
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.AcceptInvitationRequest	body		domain.AcceptInvitationRequest	true	"Invitation token"
+//	@Success		200								{object}	response						"Success"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		403								{object}	errorResponse					"Forbidden error"
+//	@Failure		409								{object}	errorResponse					"Conflict error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/organization/invitations/accept [post]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := oh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := oh.svc.AcceptInvitation(r.Context(), userID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// UpdateMemberRole godoc
+//
+//	@Summary		Change the role of a member
+//	@Description	change the role of a member. Only owners can grant or revoke the owner role
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		string							true	"Organization id"
+//	@Param			user_id							path		string							true	"Member user id"
+//	@Param			domain.UpdateMemberRoleRequest	body		domain.UpdateMemberRoleRequest	true	"Role"
+//	@Success		200								{object}	response						"Success"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		403								{object}	errorResponse					"Forbidden error"
+//	@Failure		404								{object}	errorResponse					"Data not found error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/organization/{id}/member/{user_id} [patch]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	memberID, cerr := objectIDParam(r, "user_id", "Invalid user id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := oh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := oh.svc.UpdateMemberRole(r.Context(), userID, orgID, memberID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove a member from an organization
+//	@Description	remove a member from an organization. Members can always remove themselves, but an organization keeps at least one owner
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Organization id"
+//	@Param			user_id	path		string			true	"Member user id"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		404		{object}	errorResponse	"Data not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/organization/{id}/member/{user_id} [delete]
+//	@Security		BearerAuth
+func (oh *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	memberID, cerr := objectIDParam(r, "user_id", "Invalid user id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if cerr := oh.svc.RemoveMember(r.Context(), userID, orgID, memberID); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Member removed successfully")
+}
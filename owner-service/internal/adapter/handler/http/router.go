@@ -23,11 +23,12 @@ type Router struct {
 // NewRouter creates a new HTTP router
 func NewRouter(
 	config *config.ServerConfiguration,
-	token port.TokenService,
+	auth port.AuthService,
 	logger *zap.Logger,
 	pingHandler PingHandler,
 	userHandler UserHandler,
 	authHandler AuthHandler,
+	organizationHandler OrganizationHandler,
 ) (*Router, error) {
 
 	// CORS
@@ -73,11 +74,25 @@ func NewRouter(
 		// User
 		r.Route("/user", func(r chi.Router) {
 			r.Post("/", userHandler.RegisterUser)
-			r.Get("/{id}", adminMiddleware(http.HandlerFunc(userHandler.GetUser), token, logger))
-			r.Patch("/{id}", authMiddleware(http.HandlerFunc(userHandler.UpdateUser), token, logger))
-			r.Delete("/{id}", adminMiddleware(http.HandlerFunc(userHandler.DeleteUser), token, logger))
+			r.Post("/me/password", authMiddleware(http.HandlerFunc(userHandler.ChangePassword), auth, logger))
+			r.Delete("/me", authMiddleware(http.HandlerFunc(userHandler.DeleteAccount), auth, logger))
+			r.Get("/{id}", adminMiddleware(http.HandlerFunc(userHandler.GetUser), auth, logger))
+			r.Patch("/{id}", authMiddleware(http.HandlerFunc(userHandler.UpdateUser), auth, logger))
+			r.Delete("/{id}", adminMiddleware(http.HandlerFunc(userHandler.DeleteUser), auth, logger))
 		})
-		r.Get("/users", adminMiddleware(http.HandlerFunc(userHandler.ListUsers), token, logger))
+		r.Get("/users", adminMiddleware(http.HandlerFunc(userHandler.ListUsers), auth, logger))
+
+		// Organization
+		r.Route("/organization", func(r chi.Router) {
+			r.Post("/", authMiddleware(http.HandlerFunc(organizationHandler.CreateOrganization), auth, logger))
+			r.Post("/invitations/accept", authMiddleware(http.HandlerFunc(organizationHandler.AcceptInvitation), auth, logger))
+			r.Get("/{id}", authMiddleware(http.HandlerFunc(organizationHandler.GetOrganization), auth, logger))
+			r.Get("/{id}/members", authMiddleware(http.HandlerFunc(organizationHandler.ListMembers), auth, logger))
+			r.Post("/{id}/invitations", authMiddleware(http.HandlerFunc(organizationHandler.InviteMember), auth, logger))
+			r.Patch("/{id}/member/{user_id}", authMiddleware(http.HandlerFunc(organizationHandler.UpdateMemberRole), auth, logger))
+			r.Delete("/{id}/member/{user_id}", authMiddleware(http.HandlerFunc(organizationHandler.RemoveMember), auth, logger))
+		})
+		r.Get("/organizations", authMiddleware(http.HandlerFunc(organizationHandler.ListOrganizations), auth, logger))
 	})
 
 	return &Router{
@@ -3,6 +3,9 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"owner-service/internal/adapter/logger"
 	"owner-service/internal/core/domain"
@@ -96,18 +99,34 @@ func (ch *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 // ListUsers godoc
 //
-//	@Summary		List all users
-//	@Description	list all registered active users
+//	@Summary		List users
+//	@Description	list a page of registered users matching the search and filters
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	response		"Success"
-//	@Failure		400	{object}	errorResponse	"Validation error"
-//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Param			q				query		string			false	"Search by the start of each word of the names, email or phone"
+//	@Param			role			query		string			false	"Filter by role"	Enums(admin, user)
+//	@Param			is_active		query		bool			false	"Filter by active state"
+//	@Param			created_from	query		string			false	"Created on or after (RFC3339 or YYYY-MM-DD)"
+//	@Param			created_to		query		string			false	"Created on or before (RFC3339 or YYYY-MM-DD)"
+//	@Param			include_deleted	query		bool			false	"Include deleted users"
+//	@Param			sort_by			query		string			false	"Sort field"	Enums(created_at, updated_at, first_name, last_name, email)
+//	@Param			sort_order		query		string			false	"Sort order"	Enums(asc, desc)
+//	@Param			page			query		int				false	"Page number, starting at 1"
+//	@Param			page_size		query		int				false	"Page size, at most 100"
+//	@Success		200				{object}	response		"Success"
+//	@Failure		400				{object}	errorResponse	"Validation error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
 //	@Router			/users [get]
 //	@Security		BearerAuth
 func (ch *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	results, cerr := ch.svc.ListUsers(r.Context())
+	filter, cerr := parseUserFilter(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	results, cerr := ch.svc.ListUsers(r.Context(), filter)
 	if cerr != nil {
 		handleError(w, cerr)
 		return
@@ -191,3 +210,176 @@ func (ch *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted user successfully")
 }
+
+// ChangePassword godoc
+//
+//	@Summary		Change the current user's password
+//	@Description	change the password of the authenticated user. All previously issued tokens are revoked
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.ChangePasswordRequest	body		domain.ChangePasswordRequest	true	"Passwords"
+//	@Success		200								{object}	response						"Password changed successfully"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		401								{object}	errorResponse					"Unauthorized error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/user/me/password [post]
+//	@Security		BearerAuth
+func (ch *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctxInfo := r.Context().Value(domain.AuthContextKey).(contextInfo)
+	id, err := primitive.ObjectIDFromHex(ctxInfo.ID)
+	if err != nil {
+		logger.FromCtx(r.Context()).Error("Error parsing user id", zap.String("user_id", ctxInfo.ID), zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	var req domain.ChangePasswordRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	cerr := ch.svc.ChangePassword(r.Context(), id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Password changed successfully, please log in again")
+}
+
+// DeleteAccount godoc
+//
+//	@Summary		Delete the current user's account
+//	@Description	close the account of the authenticated user after re-entering the password. All previously issued tokens are revoked
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.DeleteAccountRequest	body		domain.DeleteAccountRequest	true	"Password"
+//	@Success		200							{object}	response					"Success"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		401							{object}	errorResponse				"Unauthorized error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/user/me [delete]
+//	@Security		BearerAuth
+func (ch *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctxInfo := r.Context().Value(domain.AuthContextKey).(contextInfo)
+	id, err := primitive.ObjectIDFromHex(ctxInfo.ID)
+	if err != nil {
+		logger.FromCtx(r.Context()).Error("Error parsing user id", zap.String("user_id", ctxInfo.ID), zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	var req domain.DeleteAccountRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	cerr := ch.svc.DeleteAccount(r.Context(), id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted account successfully")
+}
+
+// parseUserFilter builds a user filter from the query parameters of the request
+func parseUserFilter(r *http.Request) (*domain.UserFilter, domain.CError) {
+	q := r.URL.Query()
+	filter := domain.UserFilter{
+		Query:     strings.TrimSpace(q.Get("q")),
+		SortBy:    q.Get("sort_by"),
+		SortOrder: q.Get("sort_order"),
+	}
+
+	if role := q.Get("role"); role != "" {
+		userRole, ok := domain.StringToUserRole[role]
+		if !ok {
+			return nil, domain.NewBadRequestCError("invalid role specified: " + role)
+		}
+		filter.Role = userRole
+	}
+
+	if v := q.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("is_active must be a boolean")
+		}
+		filter.IsActive = &isActive
+	}
+
+	if v := q.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("include_deleted must be a boolean")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	if v := q.Get("created_from"); v != "" {
+		from, _, err := parseDate(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("created_from must be an RFC3339 timestamp or a YYYY-MM-DD date")
+		}
+		filter.CreatedFrom = &from
+	}
+
+	if v := q.Get("created_to"); v != "" {
+		to, dateOnly, err := parseDate(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("created_to must be an RFC3339 timestamp or a YYYY-MM-DD date")
+		}
+		if dateOnly {
+			to = to.Add(24*time.Hour - time.Nanosecond) // include the whole day
+		}
+		filter.CreatedTo = &to
+	}
+
+	if v := q.Get("page"); v != "" {
+		page, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || page < 1 {
+			return nil, domain.NewBadRequestCError("page must be a positive integer")
+		}
+		filter.Page = page
+	}
+
+	if v := q.Get("page_size"); v != "" {
+		pageSize, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || pageSize < 1 {
+			return nil, domain.NewBadRequestCError("page_size must be a positive integer")
+		}
+		filter.PageSize = pageSize
+	}
+
+	if filter.SortOrder != "" && filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return nil, domain.NewBadRequestCError("sort_order must be either asc or desc")
+	}
+
+	return &filter, nil
+}
+
+// parseDate parses an RFC3339 timestamp or a YYYY-MM-DD date and reports whether it was a date
+func parseDate(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, v)
+	return t, true, err
+}
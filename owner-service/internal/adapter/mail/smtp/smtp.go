@@ -0,0 +1,51 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"owner-service/internal/adapter/config"
+)
+
+/**
+ * Mailer implements port.Mailer interface
+ * and sends emails through an SMTP server
+ */
+type Mailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// New creates a new mailer. Servers that take no credentials are used without authentication
+func New(conf *config.MailConfiguration) *Mailer {
+	m := Mailer{
+		addr: net.JoinHostPort(conf.Host, conf.Port),
+		from: conf.From,
+	}
+
+	if conf.User != "" {
+		m.auth = smtp.PlainAuth("", conf.User, conf.Password, conf.Host)
+	}
+
+	return &m
+}
+
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	// Header values must not break out of their line
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.from, to, subject, body)
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
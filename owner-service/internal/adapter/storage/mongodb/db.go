@@ -6,8 +6,8 @@ import (
 	"time"
 
 	"owner-service/internal/adapter/config"
+	"pkg/migrate"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -75,20 +75,32 @@ func (db *DB) Url() string {
 	return db.url
 }
 
+// RunMigrations applies all pending migrations to the service database
 func (db *DB) RunMigrations(ctx context.Context, config *config.DatabaseConfiguration) error {
-	// Define the index model
-	indexModel := mongo.IndexModel{
-		Keys:    bson.D{{Key: "email", Value: 1}},
-		Options: options.Index().SetUnique(true).SetName("email_unique_index"),
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
 	}
 
-	database := db.Client.Database(config.Name)
+	return migrator.Up(ctx)
+}
+
+// RollbackMigrations rolls back the service database to the target migration version
+func (db *DB) RollbackMigrations(ctx context.Context, config *config.DatabaseConfiguration, target int64) error {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Down(ctx, target)
+}
 
-	// Create the index
-	_, err := database.Collection("users").Indexes().CreateOne(ctx, indexModel)
+// MigrationVersion returns the latest migration version applied to the service database
+func (db *DB) MigrationVersion(ctx context.Context, config *config.DatabaseConfiguration) (int64, error) {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
 	if err != nil {
-		return fmt.Errorf("Error creating index, %w", err)
+		return 0, err
 	}
 
-	return nil
+	return migrator.Version(ctx)
 }
@@ -0,0 +1,169 @@
+package mongodb
+
+import (
+	"context"
+
+	"owner-service/internal/core/domain"
+	"pkg/migrate"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists every migration of the service database. Append new migrations
+// with the next version and never change one that has been released
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "create user indexes",
+		Up: migrate.CreateIndexes("users", []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("email_unique_index"),
+			},
+			// Default listing order, and the tie breaker for every other sort
+			{
+				Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+				Options: options.Index().SetName("created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "role", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("role_created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "is_active", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("is_active_created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "first_name", Value: 1}, {Key: "last_name", Value: 1}},
+				Options: options.Index().SetName("name_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "last_name", Value: 1}},
+				Options: options.Index().SetName("last_name_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "phone", Value: 1}},
+				Options: options.Index().SetName("phone_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "updated_at", Value: -1}},
+				Options: options.Index().SetName("updated_at_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("users",
+			"email_unique_index",
+			"created_at_index",
+			"role_created_at_index",
+			"is_active_created_at_index",
+			"name_index",
+			"last_name_index",
+			"phone_index",
+			"updated_at_index",
+		),
+	},
+	{
+		Version:     2,
+		Description: "create organization membership and invitation indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("memberships", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "user_id", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("organization_user_unique_index"),
+				},
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}},
+					Options: options.Index().SetName("user_id_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return migrate.CreateIndexes("invitations", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "token_hash", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("token_hash_unique_index"),
+				},
+				{
+					Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "email", Value: 1}},
+					Options: options.Index().SetName("organization_email_index"),
+				},
+			})(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := migrate.DropIndexes("invitations", "token_hash_unique_index", "organization_email_index")(ctx, db); err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("memberships", "organization_user_unique_index", "user_id_index")(ctx, db)
+		},
+	},
+	{
+		Version:     3,
+		Description: "search users by the prefixes of their lower-cased search terms",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := seedUserSearchTerms(ctx, db); err != nil {
+				return err
+			}
+
+			err := migrate.CreateIndexes("users", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "search_terms", Value: 1}},
+					Options: options.Index().SetName("search_terms_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			// Phones are only searched, through the search terms, while names are still sorted on
+			return migrate.DropIndexes("users", "phone_index")(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("users", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "phone", Value: 1}},
+					Options: options.Index().SetName("phone_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			if err := migrate.DropIndexes("users", "search_terms_index")(ctx, db); err != nil {
+				return err
+			}
+
+			_, err = db.Collection("users").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"search_terms": ""}})
+			return err
+		},
+	},
+}
+
+// seedUserSearchTerms records the search terms of every user. The terms are computed again on each run,
+// so the step can be run again
+func seedUserSearchTerms(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+
+	cursor, err := users.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user domain.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		_, err := users.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"search_terms": domain.UserSearchTerms(&user)}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
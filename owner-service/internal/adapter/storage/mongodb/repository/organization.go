@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"owner-service/internal/adapter/config"
+	"owner-service/internal/adapter/storage/mongodb"
+	"owner-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * OrganizationRepository implements port.OrganizationRepository interface
+ * and provides an access to the mongo database
+ */
+type OrganizationRepository struct {
+	organizations *mongo.Collection
+	memberships   *mongo.Collection
+	invitations   *mongo.Collection
+}
+
+// NewOrganizationRepository creates a new organization repository instance
+func NewOrganizationRepository(db *mongodb.DB) *OrganizationRepository {
+	database := db.Client.Database(config.GetConfig().Database.Name)
+	return &OrganizationRepository{
+		organizations: database.Collection("organizations"),
+		memberships:   database.Collection("memberships"),
+		invitations:   database.Collection("invitations"),
+	}
+}
+
+// isDuplicateKeyError reports whether err is a unique index violation
+func isDuplicateKeyError(err error) bool {
+	if writeException, ok := err.(mongo.WriteException); ok {
+		for _, writeError := range writeException.WriteErrors {
+			if writeError.Code == 11000 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CreateOrganization inserts the organization together with an owner membership for its creator
+func (or *OrganizationRepository) CreateOrganization(ctx context.Context, org *domain.Organization) (*domain.Organization, domain.CError) {
+	org.ID = primitive.NewObjectID()
+	org.CreatedAt = time.Now()
+	org.UpdatedAt = org.CreatedAt
+
+	if _, err := or.organizations.InsertOne(ctx, org); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	_, cerr := or.AddMember(ctx, &domain.Membership{
+		OrganizationID: org.ID,
+		UserID:         org.CreatedBy,
+		Role:           domain.OrgRoleOwner,
+	})
+	if cerr != nil {
+		// An organization without an owner cannot be managed, so roll it back
+		_, _ = or.organizations.DeleteOne(ctx, bson.M{"_id": org.ID})
+		return nil, cerr
+	}
+
+	return org, nil
+}
+
+// GetOrganizationByID gets an organization by ID from the database
+func (or *OrganizationRepository) GetOrganizationByID(ctx context.Context, id primitive.ObjectID) (*domain.Organization, domain.CError) {
+	var org domain.Organization
+
+	err := or.organizations.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &org, nil
+}
+
+// ListUserOrganizations gets the organizations a user is a member of
+func (or *OrganizationRepository) ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]domain.Organization, domain.CError) {
+	cursor, err := or.memberships.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	var members []domain.Membership
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	orgs := []domain.Organization{}
+	if len(members) == 0 {
+		return orgs, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(members))
+	for i, m := range members {
+		ids[i] = m.OrganizationID
+	}
+
+	cursor, err = or.organizations.Find(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "deleted_at": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return orgs, nil
+}
+
+// AddMember inserts a membership into the database
+func (or *OrganizationRepository) AddMember(ctx context.Context, member *domain.Membership) (*domain.Membership, domain.CError) {
+	member.ID = primitive.NewObjectID()
+	member.CreatedAt = time.Now()
+	member.UpdatedAt = member.CreatedAt
+
+	if _, err := or.memberships.InsertOne(ctx, member); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return member, nil
+}
+
+// GetMember gets the membership of a user in an organization
+func (or *OrganizationRepository) GetMember(ctx context.Context, orgID, userID primitive.ObjectID) (*domain.Membership, domain.CError) {
+	var member domain.Membership
+
+	err := or.memberships.FindOne(ctx, bson.M{"organization_id": orgID, "user_id": userID}).Decode(&member)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &member, nil
+}
+
+// ListMembers gets all memberships of an organization
+func (or *OrganizationRepository) ListMembers(ctx context.Context, orgID primitive.ObjectID) ([]domain.Membership, domain.CError) {
+	cursor, err := or.memberships.Find(ctx,
+		bson.M{"organization_id": orgID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	members := []domain.Membership{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return members, nil
+}
+
+// CountMembersWithRole counts the members of an organization that have a role
+func (or *OrganizationRepository) CountMembersWithRole(ctx context.Context, orgID primitive.ObjectID, role domain.OrgRole) (int64, domain.CError) {
+	count, err := or.memberships.CountDocuments(ctx, bson.M{"organization_id": orgID, "role": role})
+	if err != nil {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	return count, nil
+}
+
+// UpdateMemberRole changes the role of a member and returns the updated membership
+func (or *OrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID primitive.ObjectID, role domain.OrgRole) (*domain.Membership, domain.CError) {
+	var member domain.Membership
+
+	err := or.memberships.FindOneAndUpdate(ctx,
+		bson.M{"organization_id": orgID, "user_id": userID},
+		bson.M{"$set": bson.M{"role": role, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&member)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &member, nil
+}
+
+// RemoveMember deletes the membership of a user in an organization
+func (or *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID primitive.ObjectID) domain.CError {
+	result, err := or.memberships.DeleteOne(ctx, bson.M{"organization_id": orgID, "user_id": userID})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// CreateInvitation inserts an invitation into the database
+func (or *OrganizationRepository) CreateInvitation(ctx context.Context, inv *domain.Invitation) (*domain.Invitation, domain.CError) {
+	inv.ID = primitive.NewObjectID()
+	inv.CreatedAt = time.Now()
+
+	if _, err := or.invitations.InsertOne(ctx, inv); err != nil {
+		if isDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return inv, nil
+}
+
+// GetInvitationByTokenHash gets a pending, unexpired invitation using the hash of its token
+func (or *OrganizationRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, domain.CError) {
+	var inv domain.Invitation
+
+	err := or.invitations.FindOne(ctx, bson.M{
+		"token_hash":  tokenHash,
+		"accepted_at": bson.M{"$exists": false},
+		"expires_at":  bson.M{"$gt": time.Now()},
+	}).Decode(&inv)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &inv, nil
+}
+
+// MarkInvitationAccepted records that an invitation has been used so it cannot be used again
+func (or *OrganizationRepository) MarkInvitationAccepted(ctx context.Context, id primitive.ObjectID) domain.CError {
+	result, err := or.invitations.UpdateOne(ctx,
+		bson.M{"_id": id, "accepted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"accepted_at": time.Now()}},
+	)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
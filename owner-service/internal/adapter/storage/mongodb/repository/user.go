@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"owner-service/internal/adapter/config"
@@ -33,6 +34,7 @@ func (ur *UserRepository) CreateUser(ctx context.Context, user *domain.User) (*d
 	user.ID = primitive.NewObjectID()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	user.SearchTerms = domain.UserSearchTerms(user)
 
 	_, err := ur.collection.InsertOne(ctx, user)
 	if err != nil {
@@ -80,29 +82,92 @@ func (ur *UserRepository) GetUserByEmail(ctx context.Context, email string) (*do
 	return &user, nil
 }
 
-// ListUsers lists all users from the database
-func (ur *UserRepository) ListUsers(ctx context.Context) ([]domain.User, domain.CError) {
-	var users []domain.User
+// GetUsersByIDs gets a number of users by their ids
+func (ur *UserRepository) GetUsersByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.User, domain.CError) {
+	var users = make([]domain.User, 0)
 
-	cursor, err := ur.collection.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": false}}, options.Find().SetSort(bson.M{"created_at": -1}))
+	filter := bson.M{
+		"_id":        bson.M{"$in": ids},
+		"deleted_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := ur.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, domain.NewInternalCError(err.Error())
 	}
 	defer cursor.Close(ctx)
 
-	for cursor.Next(ctx) {
-		var user domain.User
-		if err := cursor.Decode(&user); err != nil {
-			return nil, domain.NewInternalCError(err.Error())
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return users, nil
+}
+
+// ListUsers lists a page of users matching the filter from the database
+func (ur *UserRepository) ListUsers(ctx context.Context, filter *domain.UserFilter) ([]domain.User, int64, domain.CError) {
+	var users = make([]domain.User, 0)
+
+	query := bson.M{}
+	if !filter.IncludeDeleted {
+		query["deleted_at"] = bson.M{"$exists": false}
+	}
+
+	// Each word must start a search term. The prefixes are anchored and case-sensitive on lower-cased
+	// terms, so they are served by the search terms index
+	if words := domain.SearchWords(filter.Query); len(words) > 0 {
+		prefixes := bson.A{}
+		for _, word := range words {
+			prefixes = append(prefixes, bson.M{"search_terms": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(word)}})
 		}
-		users = append(users, user)
+		query["$and"] = prefixes
 	}
 
-	if err := cursor.Err(); err != nil {
-		return nil, domain.NewInternalCError(err.Error())
+	if filter.Role != "" {
+		query["role"] = filter.Role
 	}
 
-	return users, nil
+	if filter.IsActive != nil {
+		query["is_active"] = *filter.IsActive
+	}
+
+	if filter.CreatedFrom != nil || filter.CreatedTo != nil {
+		createdAt := bson.M{}
+		if filter.CreatedFrom != nil {
+			createdAt["$gte"] = *filter.CreatedFrom
+		}
+		if filter.CreatedTo != nil {
+			createdAt["$lte"] = *filter.CreatedTo
+		}
+		query["created_at"] = createdAt
+	}
+
+	total, err := ur.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, domain.NewInternalCError(err.Error())
+	}
+
+	order := -1
+	if filter.SortOrder == "asc" {
+		order = 1
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: domain.UserSortFields[filter.SortBy], Value: order}, {Key: "_id", Value: order}}).
+		SetSkip((filter.Page - 1) * filter.PageSize).
+		SetLimit(filter.PageSize)
+
+	cursor, err := ur.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, domain.NewInternalCError(err.Error())
+	}
+
+	return users, total, nil
 }
 
 // UpdateUser updates a user by ID in the database
@@ -111,10 +176,11 @@ func (ur *UserRepository) UpdateUser(ctx context.Context, user *domain.User) (*d
 
 	update := bson.M{
 		"$set": bson.M{
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"phone":      user.Phone,
-			"updated_at": user.UpdatedAt,
+			"first_name":   user.FirstName,
+			"last_name":    user.LastName,
+			"phone":        user.Phone,
+			"search_terms": domain.UserSearchTerms(user),
+			"updated_at":   user.UpdatedAt,
 		},
 	}
 
@@ -149,3 +215,26 @@ func (ur *UserRepository) DeleteUser(ctx context.Context, id primitive.ObjectID)
 
 	return nil
 }
+
+// UpdatePassword sets a new password hash for a user and revokes their existing tokens
+func (ur *UserRepository) UpdatePassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) domain.CError {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"password":          hashedPassword,
+			"tokens_revoked_at": now,
+			"updated_at":        now,
+		},
+	}
+
+	res, err := ur.collection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}, update)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
@@ -58,6 +58,8 @@ var (
 	ErrTokenCreation = NewUnauthorizedCError("error creating token")
 	// ErrExpiredToken is an error for when the access token is expired
 	ErrExpiredToken = NewUnauthorizedCError("access token has expired")
+	// ErrRevokedToken is an error for when the access token was issued before the user's tokens were revoked
+	ErrRevokedToken = NewUnauthorizedCError("access token has been revoked")
 	// ErrInvalidToken is an error for when the access token is invalid
 	ErrInvalidToken = NewUnauthorizedCError("access token is invalid")
 	// ErrEmptyAuthorizationHeader is an error for when the authorization header is empty
@@ -68,6 +70,10 @@ var (
 	ErrInvalidAuthorizationType = NewUnauthorizedCError("authorization type is not supported")
 	// ErrUnauthorized is an error for when the user is unauthorized
 	ErrUnauthorized = NewUnauthorizedCError("user is unauthorized to access the resource")
+	// ErrForbidden is an error for when the user lacks the permission to perform an action
+	ErrForbidden = NewCError(http.StatusForbidden, "user does not have permission to perform this action")
 	// ErrInvalidCredentials is an error for when the credentials are invalid
 	ErrInvalidCredentials = NewUnauthorizedCError("invalid email or password")
+	// ErrIncorrectPassword is an error for when the password supplied for re-authentication is wrong
+	ErrIncorrectPassword = NewUnauthorizedCError("password is incorrect")
 )
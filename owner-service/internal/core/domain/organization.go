@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Enum for organization member roles
+type OrgRole string
+
+const (
+	OrgRoleOwner   OrgRole = "owner"
+	OrgRoleManager OrgRole = "manager"
+	OrgRoleStaff   OrgRole = "staff"
+)
+
+var StringToOrgRole = map[string]OrgRole{
+	"owner":   OrgRoleOwner,
+	"manager": OrgRoleManager,
+	"staff":   OrgRoleStaff,
+}
+
+func (or OrgRole) String() string {
+	return string(or)
+}
+
+// Organization permissions
+const (
+	PermManageOrganization = "organization:manage"
+	PermManageMembers      = "members:manage"
+	PermWriteProducts      = "products:write"
+	PermDeleteProducts     = "products:delete"
+)
+
+// OrgRolePermissions lists the permissions granted to each member role
+var OrgRolePermissions = map[OrgRole][]string{
+	OrgRoleOwner:   {PermManageOrganization, PermManageMembers, PermWriteProducts, PermDeleteProducts},
+	OrgRoleManager: {PermManageMembers, PermWriteProducts, PermDeleteProducts},
+	OrgRoleStaff:   {PermWriteProducts},
+}
+
+// Can reports whether the role grants the permission
+func (or OrgRole) Can(permission string) bool {
+	for _, p := range OrgRolePermissions[or] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// InvitationTTL is how long an invitation token stays valid
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Organization represents a row in the "organizations" table
+type Organization struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Name      string             `json:"name" bson:"name"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	DeletedAt *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+}
+
+// Membership represents a row in the "memberships" table
+type Membership struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Role           OrgRole            `json:"role" bson:"role"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Invitation represents a row in the "invitations" table. Only a hash of the token is stored
+type Invitation struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	OrganizationID primitive.ObjectID `json:"organization_id" bson:"organization_id"`
+	Email          string             `json:"email" bson:"email"`
+	Role           OrgRole            `json:"role" bson:"role"`
+	TokenHash      string             `json:"-" bson:"token_hash"`
+	InvitedBy      primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	AcceptedAt     *time.Time         `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// InvitationForQueue is published to the "organization-invitations" queue so the token can be emailed
+type InvitationForQueue struct {
+	InvitationID     string `json:"invitation_id"`
+	OrganizationID   string `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+	Email            string `json:"email"`
+	Role             string `json:"role"`
+	Token            string `json:"token"`
+	ExpiresAt        string `json:"expires_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type InviteMemberRequest struct {
+	Email string  `json:"email" validate:"required,email"`
+	Role  OrgRole `json:"role" validate:"required,oneof=owner manager staff"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role OrgRole `json:"role" validate:"required,oneof=owner manager staff"`
+}
@@ -1,9 +1,12 @@
 package domain
 
+import "time"
+
 // Claims is an entity that represents the payload of the token
 type Claims struct {
-	Email  string
-	Issuer string
-	ID     string
+	Email    string
+	Issuer   string
+	ID       string
+	IssuedAt time.Time
 	// jwt.RegisteredClaims
 }
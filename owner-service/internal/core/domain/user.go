@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -36,6 +37,10 @@ type User struct {
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 	DeletedAt *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	// TokensRevokedAt invalidates every token issued before it
+	TokensRevokedAt *time.Time `json:"-" bson:"tokens_revoked_at,omitempty"`
+	// SearchTerms are the lower-cased words of the names, the email and the phone digits users are searched by
+	SearchTerms []string `json:"-" bson:"search_terms,omitempty"`
 }
 
 type LoginRequest struct {
@@ -63,3 +68,109 @@ type CreateUserRequest struct {
 	Phone     string   `json:"phone" validate:"required"`
 	Role      UserRole `json:"-"`
 }
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// UserFilter narrows down, sorts and paginates user listings. Pages start at 1
+type UserFilter struct {
+	// Query matches users whose names, email or phone start with each of its words
+	Query          string
+	Role           UserRole
+	IsActive       *bool
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	IncludeDeleted bool
+	SortBy         string
+	SortOrder      string
+	Page           int64
+	PageSize       int64
+}
+
+// UserPage is a page of users returned from a listing
+type UserPage struct {
+	Users    []User `json:"users"`
+	Total    int64  `json:"total"`
+	Page     int64  `json:"page"`
+	PageSize int64  `json:"page_size"`
+}
+
+const (
+	// DefaultPageSize is used when a listing does not specify a page size
+	DefaultPageSize int64 = 20
+	// MaxPageSize is the largest page size a listing may request
+	MaxPageSize int64 = 100
+)
+
+// UserSortFields maps the accepted sort_by values to their stored field names
+var UserSortFields = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"email":      "email",
+}
+
+// Normalize fills in default pagination and sorting values and caps the page size
+func (f *UserFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+
+	if f.SortBy == "" {
+		f.SortBy = "created_at"
+	}
+
+	if f.SortOrder != "asc" {
+		f.SortOrder = "desc"
+	}
+}
+
+// UserSearchTerms returns the terms a user is searched by: the lower-cased words of their names,
+// their lower-cased email and the digits of their phone
+func UserSearchTerms(u *User) []string {
+	terms := strings.Fields(strings.ToLower(u.FirstName + " " + u.LastName))
+	if u.Email != "" {
+		terms = append(terms, strings.ToLower(u.Email))
+	}
+	if phone := phoneDigits(u.Phone); phone != "" {
+		terms = append(terms, phone)
+	}
+
+	return terms
+}
+
+// SearchWords splits a search query into the lower-cased words that must each start a search term.
+// A query that looks like a phone number is a single word of its digits
+func SearchWords(query string) []string {
+	if strings.Trim(query, "0123456789+-(). ") == "" {
+		if phone := phoneDigits(query); phone != "" {
+			return []string{phone}
+		}
+	}
+
+	return strings.Fields(strings.ToLower(query))
+}
+
+func phoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, phone)
+}
@@ -18,4 +18,6 @@ type TokenService interface {
 type AuthService interface {
 	// Login authenticates a user by email and password and returns a token
 	Login(ctx context.Context, req *domain.LoginRequest) (*domain.LoginResponse, domain.CError)
+	// VerifyToken verifies a token and checks that it has not been revoked
+	VerifyToken(ctx context.Context, tokenString string) (domain.Claims, domain.CError)
 }
@@ -0,0 +1,9 @@
+package port
+
+import "context"
+
+// Mailer is an interface for sending emails
+type Mailer interface {
+	// Send sends a plain text email
+	Send(ctx context.Context, to, subject, body string) error
+}
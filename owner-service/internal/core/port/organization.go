@@ -0,0 +1,57 @@
+package port
+
+import (
+	"context"
+
+	"owner-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrganizationRepository is an interface for interacting with organization-related data
+type OrganizationRepository interface {
+	// CreateOrganization inserts a new organization and makes its creator an owner
+	CreateOrganization(ctx context.Context, org *domain.Organization) (*domain.Organization, domain.CError)
+	// GetOrganizationByID fetches an organization using its id
+	GetOrganizationByID(ctx context.Context, id primitive.ObjectID) (*domain.Organization, domain.CError)
+	// ListUserOrganizations fetches all organizations a user is a member of
+	ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]domain.Organization, domain.CError)
+	// AddMember inserts a new membership
+	AddMember(ctx context.Context, member *domain.Membership) (*domain.Membership, domain.CError)
+	// GetMember fetches the membership of a user in an organization
+	GetMember(ctx context.Context, orgID, userID primitive.ObjectID) (*domain.Membership, domain.CError)
+	// ListMembers fetches all memberships of an organization
+	ListMembers(ctx context.Context, orgID primitive.ObjectID) ([]domain.Membership, domain.CError)
+	// CountMembersWithRole counts the members of an organization that have a role
+	CountMembersWithRole(ctx context.Context, orgID primitive.ObjectID, role domain.OrgRole) (int64, domain.CError)
+	// UpdateMemberRole changes the role of a member and returns the updated membership
+	UpdateMemberRole(ctx context.Context, orgID, userID primitive.ObjectID, role domain.OrgRole) (*domain.Membership, domain.CError)
+	// RemoveMember deletes the membership of a user in an organization
+	RemoveMember(ctx context.Context, orgID, userID primitive.ObjectID) domain.CError
+	// CreateInvitation inserts a new invitation
+	CreateInvitation(ctx context.Context, inv *domain.Invitation) (*domain.Invitation, domain.CError)
+	// GetInvitationByTokenHash fetches a pending invitation using the hash of its token
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*domain.Invitation, domain.CError)
+	// MarkInvitationAccepted records that an invitation has been used
+	MarkInvitationAccepted(ctx context.Context, id primitive.ObjectID) domain.CError
+}
+
+// OrganizationService is an interface for interacting with organization-related business logic
+type OrganizationService interface {
+	// CreateOrganization creates an organization owned by the user
+	CreateOrganization(ctx context.Context, userID primitive.ObjectID, req *domain.CreateOrganizationRequest) (*domain.Organization, domain.CError)
+	// GetOrganization returns an organization the user is a member of
+	GetOrganization(ctx context.Context, userID, orgID primitive.ObjectID) (*domain.Organization, domain.CError)
+	// ListUserOrganizations returns all organizations the user is a member of
+	ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]domain.Organization, domain.CError)
+	// ListMembers returns the members of an organization the user belongs to
+	ListMembers(ctx context.Context, userID, orgID primitive.ObjectID) ([]domain.Membership, domain.CError)
+	// InviteMember creates an invitation and publishes its token to be emailed
+	InviteMember(ctx context.Context, userID, orgID primitive.ObjectID, req *domain.InviteMemberRequest) (*domain.Invitation, domain.CError)
+	// AcceptInvitation makes the user a member of the organization they were invited to
+	AcceptInvitation(ctx context.Context, userID primitive.ObjectID, req *domain.AcceptInvitationRequest) (*domain.Membership, domain.CError)
+	// UpdateMemberRole changes the role of a member
+	UpdateMemberRole(ctx context.Context, userID, orgID, memberID primitive.ObjectID, req *domain.UpdateMemberRoleRequest) (*domain.Membership, domain.CError)
+	// RemoveMember removes a member from an organization. Members may remove themselves
+	RemoveMember(ctx context.Context, userID, orgID, memberID primitive.ObjectID) domain.CError
+}
@@ -16,12 +16,16 @@ type UserRepository interface {
 	GetUserByID(ctx context.Context, id primitive.ObjectID) (*domain.User, domain.CError)
 	// GetUserByEmail fetches a new user from the database using it's email
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, domain.CError)
-	// ListUsers fetches and returns all users in the database
-	ListUsers(ctx context.Context) ([]domain.User, domain.CError)
+	// GetUsersByIDs fetches all users that correspond to a list of user ids
+	GetUsersByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.User, domain.CError)
+	// ListUsers fetches a page of users matching the filter and the total number of matches
+	ListUsers(ctx context.Context, filter *domain.UserFilter) ([]domain.User, int64, domain.CError)
 	// UpdateUser updates a user in the database and returns the updated user
 	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, domain.CError)
 	// DeleteUser performs a soft delete on a user specified by its id
 	DeleteUser(ctx context.Context, id primitive.ObjectID) domain.CError
+	// UpdatePassword replaces a user's password hash and revokes all previously issued tokens
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) domain.CError
 }
 
 // UserService is an interface for interacting with User-related business logic
@@ -30,12 +34,16 @@ type UserService interface {
 	RegisterUser(ctx context.Context, user *domain.CreateUserRequest) (*domain.User, domain.CError)
 	// GetUser returns a user specified by its id
 	GetUser(ctx context.Context, id primitive.ObjectID) (*domain.User, domain.CError)
-	// ListUsers returns all users in the system
-	ListUsers(ctx context.Context) ([]domain.User, domain.CError)
+	// ListUsers returns a page of users in the system matching the filter
+	ListUsers(ctx context.Context, filter *domain.UserFilter) (*domain.UserPage, domain.CError)
 	// UpdateUser updates a user with the specified details and returns the updated user
 	UpdateUser(ctx context.Context, id primitive.ObjectID, user *domain.UpdateUserRequest) (*domain.User, domain.CError)
 	// DeleteUser deletes a user specified by id
 	DeleteUser(ctx context.Context, id primitive.ObjectID) domain.CError
+	// ChangePassword changes the password of a user after checking the current one
+	ChangePassword(ctx context.Context, id primitive.ObjectID, req *domain.ChangePasswordRequest) domain.CError
+	// DeleteAccount lets a user close their own account after re-entering their password
+	DeleteAccount(ctx context.Context, id primitive.ObjectID, req *domain.DeleteAccountRequest) domain.CError
 	// CreateAdminUser is an admin-only function used to create an admin user
 	CreateAdminUser(ctx context.Context, email, password string) domain.CError
 }
@@ -8,6 +8,7 @@ import (
 	"owner-service/internal/core/port"
 	"owner-service/internal/core/util"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 )
 
@@ -62,3 +63,35 @@ func (as *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*do
 		User:  *user,
 	}, nil
 }
+
+// VerifyToken verifies the token signature and rejects tokens belonging to deleted users
+// or issued before the user's tokens were last revoked
+func (as *AuthService) VerifyToken(ctx context.Context, tokenString string) (domain.Claims, domain.CError) {
+	claims, cerr := as.ts.VerifyToken(tokenString)
+	if cerr != nil {
+		return domain.Claims{}, cerr
+	}
+
+	id, err := primitive.ObjectIDFromHex(claims.ID)
+	if err != nil {
+		return domain.Claims{}, domain.ErrInvalidToken
+	}
+
+	user, cerr := as.repo.GetUserByID(ctx, id)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return domain.Claims{}, domain.ErrInvalidToken
+		}
+
+		logger.FromCtx(ctx).Error("Error fetching user by id", zap.Error(cerr))
+		return domain.Claims{}, domain.ErrInternal
+	}
+
+	// Tokens issued up to the revocation are rejected. Both times are kept to the millisecond, and a
+	// token issued in the same millisecond is treated as issued before
+	if user.TokensRevokedAt != nil && !claims.IssuedAt.After(*user.TokensRevokedAt) {
+		return domain.Claims{}, domain.ErrRevokedToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"owner-service/internal/core/domain"
+	"owner-service/internal/core/port"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeUserRepo serves the users it holds, or fails every lookup with err
+type fakeUserRepo struct {
+	port.UserRepository
+	users map[primitive.ObjectID]*domain.User
+	err   domain.CError
+}
+
+func (r *fakeUserRepo) GetUserByID(ctx context.Context, id primitive.ObjectID) (*domain.User, domain.CError) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrDataNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, domain.CError) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrDataNotFound
+}
+
+// fakeTokens verifies every token to claims, or fails every verification with err
+type fakeTokens struct {
+	claims domain.Claims
+	err    domain.CError
+}
+
+func (t fakeTokens) CreateToken(id, email string, role string) (string, error) {
+	return "token", nil
+}
+
+func (t fakeTokens) VerifyToken(tokenString string) (domain.Claims, domain.CError) {
+	if t.err != nil {
+		return domain.Claims{}, t.err
+	}
+	return t.claims, nil
+}
+
+func TestAuthServiceVerifyToken(t *testing.T) {
+	userID := primitive.NewObjectID()
+	revokedAt := time.Date(2026, 1, 2, 15, 4, 5, 250*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name      string
+		issuedAt  time.Time
+		revokedAt *time.Time
+		claimsID  string
+		tokenErr  domain.CError
+		repoErr   domain.CError
+		wantErr   domain.CError
+	}{
+		{name: "tokens never revoked", issuedAt: revokedAt},
+		{name: "issued after the revocation", issuedAt: revokedAt.Add(time.Millisecond), revokedAt: &revokedAt},
+		{name: "issued at the revocation", issuedAt: revokedAt, revokedAt: &revokedAt, wantErr: domain.ErrRevokedToken},
+		{name: "issued before the revocation", issuedAt: revokedAt.Add(-time.Millisecond), revokedAt: &revokedAt, wantErr: domain.ErrRevokedToken},
+		{
+			name:      "issued later in the second of the revocation",
+			issuedAt:  revokedAt.Truncate(time.Second).Add(900 * time.Millisecond),
+			revokedAt: &revokedAt,
+		},
+		{
+			name:      "issued earlier in the second of the revocation",
+			issuedAt:  revokedAt.Truncate(time.Second),
+			revokedAt: &revokedAt,
+			wantErr:   domain.ErrRevokedToken,
+		},
+		{name: "invalid token", tokenErr: domain.ErrExpiredToken, wantErr: domain.ErrExpiredToken},
+		{name: "token of an unknown user", claimsID: primitive.NewObjectID().Hex(), wantErr: domain.ErrInvalidToken},
+		{name: "token with an invalid user id", claimsID: "not-an-id", wantErr: domain.ErrInvalidToken},
+		{name: "users cannot be read", repoErr: domain.ErrInternal, wantErr: domain.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimsID := tt.claimsID
+			if claimsID == "" {
+				claimsID = userID.Hex()
+			}
+
+			repo := &fakeUserRepo{
+				users: map[primitive.ObjectID]*domain.User{userID: {ID: userID, TokensRevokedAt: tt.revokedAt}},
+				err:   tt.repoErr,
+			}
+			tokens := fakeTokens{claims: domain.Claims{ID: claimsID, IssuedAt: tt.issuedAt}, err: tt.tokenErr}
+
+			claims, cerr := NewAuthService(repo, tokens).VerifyToken(context.Background(), "token")
+			if cerr != tt.wantErr {
+				t.Fatalf("VerifyToken() error = %v, want %v", cerr, tt.wantErr)
+			}
+			if cerr == nil && claims.ID != userID.Hex() {
+				t.Errorf("VerifyToken() claims = %+v, want the claims of the token", claims)
+			}
+		})
+	}
+}
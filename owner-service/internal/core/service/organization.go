@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"owner-service/internal/adapter/logger"
+	"owner-service/internal/core/domain"
+	"owner-service/internal/core/port"
+	"owner-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+/**
+ * OrganizationService implements port.OrganizationService interface
+ */
+type OrganizationService struct {
+	repo     port.OrganizationRepository
+	userRepo port.UserRepository
+	producer port.MessageQueueRepository
+	mailer   port.Mailer
+}
+
+// NewOrganizationService creates a new organization service instance
+func NewOrganizationService(repo port.OrganizationRepository, userRepo port.UserRepository, producer port.MessageQueueRepository, mailer port.Mailer) *OrganizationService {
+	return &OrganizationService{
+		repo:     repo,
+		userRepo: userRepo,
+		producer: producer,
+		mailer:   mailer,
+	}
+}
+
+// internalOrCError logs unexpected errors and hides their details from the caller
+func internalOrCError(ctx context.Context, msg string, cerr domain.CError) domain.CError {
+	if cerr.Code() == 500 {
+		logger.FromCtx(ctx).Error(msg, zap.Error(cerr))
+		return domain.ErrInternal
+	}
+	return cerr
+}
+
+// authorize returns the membership of the user in the organization and checks that it grants the permission.
+// An empty permission only requires the user to be a member
+func (ors *OrganizationService) authorize(ctx context.Context, userID, orgID primitive.ObjectID, permission string) (*domain.Membership, domain.CError) {
+	member, cerr := ors.repo.GetMember(ctx, orgID, userID)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.ErrForbidden
+		}
+		return nil, internalOrCError(ctx, "Error getting membership", cerr)
+	}
+
+	if permission != "" && !member.Role.Can(permission) {
+		return nil, domain.ErrForbidden
+	}
+
+	return member, nil
+}
+
+func (ors *OrganizationService) CreateOrganization(ctx context.Context, userID primitive.ObjectID, req *domain.CreateOrganizationRequest) (*domain.Organization, domain.CError) {
+	org, cerr := ors.repo.CreateOrganization(ctx, &domain.Organization{
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+	})
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error creating organization", cerr)
+	}
+
+	return org, nil
+}
+
+func (ors *OrganizationService) GetOrganization(ctx context.Context, userID, orgID primitive.ObjectID) (*domain.Organization, domain.CError) {
+	if _, cerr := ors.authorize(ctx, userID, orgID, ""); cerr != nil {
+		return nil, cerr
+	}
+
+	org, cerr := ors.repo.GetOrganizationByID(ctx, orgID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error getting organization", cerr)
+	}
+
+	return org, nil
+}
+
+func (ors *OrganizationService) ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]domain.Organization, domain.CError) {
+	orgs, cerr := ors.repo.ListUserOrganizations(ctx, userID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error listing organizations", cerr)
+	}
+
+	return orgs, nil
+}
+
+func (ors *OrganizationService) ListMembers(ctx context.Context, userID, orgID primitive.ObjectID) ([]domain.Membership, domain.CError) {
+	if _, cerr := ors.authorize(ctx, userID, orgID, ""); cerr != nil {
+		return nil, cerr
+	}
+
+	members, cerr := ors.repo.ListMembers(ctx, orgID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error listing members", cerr)
+	}
+
+	return members, nil
+}
+
+func (ors *OrganizationService) InviteMember(ctx context.Context, userID, orgID primitive.ObjectID, req *domain.InviteMemberRequest) (*domain.Invitation, domain.CError) {
+	log := logger.FromCtx(ctx)
+	member, cerr := ors.authorize(ctx, userID, orgID, domain.PermManageMembers)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	// Only owners can hand out the owner role
+	if req.Role == domain.OrgRoleOwner && member.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrForbidden
+	}
+
+	org, cerr := ors.repo.GetOrganizationByID(ctx, orgID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error getting organization", cerr)
+	}
+
+	token, err := util.GenerateToken(32)
+	if err != nil {
+		log.Error("Error generating invitation token", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	inv, cerr := ors.repo.CreateInvitation(ctx, &domain.Invitation{
+		OrganizationID: orgID,
+		Email:          strings.ToLower(strings.TrimSpace(req.Email)),
+		Role:           req.Role,
+		TokenHash:      util.HashToken(token),
+		InvitedBy:      userID,
+		ExpiresAt:      time.Now().Add(domain.InvitationTTL),
+	})
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error creating invitation", cerr)
+	}
+
+	// The token only leaves the service through the queue, to be emailed to the invitee
+	msg, err := util.Serialize(domain.InvitationForQueue{
+		InvitationID:     inv.ID.Hex(),
+		OrganizationID:   org.ID.Hex(),
+		OrganizationName: org.Name,
+		Email:            inv.Email,
+		Role:             inv.Role.String(),
+		Token:            token,
+		ExpiresAt:        inv.ExpiresAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Error("Error serializing invitation", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	correlationId := ctx.Value(domain.CorrelationIDCtxKey)
+	headers := map[string]any{string(domain.CorrelationIDCtxKey): correlationId}
+	if err := ors.producer.Publish(ctx, "organization-invitations", msg, headers); err != nil {
+		log.Error("Error publishing invitation to the queue", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	return inv, nil
+}
+
+func (ors *OrganizationService) AcceptInvitation(ctx context.Context, userID primitive.ObjectID, req *domain.AcceptInvitationRequest) (*domain.Membership, domain.CError) {
+	inv, cerr := ors.repo.GetInvitationByTokenHash(ctx, util.HashToken(req.Token))
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.NewBadRequestCError("invitation is invalid or has expired")
+		}
+		return nil, internalOrCError(ctx, "Error getting invitation", cerr)
+	}
+
+	user, cerr := ors.userRepo.GetUserByID(ctx, userID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error getting user", cerr)
+	}
+
+	// The invitation is bound to the email address it was sent to
+	if !strings.EqualFold(user.Email, inv.Email) {
+		return nil, domain.ErrForbidden
+	}
+
+	// The member is added before the invitation is used up, so a failure leaves the invitation usable
+	member, cerr := ors.repo.AddMember(ctx, &domain.Membership{
+		OrganizationID: inv.OrganizationID,
+		UserID:         userID,
+		Role:           inv.Role,
+	})
+	if cerr != nil {
+		if cerr.Code() == 409 {
+			return nil, domain.NewCError(cerr.Code(), "user is already a member of the organization")
+		}
+		return nil, internalOrCError(ctx, "Error adding member", cerr)
+	}
+
+	// Only one request can use the invitation, and the membership added by any other is removed
+	if cerr := ors.repo.MarkInvitationAccepted(ctx, inv.ID); cerr != nil {
+		if rerr := ors.repo.RemoveMember(ctx, inv.OrganizationID, userID); rerr != nil {
+			logger.FromCtx(ctx).Error("Error removing the member of an invitation that was not accepted", zap.Error(rerr))
+		}
+
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.NewBadRequestCError("invitation is invalid or has expired")
+		}
+		return nil, internalOrCError(ctx, "Error accepting invitation", cerr)
+	}
+
+	return member, nil
+}
+
+// SendInvitationFromQueue emails the token of an invitation consumed from the "organization-invitations"
+// queue to the invitee
+func (ors *OrganizationService) SendInvitationFromQueue(log *zap.Logger, msg []byte) error {
+	var inv domain.InvitationForQueue
+	if err := util.Deserialize(msg, &inv); err != nil {
+		// The message can never be handled, so it is dropped instead of requeued
+		log.Error("Could not deserialize invitation", zap.Error(err))
+		return nil
+	}
+
+	log.Info("Sending invitation", zap.String("invitation_id", inv.InvitationID))
+
+	subject := fmt.Sprintf("You are invited to join %s", inv.OrganizationName)
+	body := fmt.Sprintf("You have been invited to join %s as %s.\r\n\r\n"+
+		"Sign in with this email address and accept the invitation with the token below before %s.\r\n\r\n%s\r\n",
+		inv.OrganizationName, inv.Role, inv.ExpiresAt, inv.Token)
+
+	if err := ors.mailer.Send(context.Background(), inv.Email, subject, body); err != nil {
+		log.Error("Could not send invitation", zap.String("invitation_id", inv.InvitationID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (ors *OrganizationService) UpdateMemberRole(ctx context.Context, userID, orgID, memberID primitive.ObjectID, req *domain.UpdateMemberRoleRequest) (*domain.Membership, domain.CError) {
+	actor, cerr := ors.authorize(ctx, userID, orgID, domain.PermManageMembers)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	target, cerr := ors.repo.GetMember(ctx, orgID, memberID)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error getting membership", cerr)
+	}
+
+	// Only owners can grant the owner role or change the role of another owner
+	if (req.Role == domain.OrgRoleOwner || target.Role == domain.OrgRoleOwner) && actor.Role != domain.OrgRoleOwner {
+		return nil, domain.ErrForbidden
+	}
+
+	if target.Role == domain.OrgRoleOwner && req.Role != domain.OrgRoleOwner {
+		if cerr := ors.ensureAnotherOwner(ctx, orgID); cerr != nil {
+			return nil, cerr
+		}
+	}
+
+	member, cerr := ors.repo.UpdateMemberRole(ctx, orgID, memberID, req.Role)
+	if cerr != nil {
+		return nil, internalOrCError(ctx, "Error updating membership", cerr)
+	}
+
+	return member, nil
+}
+
+func (ors *OrganizationService) RemoveMember(ctx context.Context, userID, orgID, memberID primitive.ObjectID) domain.CError {
+	permission := domain.PermManageMembers
+	if userID == memberID {
+		permission = ""
+	}
+
+	actor, cerr := ors.authorize(ctx, userID, orgID, permission)
+	if cerr != nil {
+		return cerr
+	}
+
+	target, cerr := ors.repo.GetMember(ctx, orgID, memberID)
+	if cerr != nil {
+		return internalOrCError(ctx, "Error getting membership", cerr)
+	}
+
+	if target.Role == domain.OrgRoleOwner {
+		if userID != memberID && actor.Role != domain.OrgRoleOwner {
+			return domain.ErrForbidden
+		}
+		if cerr := ors.ensureAnotherOwner(ctx, orgID); cerr != nil {
+			return cerr
+		}
+	}
+
+	if cerr := ors.repo.RemoveMember(ctx, orgID, memberID); cerr != nil {
+		return internalOrCError(ctx, "Error removing member", cerr)
+	}
+
+	return nil
+}
+
+// ensureAnotherOwner prevents an organization from being left without an owner
+func (ors *OrganizationService) ensureAnotherOwner(ctx context.Context, orgID primitive.ObjectID) domain.CError {
+	owners, cerr := ors.repo.CountMembersWithRole(ctx, orgID, domain.OrgRoleOwner)
+	if cerr != nil {
+		return internalOrCError(ctx, "Error counting owners", cerr)
+	}
+
+	if owners <= 1 {
+		return domain.NewBadRequestCError("organization must have at least one owner")
+	}
+
+	return nil
+}
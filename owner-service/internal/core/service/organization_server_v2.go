@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"owner-service/internal/core/domain"
+	"owner-service/internal/core/port"
+	userv2 "owner-service/internal/core/service/user/v2"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ userv2.OrganizationServer = (*organizationServerV2)(nil)
+
+type organizationServerV2 struct {
+	userv2.UnimplementedOrganizationServer
+	config  *Config
+	orgRepo port.OrganizationRepository
+}
+
+func newOrganizationServerV2(config *Config, orgRepo port.OrganizationRepository) (srv *organizationServerV2, err error) {
+	srv = &organizationServerV2{
+		config:  config,
+		orgRepo: orgRepo,
+	}
+	return srv, nil
+}
+
+// GetMembership reports whether a user belongs to an organization and the permissions of their role.
+// Unknown users and organizations are reported as non members rather than errors
+func (s *organizationServerV2) GetMembership(ctx context.Context, req *userv2.GetMembershipRequest) (*userv2.GetMembershipResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 GetMembership request", zap.String("organization_id", req.OrganizationId), zap.String("user_id", req.UserId))
+
+	orgID, err := primitive.ObjectIDFromHex(req.OrganizationId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid organization id")
+	}
+
+	userID, err := primitive.ObjectIDFromHex(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	if _, cerr := s.orgRepo.GetOrganizationByID(ctx, orgID); cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return &userv2.GetMembershipResponse{IsMember: false}, nil
+		}
+		logger.Error("Failed to get organization", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	member, cerr := s.orgRepo.GetMember(ctx, orgID, userID)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return &userv2.GetMembershipResponse{IsMember: false}, nil
+		}
+		logger.Error("Failed to get membership", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	role, err := userv2.StringToOrganizationRole(member.Role.String())
+	if err != nil {
+		logger.Error("Failed to map organization role", zap.Error(err))
+		return nil, status.Error(codes.Internal, domain.ErrInternal.Error())
+	}
+
+	return &userv2.GetMembershipResponse{
+		IsMember:    true,
+		Role:        role,
+		Permissions: domain.OrgRolePermissions[member.Role],
+	}, nil
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/http"
 
+	"owner-service/internal/adapter/config"
 	"owner-service/internal/adapter/logger"
 	"owner-service/internal/core/domain"
 	"owner-service/internal/core/port"
 	"owner-service/internal/core/service/user"
+	userv2 "owner-service/internal/core/service/user/v2"
 	"owner-service/internal/core/util"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -20,14 +22,18 @@ import (
 type UserService struct {
 	repo     port.UserRepository
 	producer port.MessageQueueRepository
+	// publishLegacyUpdates keeps v1 messages flowing to "user-updates" for outside consumers that
+	// have not moved to "user-updates.v2" yet
+	publishLegacyUpdates bool
 }
 
 // NewUserService creates a new auth service instance
 func NewUserService(repo port.UserRepository, producer port.MessageQueueRepository) *UserService {
 
 	return &UserService{
-		repo:     repo,
-		producer: producer,
+		repo:                 repo,
+		producer:             producer,
+		publishLegacyUpdates: config.GetConfig().Rabbitmq.PublishLegacyUserUpdates,
 	}
 }
 
@@ -81,8 +87,13 @@ func (us *UserService) GetUser(ctx context.Context, id primitive.ObjectID) (*dom
 	return user, nil
 }
 
-func (us *UserService) ListUsers(ctx context.Context) ([]domain.User, domain.CError) {
-	users, cerr := us.repo.ListUsers(ctx)
+func (us *UserService) ListUsers(ctx context.Context, filter *domain.UserFilter) (*domain.UserPage, domain.CError) {
+	filter.Normalize()
+	if _, ok := domain.UserSortFields[filter.SortBy]; !ok {
+		return nil, domain.NewBadRequestCError("invalid sort field specified: " + filter.SortBy)
+	}
+
+	users, total, cerr := us.repo.ListUsers(ctx, filter)
 	if cerr != nil {
 
 		logger.FromCtx(ctx).Error("Error listing user", zap.Error(cerr))
@@ -93,7 +104,12 @@ func (us *UserService) ListUsers(ctx context.Context) ([]domain.User, domain.CEr
 		users[i].Password = ""
 	}
 
-	return users, nil
+	return &domain.UserPage{
+		Users:    users,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
 }
 
 func (us *UserService) UpdateUser(ctx context.Context, id primitive.ObjectID, req *domain.UpdateUserRequest) (*domain.User, domain.CError) {
@@ -126,52 +142,83 @@ func (us *UserService) UpdateUser(ctx context.Context, id primitive.ObjectID, re
 	}
 	userResponse.Password = ""
 
-	// Produce update to the queue
-	ownerUpdate := user.UserUpdateForQueue{
-		Id:        userResponse.ID.Hex(),
-		FirstName: userResponse.FirstName,
-		LastName:  userResponse.LastName,
-		Email:     userResponse.Email,
-		Password:  userResponse.Password,
-		Phone:     userResponse.Phone,
-		IsActive:  userResponse.IsActive,
-		CreatedAt: userResponse.CreatedAt.String(),
-		UpdatedAt: userResponse.UpdatedAt.String(),
+	// Publishing errors do not affect the request
+	us.publishUserUpdate(ctx, userResponse)
+
+	return userResponse, nil
+}
+
+func (us *UserService) DeleteUser(ctx context.Context, id primitive.ObjectID) domain.CError {
+	cerr := us.repo.DeleteUser(ctx, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+
+			logger.FromCtx(ctx).Error("Error deleting user", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+		return cerr
 	}
 
-	msg, err := util.Serialize(ownerUpdate)
-	if err != nil {
-		log.Error("Error serializing user update", zap.Error(cerr))
-		return userResponse, nil
+	return nil
+}
+
+func (us *UserService) ChangePassword(ctx context.Context, id primitive.ObjectID, req *domain.ChangePasswordRequest) domain.CError {
+	log := logger.FromCtx(ctx)
+	retUser, cerr := us.repo.GetUserByID(ctx, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+
+			log.Error("Error getting user", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+		return cerr
 	}
 
-	// Publish message to queue
-	queue := "user-updates"
-	log.Info("Publishing message about update to queue", zap.String("queue", queue))
-	correlationId := ctx.Value(domain.CorrelationIDCtxKey)
+	if err := util.ComparePassword(req.CurrentPassword, retUser.Password); err != nil {
+		return domain.ErrIncorrectPassword
+	}
 
-	err = us.producer.Publish(ctx, queue, msg, map[string]any{string(domain.CorrelationIDCtxKey): correlationId})
+	if req.CurrentPassword == req.NewPassword {
+		return domain.NewBadRequestCError("new password must be different from the current password")
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
 	if err != nil {
-		log.Error("Error publishing message to the queue", zap.Error(err))
-		return userResponse, nil
+
+		log.Error("Error hashing user password", zap.Error(err))
+		return domain.ErrInternal
 	}
-	log.Info("Successfully published message about update to queue")
 
-	return userResponse, nil
+	cerr = us.repo.UpdatePassword(ctx, id, hashedPassword)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+
+			log.Error("Error updating user password", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+		return cerr
+	}
+
+	return nil
 }
 
-func (us *UserService) DeleteUser(ctx context.Context, id primitive.ObjectID) domain.CError {
-	cerr := us.repo.DeleteUser(ctx, id)
+func (us *UserService) DeleteAccount(ctx context.Context, id primitive.ObjectID, req *domain.DeleteAccountRequest) domain.CError {
+	retUser, cerr := us.repo.GetUserByID(ctx, id)
 	if cerr != nil {
 		if cerr.Code() == 500 {
 
-			logger.FromCtx(ctx).Error("Error deleting user", zap.Error(cerr))
+			logger.FromCtx(ctx).Error("Error getting user", zap.Error(cerr))
 			return domain.ErrInternal
 		}
 		return cerr
 	}
 
-	return nil
+	if err := util.ComparePassword(req.Password, retUser.Password); err != nil {
+		return domain.ErrIncorrectPassword
+	}
+
+	// Tokens of deleted users are rejected when they are verified
+	return us.DeleteUser(ctx, id)
 }
 
 func (us *UserService) CreateAdminUser(ctx context.Context, email, password string) domain.CError {
@@ -188,3 +235,47 @@ func (us *UserService) CreateAdminUser(ctx context.Context, email, password stri
 
 	return nil
 }
+
+// publishUserUpdate publishes a v2 profile update to the "user-updates.v2" queue and,
+// while the v1 schema is still supported, a v1 message to the "user-updates" queue
+func (us *UserService) publishUserUpdate(ctx context.Context, u *domain.User) {
+	log := logger.FromCtx(ctx)
+	correlationId := ctx.Value(domain.CorrelationIDCtxKey)
+	headers := map[string]any{string(domain.CorrelationIDCtxKey): correlationId}
+
+	event := &userv2.UserUpdatedEvent{
+		SchemaVersion: userv2.UserUpdatedEventSchemaVersion,
+		User:          toUserProfile(u),
+	}
+
+	messages := map[string]any{"user-updates.v2": event}
+	if us.publishLegacyUpdates {
+		messages["user-updates"] = user.UserUpdateForQueue{
+			Id:        u.ID.Hex(),
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Email:     u.Email,
+			Phone:     u.Phone,
+			IsActive:  u.IsActive,
+			CreatedAt: u.CreatedAt.String(),
+			UpdatedAt: u.UpdatedAt.String(),
+		}
+	}
+
+	for queue, payload := range messages {
+		msg, err := util.Serialize(payload)
+		if err != nil {
+			log.Error("Error serializing user update", zap.String("queue", queue), zap.Error(err))
+			continue
+		}
+
+		// Publish message to queue
+		log.Info("Publishing message about update to queue", zap.String("queue", queue))
+		err = us.producer.Publish(ctx, queue, msg, headers)
+		if err != nil {
+			log.Error("Error publishing message to the queue", zap.String("queue", queue), zap.Error(err))
+			continue
+		}
+		log.Info("Successfully published message about update to queue", zap.String("queue", queue))
+	}
+}
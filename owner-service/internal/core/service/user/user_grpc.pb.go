@@ -25,7 +25,10 @@ const (
 // UserClient is the client API for User service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Deprecated: use user.v2.User, which never exposes credentials
 type UserClient interface {
+	// Deprecated: Do not use.
 	Get(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error)
 }
 
@@ -37,6 +40,7 @@ func NewUserClient(cc grpc.ClientConnInterface) UserClient {
 	return &userClient{cc}
 }
 
+// Deprecated: Do not use.
 func (c *userClient) Get(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UserResponse)
@@ -50,7 +54,10 @@ func (c *userClient) Get(ctx context.Context, in *UserRequest, opts ...grpc.Call
 // UserServer is the server API for User service.
 // All implementations must embed UnimplementedUserServer
 // for forward compatibility.
+//
+// Deprecated: use user.v2.User, which never exposes credentials
 type UserServer interface {
+	// Deprecated: Do not use.
 	Get(context.Context, *UserRequest) (*UserResponse, error)
 	mustEmbedUnimplementedUserServer()
 }
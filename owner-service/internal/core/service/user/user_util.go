@@ -26,6 +26,10 @@ func StringToUserRole(userRoleStr string) (UserRole, error) {
 	}
 }
 
+// UserUpdateForQueue is the v1 schema of messages on the "user-updates" queue.
+//
+// Deprecated: consume userv2.UserUpdatedEvent from the "user-updates.v2" queue instead.
+// Password is never filled and is only kept so that v1 consumers keep decoding.
 type UserUpdateForQueue struct {
 	Id        string   `json:"id,omitempty"`
 	FirstName string   `json:"first_name,omitempty"`
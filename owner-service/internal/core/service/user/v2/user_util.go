@@ -0,0 +1,60 @@
+package userv2
+
+import "fmt"
+
+// UserUpdatedEventSchemaVersion is the schema version carried by every UserUpdatedEvent
+const UserUpdatedEventSchemaVersion = 2
+
+// UserRoleToString maps UserRole enum values to their string representations.
+// USER_ROLE_UNSPECIFIED maps to an empty string.
+func UserRoleToString(userRole UserRole) string {
+	switch userRole {
+	case UserRole_USER_ROLE_ADMIN:
+		return "admin"
+	case UserRole_USER_ROLE_USER:
+		return "user"
+	default:
+		return ""
+	}
+}
+
+// StringToUserRole maps string representations to UserRole enum values.
+func StringToUserRole(userRoleStr string) (UserRole, error) {
+	switch userRoleStr {
+	case "admin":
+		return UserRole_USER_ROLE_ADMIN, nil
+	case "user":
+		return UserRole_USER_ROLE_USER, nil
+	default:
+		return UserRole_USER_ROLE_UNSPECIFIED, fmt.Errorf("unknown user type: %s", userRoleStr)
+	}
+}
+
+// OrganizationRoleToString maps OrganizationRole enum values to their string representations.
+// ORGANIZATION_ROLE_UNSPECIFIED maps to an empty string.
+func OrganizationRoleToString(role OrganizationRole) string {
+	switch role {
+	case OrganizationRole_ORGANIZATION_ROLE_OWNER:
+		return "owner"
+	case OrganizationRole_ORGANIZATION_ROLE_MANAGER:
+		return "manager"
+	case OrganizationRole_ORGANIZATION_ROLE_STAFF:
+		return "staff"
+	default:
+		return ""
+	}
+}
+
+// StringToOrganizationRole maps string representations to OrganizationRole enum values.
+func StringToOrganizationRole(roleStr string) (OrganizationRole, error) {
+	switch roleStr {
+	case "owner":
+		return OrganizationRole_ORGANIZATION_ROLE_OWNER, nil
+	case "manager":
+		return OrganizationRole_ORGANIZATION_ROLE_MANAGER, nil
+	case "staff":
+		return OrganizationRole_ORGANIZATION_ROLE_STAFF, nil
+	default:
+		return OrganizationRole_ORGANIZATION_ROLE_UNSPECIFIED, fmt.Errorf("unknown organization role: %s", roleStr)
+	}
+}
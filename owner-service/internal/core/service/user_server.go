@@ -4,6 +4,8 @@ import (
 	"context"
 	"owner-service/internal/core/port"
 	"owner-service/internal/core/service/user"
+	userv2 "owner-service/internal/core/service/user/v2"
+	"pkg/grpcauth"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
@@ -19,6 +21,8 @@ import (
 var _ user.UserServer = (*grpcServer)(nil)
 
 type Config struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
 }
 
 type grpcServer struct {
@@ -27,7 +31,7 @@ type grpcServer struct {
 	userRepo port.UserRepository
 }
 
-func NewGRPCServer(config *Config, userRepo port.UserRepository, opts ...grpc.ServerOption) (*grpc.Server, error) {
+func NewGRPCServer(config *Config, userRepo port.UserRepository, orgRepo port.OrganizationRepository, authService port.AuthService, opts ...grpc.ServerOption) (*grpc.Server, error) {
 
 	logger := zap.L().Named("grpc_server")
 	zapOpts := []grpc_zap.Option{
@@ -45,6 +49,7 @@ func NewGRPCServer(config *Config, userRepo port.UserRepository, opts ...grpc.Se
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
 			grpc_ctxtags.UnaryServerInterceptor(),
 			grpc_zap.UnaryServerInterceptor(logger, zapOpts...),
+			grpcauth.UnaryServerInterceptor(config.Clients),
 		)),
 	)
 
@@ -54,7 +59,19 @@ func NewGRPCServer(config *Config, userRepo port.UserRepository, opts ...grpc.Se
 		return nil, err
 	}
 
+	srvV2, err := newgrpcServerV2(config, userRepo, authService)
+	if err != nil {
+		return nil, err
+	}
+
+	orgSrvV2, err := newOrganizationServerV2(config, orgRepo)
+	if err != nil {
+		return nil, err
+	}
+
 	user.RegisterUserServer(gsrv, srv)
+	userv2.RegisterUserServer(gsrv, srvV2)
+	userv2.RegisterOrganizationServer(gsrv, orgSrvV2)
 	return gsrv, nil
 }
 
@@ -66,12 +83,14 @@ func newgrpcServer(config *Config, userRepo port.UserRepository) (srv *grpcServe
 	return srv, nil
 }
 
+// Get returns a user in the v1 schema. The deprecated password field is left empty.
+//
+// Deprecated: use the user.v2.User service.
 func (s *grpcServer) Get(ctx context.Context, req *user.UserRequest) (*user.UserResponse, error) {
 	// Extract the logger from the context
 	logger := zap.L().Named("grpc_server")
 	logger.Info("Received Get request", zap.String("user_id", req.UserId))
 
-	// TODO: authorize user through a mechanism
 	userId, err := primitive.ObjectIDFromHex(req.UserId)
 	if err != nil {
 		logger.Error("Failed to parse user ID", zap.Error(err))
@@ -90,7 +109,6 @@ func (s *grpcServer) Get(ctx context.Context, req *user.UserRequest) (*user.User
 		FirstName: retUser.FirstName,
 		LastName:  retUser.LastName,
 		Email:     retUser.Email,
-		Password:  retUser.Password,
 		Phone:     retUser.Phone,
 		IsActive:  retUser.IsActive,
 		CreatedAt: retUser.CreatedAt.String(),
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"owner-service/internal/core/domain"
+	"owner-service/internal/core/port"
+	userv2 "owner-service/internal/core/service/user/v2"
+	"owner-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ userv2.UserServer = (*grpcServerV2)(nil)
+
+type grpcServerV2 struct {
+	userv2.UnimplementedUserServer
+	config      *Config
+	userRepo    port.UserRepository
+	authService port.AuthService
+	// dummyHash is the hash passwords are compared against when the email of the credentials is unknown
+	dummyHash string
+}
+
+func newgrpcServerV2(config *Config, userRepo port.UserRepository, authService port.AuthService) (srv *grpcServerV2, err error) {
+	dummyHash, err := util.HashPassword("dummy-password")
+	if err != nil {
+		return nil, err
+	}
+
+	srv = &grpcServerV2{
+		config:      config,
+		userRepo:    userRepo,
+		authService: authService,
+		dummyHash:   dummyHash,
+	}
+	return srv, nil
+}
+
+func (s *grpcServerV2) Get(ctx context.Context, req *userv2.GetUserRequest) (*userv2.UserProfile, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 Get request", zap.String("user_id", req.UserId))
+
+	userId, err := primitive.ObjectIDFromHex(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	retUser, cerr := s.userRepo.GetUserByID(ctx, userId)
+	if cerr != nil {
+		logger.Error("Failed to get user", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	return toUserProfile(retUser), nil
+}
+
+func (s *grpcServerV2) GetMany(ctx context.Context, req *userv2.GetUsersRequest) (*userv2.UsersResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 GetMany request", zap.Strings("user_ids", req.UserIds))
+
+	userIds := make([]primitive.ObjectID, 0, len(req.UserIds))
+	for _, id := range req.UserIds {
+		oId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user id %q", id)
+		}
+		userIds = append(userIds, oId)
+	}
+
+	users, cerr := s.userRepo.GetUsersByIDs(ctx, userIds)
+	if cerr != nil {
+		logger.Error("Failed to get users", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	return &userv2.UsersResponse{Users: toUserProfiles(users)}, nil
+}
+
+func (s *grpcServerV2) FindByEmail(ctx context.Context, req *userv2.FindByEmailRequest) (*userv2.UserProfile, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 FindByEmail request")
+
+	retUser, cerr := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if cerr != nil {
+		logger.Error("Failed to get user by email", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	return toUserProfile(retUser), nil
+}
+
+func (s *grpcServerV2) Search(ctx context.Context, req *userv2.SearchUsersRequest) (*userv2.SearchUsersResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 Search request", zap.String("query", req.Query), zap.Int64("page", req.Page))
+
+	filter := domain.UserFilter{
+		Query:    req.Query,
+		Role:     domain.UserRole(userv2.UserRoleToString(req.Role)),
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	filter.Normalize()
+
+	users, total, cerr := s.userRepo.ListUsers(ctx, &filter)
+	if cerr != nil {
+		logger.Error("Failed to search users", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	return &userv2.SearchUsersResponse{
+		Users:    toUserProfiles(users),
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}, nil
+}
+
+func (s *grpcServerV2) ValidateCredentials(ctx context.Context, req *userv2.ValidateCredentialsRequest) (*userv2.ValidateCredentialsResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received v2 ValidateCredentials request")
+
+	retUser, cerr := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			// The password is still compared, so unknown emails take as long to check as known ones
+			_ = util.ComparePassword(req.Password, s.dummyHash)
+			return &userv2.ValidateCredentialsResponse{Valid: false}, nil
+		}
+
+		logger.Error("Failed to get user by email", zap.Error(cerr))
+		return nil, toStatusError(cerr)
+	}
+
+	if err := util.ComparePassword(req.Password, retUser.Password); err != nil {
+		return &userv2.ValidateCredentialsResponse{Valid: false}, nil
+	}
+
+	return &userv2.ValidateCredentialsResponse{
+		Valid: true,
+		User:  toUserProfile(retUser),
+	}, nil
+}
+
+// VerifyToken checks an access token for the other services, which cannot tell on their own whether
+// it was revoked by a password change or an account deletion
+func (s *grpcServerV2) VerifyToken(ctx context.Context, req *userv2.VerifyTokenRequest) (*userv2.VerifyTokenResponse, error) {
+	logger := zap.L().Named("grpc_server")
+
+	claims, cerr := s.authService.VerifyToken(ctx, req.Token)
+	if cerr != nil {
+		if cerr.Code() != http.StatusUnauthorized {
+			logger.Error("Failed to verify token", zap.Error(cerr))
+		}
+		return nil, toStatusError(cerr)
+	}
+
+	return &userv2.VerifyTokenResponse{UserId: claims.ID}, nil
+}
+
+// toStatusError maps an error of the core to the status of a gRPC call
+func toStatusError(cerr domain.CError) error {
+	switch cerr.Code() {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, cerr.Error())
+	case http.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, cerr.Error())
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, cerr.Error())
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, cerr.Error())
+	case http.StatusConflict:
+		return status.Error(codes.AlreadyExists, cerr.Error())
+	default:
+		return status.Error(codes.Internal, domain.ErrInternal.Error())
+	}
+}
+
+// toUserProfile maps a user to its public v2 profile, leaving out credentials
+func toUserProfile(u *domain.User) *userv2.UserProfile {
+	profile := userv2.UserProfile{
+		Id:        u.ID.Hex(),
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+		Phone:     u.Phone,
+		IsActive:  u.IsActive,
+		CreatedAt: u.CreatedAt.String(),
+		UpdatedAt: u.UpdatedAt.String(),
+	}
+
+	if u.DeletedAt != nil {
+		profile.DeletedAt = u.DeletedAt.String()
+	}
+
+	if role, err := userv2.StringToUserRole(u.Role.String()); err == nil {
+		profile.Role = role
+	}
+
+	return &profile
+}
+
+// toUserProfiles maps a list of users to their public v2 profiles
+func toUserProfiles(users []domain.User) []*userv2.UserProfile {
+	profiles := make([]*userv2.UserProfile, 0, len(users))
+	for i := range users {
+		profiles = append(profiles, toUserProfile(&users[i]))
+	}
+
+	return profiles
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"owner-service/internal/core/domain"
+	userv2 "owner-service/internal/core/service/user/v2"
+	"owner-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusError(t *testing.T) {
+	tests := []struct {
+		cerr     domain.CError
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{cerr: domain.NewBadRequestCError("bad"), wantCode: codes.InvalidArgument, wantMsg: "bad"},
+		{cerr: domain.ErrRevokedToken, wantCode: codes.Unauthenticated, wantMsg: domain.ErrRevokedToken.Error()},
+		{cerr: domain.NewCError(http.StatusForbidden, "forbidden"), wantCode: codes.PermissionDenied, wantMsg: "forbidden"},
+		{cerr: domain.ErrDataNotFound, wantCode: codes.NotFound, wantMsg: domain.ErrDataNotFound.Error()},
+		{cerr: domain.ErrConflictingData, wantCode: codes.AlreadyExists, wantMsg: domain.ErrConflictingData.Error()},
+		{cerr: domain.NewInternalCError("database is down"), wantCode: codes.Internal, wantMsg: domain.ErrInternal.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cerr.Error(), func(t *testing.T) {
+			st := status.Convert(toStatusError(tt.cerr))
+			if st.Code() != tt.wantCode || st.Message() != tt.wantMsg {
+				t.Errorf("toStatusError() = %v %q, want %v %q", st.Code(), st.Message(), tt.wantCode, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestGrpcServerV2VerifyToken(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		tokenErr   domain.CError
+		repoErr    domain.CError
+		wantCode   codes.Code
+		wantUserID string
+	}{
+		{name: "valid token", wantCode: codes.OK, wantUserID: userID.Hex()},
+		{name: "invalid token", tokenErr: domain.ErrInvalidToken, wantCode: codes.Unauthenticated},
+		{name: "users cannot be read", repoErr: domain.ErrInternal, wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepo{users: map[primitive.ObjectID]*domain.User{userID: {ID: userID}}, err: tt.repoErr}
+			tokens := fakeTokens{claims: domain.Claims{ID: userID.Hex()}, err: tt.tokenErr}
+			srv := &grpcServerV2{userRepo: repo, authService: NewAuthService(repo, tokens)}
+
+			resp, err := srv.VerifyToken(context.Background(), &userv2.VerifyTokenRequest{Token: "token"})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("VerifyToken() error = %v, want %v", err, tt.wantCode)
+			}
+			if err == nil && resp.UserId != tt.wantUserID {
+				t.Errorf("VerifyToken() user id = %q, want %q", resp.UserId, tt.wantUserID)
+			}
+		})
+	}
+}
+
+func TestGrpcServerV2ValidateCredentials(t *testing.T) {
+	hash, err := util.HashPassword("correct-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := primitive.NewObjectID()
+	repo := &fakeUserRepo{users: map[primitive.ObjectID]*domain.User{
+		userID: {ID: userID, Email: "ada@example.com", Password: hash},
+	}}
+
+	srv, err := newgrpcServerV2(&Config{}, repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		email     string
+		password  string
+		wantValid bool
+	}{
+		{name: "valid credentials", email: "ada@example.com", password: "correct-password", wantValid: true},
+		{name: "wrong password", email: "ada@example.com", password: "wrong-password"},
+		{name: "unknown email", email: "bob@example.com", password: "correct-password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.ValidateCredentials(context.Background(), &userv2.ValidateCredentialsRequest{Email: tt.email, Password: tt.password})
+			if err != nil {
+				t.Fatalf("ValidateCredentials() failed: %v", err)
+			}
+
+			if resp.Valid != tt.wantValid || (resp.User != nil) != tt.wantValid {
+				t.Fatalf("ValidateCredentials() = %+v, want valid %v with the user only when valid", resp, tt.wantValid)
+			}
+			if resp.Valid && resp.User.Id != userID.Hex() {
+				t.Errorf("ValidateCredentials() user = %q, want %q", resp.User.Id, userID.Hex())
+			}
+		})
+	}
+}
+
+func TestGrpcServerV2GetManyInvalidID(t *testing.T) {
+	srv := &grpcServerV2{userRepo: &fakeUserRepo{}}
+
+	_, err := srv.GetMany(context.Background(), &userv2.GetUsersRequest{UserIds: []string{primitive.NewObjectID().Hex(), "not-an-id"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetMany() error = %v, want %v", err, codes.InvalidArgument)
+	}
+}
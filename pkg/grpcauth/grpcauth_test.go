@@ -0,0 +1,116 @@
+package grpcauth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testClients = map[string]Client{
+	"product-service": {Token: "product-token", Methods: []string{"/user.v2.User/Get", "/product.Product/WatchProducts"}},
+	"order-service":   {Token: "order-token", Methods: []string{"/user.v2.User/GetMany"}},
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "allowed method", md: metadata.Pairs("authorization", "Bearer product-token"), method: "/user.v2.User/Get", wantCode: codes.OK},
+		{name: "method of another service", md: metadata.Pairs("authorization", "Bearer order-token"), method: "/user.v2.User/Get", wantCode: codes.PermissionDenied},
+		{name: "unknown method", md: metadata.Pairs("authorization", "Bearer product-token"), method: "/user.v2.User/Delete", wantCode: codes.PermissionDenied},
+		{name: "wrong token", md: metadata.Pairs("authorization", "Bearer product-token-2"), method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+		{name: "token without the bearer scheme", md: metadata.Pairs("authorization", "product-token"), method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+		{name: "empty token", md: metadata.Pairs("authorization", "Bearer "), method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+		{name: "several tokens", md: metadata.Pairs("authorization", "Bearer product-token", "authorization", "Bearer order-token"), method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+		{name: "no token", md: metadata.MD{}, method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+		{name: "no metadata", method: "/user.v2.User/Get", wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := UnaryServerInterceptor(testClients)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return "response", nil
+			}
+
+			resp, err := interceptor(ctx, "request", &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("interceptor error = %v, want %v", err, tt.wantCode)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+			if err == nil && resp != "response" {
+				t.Errorf("interceptor response = %v, want the response of the handler", resp)
+			}
+		})
+	}
+}
+
+// testStream is a server stream that only carries a context
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "allowed method", token: "product-token", method: "/product.Product/WatchProducts", wantCode: codes.OK},
+		{name: "method of another service", token: "order-token", method: "/product.Product/WatchProducts", wantCode: codes.PermissionDenied},
+		{name: "wrong token", token: "watch-token", method: "/product.Product/WatchProducts", wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := StreamServerInterceptor(testClients)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tt.token))
+
+			called := false
+			handler := func(srv interface{}, stream grpc.ServerStream) error {
+				called = true
+				return nil
+			}
+
+			err := interceptor(nil, testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("interceptor error = %v, want %v", err, tt.wantCode)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCode == codes.OK)
+			}
+		})
+	}
+}
+
+func TestTokenRequestMetadata(t *testing.T) {
+	md, err := Token("product-token").GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata() failed: %v", err)
+	}
+	if md["authorization"] != "Bearer product-token" {
+		t.Errorf("GetRequestMetadata() = %v, want the bearer token", md)
+	}
+}
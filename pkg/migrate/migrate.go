@@ -0,0 +1,336 @@
+// Package migrate applies versioned migrations to a MongoDB database, holding a lock so only
+// one instance of a service migrates it at a time
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	// migrationsCollection records every applied migration
+	migrationsCollection = "schema_migrations"
+	// migrationLockCollection holds the lock taken while migrations run
+	migrationLockCollection = "schema_migrations_lock"
+	migrationLockID         = "migrations"
+	// migrationLockTTL bounds how long a crashed instance can keep others from migrating
+	migrationLockTTL = 5 * time.Minute
+	// migrationLockRenewal is how often a running instance extends its lock, leaving a few
+	// attempts before the lock expires when a renewal fails
+	migrationLockRenewal = migrationLockTTL / 5
+	// migrationLockWait is how long an instance waits for another one to finish migrating
+	migrationLockWait = 2 * time.Minute
+)
+
+// errMigrationLockLost cancels a migration whose lock was taken by another instance
+var errMigrationLockLost = errors.New("the migration lock was lost to another instance")
+
+// Migration is a versioned change to a database. Versions are applied in ascending order
+// and Down must undo exactly what Up did
+type Migration struct {
+	Version     int64
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// appliedMigration represents a row in the "schema_migrations" table
+type appliedMigration struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// migrationLock represents the single row in the "schema_migrations_lock" table
+type migrationLock struct {
+	ID        string    `bson:"_id"`
+	Holder    string    `bson:"holder"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// Migrator applies and rolls back versioned migrations under a lock so only
+// one instance of a service migrates a database at a time
+type Migrator struct {
+	database   *mongo.Database
+	migrations []Migration
+	holder     string
+	logger     *zap.Logger
+}
+
+// New creates a migrator for the database. Migrations are sorted by version and must have
+// unique, positive versions
+func New(database *mongo.Database, migrations []Migration) (*Migrator, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	for i, m := range sorted {
+		if m.Version < 1 {
+			return nil, fmt.Errorf("migration %q has an invalid version %d", m.Description, m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		if m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %d must define both up and down steps", m.Version)
+		}
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &Migrator{
+		database:   database,
+		migrations: sorted,
+		holder:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		logger:     zap.L().Named("migrator"),
+	}, nil
+}
+
+// Up applies every migration that has not been applied yet
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			m.logger.Info("Applying migration", zap.Int64("version", mig.Version), zap.String("description", mig.Description))
+			if err := mig.Up(ctx, m.database); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %w", mig.Version, mig.Description, err)
+			}
+
+			_, err := m.database.Collection(migrationsCollection).InsertOne(ctx, appliedMigration{
+				Version:     mig.Version,
+				Description: mig.Description,
+				AppliedAt:   time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("could not record migration %d: %w", mig.Version, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back every applied migration with a version greater than target, newest first.
+// A target of 0 rolls back all migrations
+func (m *Migrator) Down(ctx context.Context, target int64) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version <= target {
+				break
+			}
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			m.logger.Info("Rolling back migration", zap.Int64("version", mig.Version), zap.String("description", mig.Description))
+			if err := mig.Down(ctx, m.database); err != nil {
+				return fmt.Errorf("rollback of migration %d (%s) failed: %w", mig.Version, mig.Description, err)
+			}
+
+			_, err := m.database.Collection(migrationsCollection).DeleteOne(ctx, bson.M{"_id": mig.Version})
+			if err != nil {
+				return fmt.Errorf("could not remove the record of migration %d: %w", mig.Version, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Version returns the highest applied migration version, or 0 when none has been applied
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var last appliedMigration
+	err := m.database.Collection(migrationsCollection).FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}),
+	).Decode(&last)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return last.Version, nil
+}
+
+// applied returns the set of applied migration versions. A database migrated by a newer
+// build of the service is rejected rather than run against migrations it does not know
+func (m *Migrator) applied(ctx context.Context) (map[int64]struct{}, error) {
+	cursor, err := m.database.Collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	var rows []appliedMigration
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("could not read applied migrations: %w", err)
+	}
+
+	known := make(map[int64]struct{}, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = struct{}{}
+	}
+
+	applied := make(map[int64]struct{}, len(rows))
+	for _, row := range rows {
+		if _, ok := known[row.Version]; !ok {
+			return nil, fmt.Errorf("database has unknown migration %d (%s) applied", row.Version, row.Description)
+		}
+		applied[row.Version] = struct{}{}
+	}
+
+	return applied, nil
+}
+
+// withLock runs fn while holding the migration lock, waiting for another holder to release it.
+// The lock is renewed until fn returns, and fn is cancelled if the lock is lost meanwhile
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	deadline := time.Now().Add(migrationLockWait)
+	for {
+		acquired, err := m.acquireLock(ctx)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the migration lock")
+		}
+
+		m.logger.Info("Waiting for another instance to finish migrating")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		m.renewLock(lockCtx, cancel)
+	}()
+
+	defer func() {
+		_, err := m.database.Collection(migrationLockCollection).DeleteOne(context.Background(), bson.M{"_id": migrationLockID, "holder": m.holder})
+		if err != nil {
+			m.logger.Error("Error releasing the migration lock", zap.Error(err))
+		}
+	}()
+
+	err := fn(lockCtx)
+	if errors.Is(context.Cause(lockCtx), errMigrationLockLost) {
+		err = fmt.Errorf("migration stopped: %w", errMigrationLockLost)
+	}
+
+	cancel(nil)
+	<-renewed
+
+	return err
+}
+
+// renewLock extends the lock until ctx is done. A renewal that fails is tried again at the next
+// tick, while a lock that is no longer held cancels ctx
+func (m *Migrator) renewLock(ctx context.Context, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(migrationLockRenewal)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := m.database.Collection(migrationLockCollection).UpdateOne(ctx,
+			bson.M{"_id": migrationLockID, "holder": m.holder},
+			bson.M{"$set": bson.M{"expires_at": time.Now().Add(migrationLockTTL)}},
+		)
+		if err != nil {
+			if ctx.Err() == nil {
+				m.logger.Error("Error renewing the migration lock", zap.Error(err))
+			}
+			continue
+		}
+
+		if result.MatchedCount == 0 {
+			m.logger.Error("The migration lock was taken by another instance")
+			cancel(errMigrationLockLost)
+			return
+		}
+	}
+}
+
+// acquireLock takes the lock if nobody holds it or the previous holder's lock has expired
+func (m *Migrator) acquireLock(ctx context.Context) (bool, error) {
+	now := time.Now()
+	lock := migrationLock{ID: migrationLockID, Holder: m.holder, ExpiresAt: now.Add(migrationLockTTL)}
+
+	collection := m.database.Collection(migrationLockCollection)
+	_, err := collection.InsertOne(ctx, lock)
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("could not acquire the migration lock: %w", err)
+	}
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": migrationLockID, "expires_at": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"holder": lock.Holder, "expires_at": lock.ExpiresAt}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("could not acquire the migration lock: %w", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// CreateIndexes returns a migration step that creates the indexes on a collection
+func CreateIndexes(collection string, models []mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, models)
+		return err
+	}
+}
+
+// DropIndexes returns a migration step that drops the named indexes of a collection
+func DropIndexes(collection string, names ...string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, name := range names {
+			_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+			var cmdErr mongo.CommandError
+			if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound") {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package migrate
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNew(t *testing.T) {
+	step := func(ctx context.Context, db *mongo.Database) error { return nil }
+
+	tests := []struct {
+		name         string
+		migrations   []Migration
+		wantVersions []int64
+		wantErr      string
+	}{
+		{
+			name: "sorted by version",
+			migrations: []Migration{
+				{Version: 3, Up: step, Down: step},
+				{Version: 1, Up: step, Down: step},
+				{Version: 2, Up: step, Down: step},
+			},
+			wantVersions: []int64{1, 2, 3},
+		},
+		{
+			name:         "no migrations",
+			wantVersions: []int64{},
+		},
+		{
+			name:       "version below one",
+			migrations: []Migration{{Version: 0, Description: "zero", Up: step, Down: step}},
+			wantErr:    `migration "zero" has an invalid version 0`,
+		},
+		{
+			name: "duplicate version",
+			migrations: []Migration{
+				{Version: 2, Up: step, Down: step},
+				{Version: 2, Up: step, Down: step},
+			},
+			wantErr: "duplicate migration version 2",
+		},
+		{
+			name:       "missing down step",
+			migrations: []Migration{{Version: 1, Up: step}},
+			wantErr:    "migration 1 must define both up and down steps",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator, err := New(nil, tt.migrations)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			versions := make([]int64, 0, len(migrator.migrations))
+			for _, m := range migrator.migrations {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("New() versions = %v, want %v", versions, tt.wantVersions)
+			}
+		})
+	}
+}
@@ -13,9 +13,11 @@ import (
 	"product-service/internal/adapter/config"
 	httpLib "product-service/internal/adapter/handler/http"
 	"product-service/internal/adapter/logger"
+	"product-service/internal/adapter/storage/media"
 	"product-service/internal/adapter/storage/mongodb"
 	"product-service/internal/adapter/storage/mongodb/repository"
 	"product-service/internal/adapter/storage/redis"
+	"product-service/internal/core/port"
 	"product-service/internal/core/service"
 
 	"github.com/go-playground/validator/v10"
@@ -62,18 +64,31 @@ func main() {
 
 	l.Info("Successfully connected to the database")
 
+	// Run Migrations
+	err = db.RunMigrations(ctx, &config.Database)
+	if err != nil {
+		l.Error("Error running database migrations", zap.Error(err))
+		os.Exit(1)
+	}
+	l.Info("Successfully run database migrations")
+
 	// Init cache service
 	cache, err := redis.New(ctx, &config.Redis)
 	if err != nil {
 		l.Error("Error initializing cache connection", zap.Error(err))
-		// os.Exit(1) // Cache is not being used at the moment
+		os.Exit(1)
 	}
 	defer cache.Close()
 
 	l.Info("Successfully connected to the cache server")
 
-	// Init token service
-	tokenService := jwt.New(&config.Token)
+	// Init token service. Tokens are checked with the owner-service as well, which knows the revoked ones
+	tokenService, err := service.NewTokenService(jwt.New(&config.Token), &config.Discovery)
+	if err != nil {
+		l.Error("Error initializing token service", zap.Error(err))
+		os.Exit(1)
+	}
+	defer tokenService.Close()
 
 	// Message Queue Producer
 	producer, err := rabbitmq.New(ctx, &config.Rabbitmq)
@@ -84,24 +99,68 @@ func main() {
 
 	l.Info("Successfully connected to the message broker and created producer")
 
+	// Init media store
+	mediaStore, err := media.New(&config.Media)
+	if err != nil {
+		l.Error("Error initializing media store", zap.Error(err))
+		os.Exit(1)
+	}
+
+	l.Info("Successfully initialized the media store", zap.String("driver", config.Media.Driver))
+
 	// Dependency injection
 	// Ping
 	pingRepo := repository.NewPingRepository(db)
 	pingService := service.NewPingService(pingRepo, cache)
 	pingHandler := httpLib.NewPingHandler(pingService, validator.New())
 
+	// Product changes are followed through change streams, or through a change log written by the
+	// product repository when the database has none
+	mongoProductRepo := repository.NewProductRepository(db)
+	var productFeed port.ProductFeedRepository
+	if db.SupportsChangeStreams(ctx) {
+		productFeed = repository.NewProductChangeStream(db)
+	} else {
+		l.Info("Change streams are not available, recording product changes in a change log")
+		changeLog := repository.NewProductChangeLog(db)
+		mongoProductRepo.WithChangeLog(changeLog)
+		productFeed = changeLog
+	}
+
 	// Product
-	productRepo := repository.NewProductRepository(db)
-	productService := service.NewProductService(productRepo, cache, producer)
+	productRepo := service.NewCachedProductRepository(mongoProductRepo, cache)
+	categoryRepo := repository.NewCategoryRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	revisionRepo := repository.NewRevisionRepository(db)
+	priceRepo := repository.NewPriceRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	productService := service.NewProductService(productRepo, categoryRepo, inventoryRepo, importJobRepo, revisionRepo, priceRepo, reviewRepo, cache, producer, mediaStore)
 	productHandler := httpLib.NewProductHandler(productService, validator.New())
 
+	// Inventory
+	inventoryHandler := httpLib.NewInventoryHandler(productService, validator.New())
+
+	// Category
+	categoryService := service.NewCategoryService(categoryRepo, productRepo)
+	categoryHandler := httpLib.NewCategoryHandler(categoryService, validator.New())
+
+	// Wishlist
+	wishlistRepo := repository.NewWishlistRepository(db)
+	wishlistService := service.NewWishlistService(wishlistRepo, productRepo)
+	wishlistHandler := httpLib.NewWishlistHandler(wishlistService, validator.New())
+
 	// Init router
 	router, err := httpLib.NewRouter(
 		&config.Server,
+		&config.Media,
 		tokenService,
 		l,
 		*pingHandler,
 		*productHandler,
+		*categoryHandler,
+		*inventoryHandler,
+		*wishlistHandler,
 	)
 	if err != nil {
 		l.Error("Error initializing router ", zap.Error(err))
@@ -118,16 +177,20 @@ func main() {
 	l.Info("Successfully connected to the message broker and created consumer")
 
 	// Start consumer
-	queue := "user-updates"
+	queue := "user-updates.v2"
 	l.Info("Starting consumer on", zap.String("queue", queue))
 	go consumer1.Consume(ctx, queue, productService.UpdateProductsFromQueue)
 
+	// Start price scheduler
+	l.Info("Starting the price scheduler")
+	go productService.RunPriceScheduler(ctx)
+
 	// Init GRPC server
 	grpcListAddr := fmt.Sprintf("%s:%s", config.Server.GrpcUrl, config.Server.GrpcPort)
 	list, err := net.Listen("tcp", grpcListAddr)
 	l.Info("Starting the GRPC server", zap.String("listen_address", list.Addr().String()))
 
-	server, err := service.NewGRPCServer(&service.Config{}, productRepo, grpc.EmptyServerOption{})
+	server, err := service.NewGRPCServer(&service.Config{Clients: config.Grpc.Clients}, productRepo, productService, productFeed, grpc.EmptyServerOption{})
 	go func() {
 		l.Error("Error starting grpc server", zap.Error(server.Serve(list)))
 	}()
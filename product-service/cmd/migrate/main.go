@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
+	"product-service/internal/adapter/storage/mongodb"
+
+	"go.uber.org/zap"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down <version>  roll back to the given version, 0 rolls back everything
+  version         print the latest applied version`
+
+// The HTTP server applies pending migrations on startup. This command is
+// used to inspect the database version and to roll migrations back
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	config := config.Setup()
+
+	// Set logger
+	l := logger.Get()
+	zap.ReplaceGlobals(l)
+
+	ctx := context.Background()
+	db, err := mongodb.New(ctx, &config.Database)
+	if err != nil {
+		l.Error("Error initializing database connection", zap.Error(err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	switch os.Args[1] {
+	case "up":
+		err = db.RunMigrations(ctx, &config.Database)
+	case "down":
+		if len(os.Args) != 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+
+		var target int64
+		target, err = strconv.ParseInt(os.Args[2], 10, 64)
+		if err != nil || target < 0 {
+			fmt.Println("version must be a non-negative integer")
+			os.Exit(2)
+		}
+		err = db.RollbackMigrations(ctx, &config.Database, target)
+	case "version":
+		var version int64
+		version, err = db.MigrationVersion(ctx, &config.Database)
+		if err == nil {
+			fmt.Println(version)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		l.Error("Error running database migrations", zap.Error(err))
+		os.Exit(1)
+	}
+}
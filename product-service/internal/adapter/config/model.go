@@ -1,5 +1,7 @@
 package config
 
+import "pkg/grpcauth"
+
 type RedisConfiguration struct {
 	Address  string
 	Password string
@@ -36,6 +38,11 @@ type TokenConfiguration struct {
 
 type DiscoveryConfiguration struct {
 	OwnerUrl string
+	OrderUrl string
+	// OwnerToken authenticates the service with the gRPC server of the owner-service
+	OwnerToken string
+	// OrderToken authenticates the service with the gRPC server of the order-service
+	OrderToken string
 }
 
 type RabbitMqConfiguration struct {
@@ -44,6 +51,40 @@ type RabbitMqConfiguration struct {
 	Host     string
 }
 
+type MediaConfiguration struct {
+	// Driver selects the media store, either "local" or "s3"
+	Driver        string
+	MaxUploadSize int64
+	ThumbnailSize int
+	Local         LocalMediaConfiguration
+	S3            S3MediaConfiguration
+}
+
+type LocalMediaConfiguration struct {
+	Directory string
+	BaseUrl   string
+}
+
+type S3MediaConfiguration struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicUrl is the base url files are served from, defaulting to the bucket url
+	PublicUrl string
+}
+
+type SchedulerConfiguration struct {
+	// PriceInterval is how often due price schedules are applied
+	PriceInterval string
+}
+
+type GrpcConfiguration struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
+}
+
 type Configuration struct {
 	App       AppConfiguration
 	Server    ServerConfiguration
@@ -51,5 +92,8 @@ type Configuration struct {
 	Redis     RedisConfiguration
 	Token     TokenConfiguration
 	Discovery DiscoveryConfiguration
+	Grpc      GrpcConfiguration
 	Rabbitmq  RabbitMqConfiguration
+	Media     MediaConfiguration
+	Scheduler SchedulerConfiguration
 }
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// CategoryHandler represents the HTTP handler for category-related requests
+type CategoryHandler struct {
+	svc      port.CategoryService
+	validate *validator.Validate
+}
+
+// NewCategoryHandler creates a new CategoryHandler instance
+func NewCategoryHandler(svc port.CategoryService, vld *validator.Validate) *CategoryHandler {
+	return &CategoryHandler{
+		svc,
+		vld,
+	}
+}
+
+// CreateCategory godoc
+//
+//	@Summary		Create a new category
+//	@Description	create a category at the root or under a parent category
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.CreateCategoryRequest	body		domain.CreateCategoryRequest	true	"Category"
+//	@Success		201								{object}	response						"Category created successfully"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		409								{object}	errorResponse					"Conflict error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/category [post]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.CreateCategory(r.Context(), &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Category created successfully")
+}
+
+// GetCategory godoc
+//
+//	@Summary		Get a category by id
+//	@Description	fetch a category with its breadcrumbs
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Category id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/category/{id} [get]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid category id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.GetCategory(r.Context(), id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// ListCategories godoc
+//
+//	@Summary		List all categories
+//	@Description	list the whole category tree ordered by path, so every parent comes before its children
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	response		"Success"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/categories [get]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	result, cerr := ch.svc.ListCategories(r.Context())
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// RenameCategory godoc
+//
+//	@Summary		Rename a category
+//	@Description	change the name of a category
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		string							true	"Category id"
+//	@Param			domain.RenameCategoryRequest	body		domain.RenameCategoryRequest	true	"Name"
+//	@Success		200								{object}	response						"Success"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		404								{object}	errorResponse					"Data not found error"
+//	@Failure		409								{object}	errorResponse					"Conflict error"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/category/{id} [patch]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) RenameCategory(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid category id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.RenameCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.RenameCategory(r.Context(), id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// MoveCategory godoc
+//
+//	@Summary		Move a category
+//	@Description	move a category and all of its descendants under a new parent. An empty parent_id moves it to the root
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Category id"
+//	@Param			domain.MoveCategoryRequest	body		domain.MoveCategoryRequest	true	"New parent"
+//	@Success		200							{object}	response					"Success"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		404							{object}	errorResponse				"Data not found error"
+//	@Failure		409							{object}	errorResponse				"Conflict error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/category/{id}/move [post]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid category id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.MoveCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	result, cerr := ch.svc.MoveCategory(r.Context(), id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// DeleteCategory godoc
+//
+//	@Summary		Delete a category
+//	@Description	delete a category. Its children and products are moved up to its parent
+//	@Tags			Category
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Category id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		409	{object}	errorResponse	"Conflict error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/category/{id} [delete]
+//	@Security		BearerAuth
+func (ch *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid category id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if cerr := ch.svc.DeleteCategory(r.Context(), id); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted category successfully")
+}
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// InventoryHandler represents the HTTP handler for stock-related requests
+type InventoryHandler struct {
+	svc      port.InventoryService
+	validate *validator.Validate
+}
+
+// NewInventoryHandler creates a new InventoryHandler instance
+func NewInventoryHandler(svc port.InventoryService, vld *validator.Validate) *InventoryHandler {
+	return &InventoryHandler{
+		svc,
+		vld,
+	}
+}
+
+// AdjustStock godoc
+//
+//	@Summary		Adjust the stock of a product
+//	@Description	change the stock of a product, or of one of its variants, and record the movement in the inventory ledger. An adjustment or a return changes the stock by delta, a stock_count sets it to quantity
+//	@Tags			Inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Product id"
+//	@Param			domain.AdjustStockRequest	body		domain.AdjustStockRequest	true	"Stock adjustment"
+//	@Success		200							{object}	response					"Success"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		404							{object}	errorResponse				"Not found error"
+//	@Failure		409							{object}	errorResponse				"Not enough stock"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/product/{id}/stock [post]
+//	@Security		BearerAuth
+func (ih *InventoryHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.AdjustStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := ih.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ih.svc.AdjustStock(r.Context(), id, userID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Stock adjusted successfully")
+}
+
+// AdjustOwnStock godoc
+//
+//	@Summary		Adjust the stock of an own product
+//	@Description	change the stock of a product owned by the authenticated user, or of one of its variants, and record the movement in the inventory ledger. Products managed by an organization are not changed here
+//	@Tags			Inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Product id"
+//	@Param			domain.AdjustStockRequest	body		domain.AdjustStockRequest	true	"Stock adjustment"
+//	@Success		200							{object}	response					"Success"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		403							{object}	errorResponse				"Forbidden error"
+//	@Failure		404							{object}	errorResponse				"Not found error"
+//	@Failure		409							{object}	errorResponse				"Not enough stock"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/me/products/{id}/stock [post]
+//	@Security		BearerAuth
+func (ih *InventoryHandler) AdjustOwnStock(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.AdjustStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := ih.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ih.svc.AdjustOwnStock(r.Context(), userID, id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Stock adjusted successfully")
+}
+
+// ListMovements godoc
+//
+//	@Summary		List the stock movements of a product
+//	@Description	list the inventory ledger of a product from the newest movement, and check the stock of the product against it
+//	@Tags			Inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			before	query		string			false	"Only list movements older than this movement id"
+//	@Param			limit	query		int				false	"Page size, at most 100"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/movements [get]
+//	@Security		BearerAuth
+func (ih *InventoryHandler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var before *primitive.ObjectID
+	if v := q.Get("before"); v != "" {
+		beforeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid before movement id"))
+			return
+		}
+		before = &beforeID
+	}
+
+	var limit int64
+	if v := q.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	result, cerr := ih.svc.ListMovements(r.Context(), id, before, limit)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// SubscribeToStock godoc
+//
+//	@Summary		Subscribe to a back-in-stock alert
+//	@Description	subscribe the authenticated user to be alerted when an out of stock product, or one of its variants, is back in stock
+//	@Tags			Inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Product id"
+//	@Param			domain.SubscribeRequest	body		domain.SubscribeRequest	false	"Variant to subscribe to"
+//	@Success		201						{object}	response				"Subscribed successfully"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		404						{object}	errorResponse			"Not found error"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/product/{id}/subscription [post]
+//	@Security		BearerAuth
+func (ih *InventoryHandler) SubscribeToStock(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.SubscribeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+			handleError(w, domain.NewBadRequestCError("Invalid request body"))
+			return
+		}
+	}
+
+	if err := ih.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	email := r.Context().Value(authContextKey).(contextInfo).Email
+	result, cerr := ih.svc.SubscribeToStock(r.Context(), id, userID, email, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Subscribed successfully")
+}
+
+// UnsubscribeFromStock godoc
+//
+//	@Summary		Unsubscribe from a back-in-stock alert
+//	@Description	remove the back-in-stock subscription of the authenticated user to a product, or to one of its variants
+//	@Tags			Inventory
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Product id"
+//	@Param			variant_id	query		string			false	"Variant id"
+//	@Success		200			{object}	response		"Success"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/subscription [delete]
+//	@Security		BearerAuth
+func (ih *InventoryHandler) UnsubscribeFromStock(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	req := domain.SubscribeRequest{VariantID: r.URL.Query().Get("variant_id")}
+	if err := ih.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	if cerr := ih.svc.UnsubscribeFromStock(r.Context(), id, userID, &req); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Unsubscribed successfully")
+}
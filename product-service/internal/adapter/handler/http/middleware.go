@@ -51,10 +51,18 @@ func authMiddleware(next http.Handler, token port.TokenService, logger *zap.Logg
 			return
 		}
 
+		// claims.Email is of the form <email,role>
+		identifier := strings.Split(claims.Email, ",")
+		if len(identifier) != 2 {
+			handleError(w, domain.ErrInvalidToken)
+			return
+		}
+
 		// Set details from token in context
 		ctx := context.WithValue(r.Context(), authContextKey, contextInfo{
 			ID:    claims.ID,
-			Email: claims.Email,
+			Role:  identifier[1],
+			Email: identifier[0],
 		})
 
 		// call the next handler in the chain, passing the response writer and
@@ -145,11 +153,13 @@ func adminMiddleware(next http.Handler, token port.TokenService, logger *zap.Log
 		email, role := identifier[0], identifier[len(identifier)-1]
 		if role != domain.RAdmin {
 			handleError(w, domain.ErrUnauthorized)
+			return
 		}
 
 		// Set details from token in context
 		ctx := context.WithValue(r.Context(), authContextKey, contextInfo{
 			ID:    claims.ID,
+			Role:  role,
 			Email: email,
 		})
 
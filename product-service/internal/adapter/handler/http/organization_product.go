@@ -0,0 +1,229 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// currentUserID returns the id of the authenticated user
+func currentUserID(r *http.Request) (primitive.ObjectID, domain.CError) {
+	ctxInfo := r.Context().Value(authContextKey).(contextInfo)
+	id, err := primitive.ObjectIDFromHex(ctxInfo.ID)
+	if err != nil {
+		logger.FromCtx(r.Context()).Error("Error parsing user id", zap.String("user_id", ctxInfo.ID), zap.Error(err))
+		return primitive.NilObjectID, domain.ErrInternal
+	}
+	return id, nil
+}
+
+// objectIDParam parses an object id from the url
+func objectIDParam(r *http.Request, name, errMsg string) (primitive.ObjectID, domain.CError) {
+	id, err := primitive.ObjectIDFromHex(chi.URLParam(r, name))
+	if err != nil {
+		return primitive.NilObjectID, domain.NewBadRequestCError(errMsg)
+	}
+	return id, nil
+}
+
+// CreateOrganizationProduct godoc
+//
+//	@Summary		Create a product for an organization
+//	@Description	create a product managed by an organization. Requires the products:write permission in the organization
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			org_id						path		string						true	"Organization id"
+//	@Param			domain.CreateProductRequest	body		domain.CreateProductRequest	true	"Product"
+//	@Success		201							{object}	response					"Product created successfully"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		403							{object}	errorResponse				"Forbidden error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/organization/{org_id}/product [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) CreateOrganizationProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "org_id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.CreateOrganizationProduct(r.Context(), orgID, userID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Product created successfully")
+}
+
+// ListOrganizationProducts godoc
+//
+//	@Summary		List the products of an organization
+//	@Description	list the products managed by an organization the authenticated user is a member of
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			org_id	path		string			true	"Organization id"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/organization/{org_id}/products [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ListOrganizationProducts(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "org_id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.ListOrganizationProducts(r.Context(), orgID, userID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// UpdateOrganizationProduct godoc
+//
+//	@Summary		Update a product of an organization
+//	@Description	update a product managed by an organization. Requires the products:write permission in the organization, and the ETag the product was fetched with in the If-Match header
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			org_id						path		string						true	"Organization id"
+//	@Param			id							path		string						true	"Product id"
+//	@Param			If-Match					header		string						true	"ETag of the product"
+//	@Param			domain.UpdateProductRequest	body		domain.UpdateProductRequest	true	"Product"
+//	@Success		200							{object}	response					"Success"
+//	@Header			200							{string}	ETag						"New version of the product"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		403							{object}	errorResponse				"Forbidden error"
+//	@Failure		404							{object}	errorResponse				"Data not found error"
+//	@Failure		412							{object}	errorResponse				"The product has been changed"
+//	@Failure		428							{object}	errorResponse				"If-Match header missing"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/organization/{org_id}/product/{id} [patch]
+//	@Security		BearerAuth
+func (ch *ProductHandler) UpdateOrganizationProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "org_id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	version, cerr := ifMatchVersion(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.UpdateOrganizationProduct(r.Context(), orgID, userID, id, version, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	setETag(w, result)
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// DeleteOrganizationProduct godoc
+//
+//	@Summary		Delete a product of an organization
+//	@Description	delete a product managed by an organization. Requires the products:delete permission in the organization
+//	@Tags			Organization
+//	@Accept			json
+//	@Produce		json
+//	@Param			org_id	path		string			true	"Organization id"
+//	@Param			id		path		string			true	"Product id"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		403		{object}	errorResponse	"Forbidden error"
+//	@Failure		404		{object}	errorResponse	"Data not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/organization/{org_id}/product/{id} [delete]
+//	@Security		BearerAuth
+func (ch *ProductHandler) DeleteOrganizationProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	orgID, cerr := objectIDParam(r, "org_id", "Invalid organization id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if cerr := ch.svc.DeleteOrganizationProduct(r.Context(), orgID, userID, id); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted product successfully")
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.uber.org/zap"
+)
+
+// CreateOwnProduct godoc
+//
+//	@Summary		Create a product
+//	@Description	create a product owned by the authenticated user
+//	@Tags			Own product
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.CreateProductRequest	body		domain.CreateProductRequest	true	"Product"
+//	@Success		201							{object}	response					"Product created successfully"
+//	@Header			201							{string}	ETag						"Version of the product"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/me/products [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) CreateOwnProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.CreateProduct(r.Context(), &req, userID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	setETag(w, result)
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Product created successfully")
+}
+
+// ListOwnProducts godoc
+//
+//	@Summary		List own products
+//	@Description	search the products owned by the authenticated user with the filters of the product list
+//	@Tags			Own product
+//	@Accept			json
+//	@Produce		json
+//	@Param			q			query		string			false	"Text search over name and description"
+//	@Param			min_price	query		number			false	"Minimum price"
+//	@Param			max_price	query		number			false	"Maximum price"
+//	@Param			status		query		string			false	"Filter by status"	Enums(active, inactive, out_of_stock)
+//	@Param			category_id	query		string			false	"Filter by category, including its descendants"
+//	@Param			in_stock	query		bool			false	"Filter by stock availability"
+//	@Param			sort_by		query		string			false	"Sort field"	Enums(price, name, created_at)
+//	@Param			sort_order	query		string			false	"Sort order"	Enums(asc, desc)
+//	@Param			limit		query		int				false	"Page size, at most 100"
+//	@Param			cursor		query		string			false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200			{object}	response		"Success"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/me/products [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ListOwnProducts(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	filter, cerr := parseProductFilter(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if filter.IncludeDeleted {
+		handleError(w, domain.NewBadRequestCError("include_deleted is not supported for own products"))
+		return
+	}
+	filter.OwnerID = &userID
+
+	result, cerr := ch.svc.ListProducts(r.Context(), filter)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// GetOwnProduct godoc
+//
+//	@Summary		Get an own product
+//	@Description	fetch a product owned by the authenticated user
+//	@Tags			Own product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Product id"
+//	@Success		200	{object}	response		"Success"
+//	@Header			200	{string}	ETag			"Version of the product, sent back as If-Match to update it"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/me/products/{id} [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) GetOwnProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.GetOwnProduct(r.Context(), userID, id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	setETag(w, result)
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// UpdateOwnProduct godoc
+//
+//	@Summary		Update an own product
+//	@Description	update a product owned by the authenticated user. Products managed by an organization are updated through the organization. The If-Match header must hold the ETag the product was fetched with
+//	@Tags			Own product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Product id"
+//	@Param			If-Match					header		string						true	"ETag of the product"
+//	@Param			domain.UpdateProductRequest	body		domain.UpdateProductRequest	true	"Product"
+//	@Success		200							{object}	response					"Success"
+//	@Header			200							{string}	ETag						"New version of the product"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		403							{object}	errorResponse				"Forbidden error"
+//	@Failure		404							{object}	errorResponse				"Data not found error"
+//	@Failure		412							{object}	errorResponse				"The product has been changed"
+//	@Failure		428							{object}	errorResponse				"If-Match header missing"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/me/products/{id} [patch]
+//	@Security		BearerAuth
+func (ch *ProductHandler) UpdateOwnProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	version, cerr := ifMatchVersion(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.UpdateOwnProduct(r.Context(), userID, id, version, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	setETag(w, result)
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// DeleteOwnProduct godoc
+//
+//	@Summary		Delete an own product
+//	@Description	delete a product owned by the authenticated user. Products managed by an organization are deleted through the organization
+//	@Tags			Own product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Product id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		403	{object}	errorResponse	"Forbidden error"
+//	@Failure		404	{object}	errorResponse	"Data not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/me/products/{id} [delete]
+//	@Security		BearerAuth
+func (ch *ProductHandler) DeleteOwnProduct(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if cerr := ch.svc.DeleteOwnProduct(r.Context(), userID, id); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted product successfully")
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"product-service/internal/adapter/logger"
 	"product-service/internal/core/domain"
@@ -37,6 +39,7 @@ func NewProductHandler(svc port.ProductService, vld *validator.Validate) *Produc
 //	@Produce		json
 //	@Param			domain.CreateProductRequest	body		domain.CreateProductRequest	true	"Product"
 //	@Success		201							{object}	response					"Product created successfully"
+//	@Header			201							{string}	ETag						"Version of the product"
 //	@Failure		400							{object}	errorResponse				"Validation error"
 //	@Failure		500							{object}	errorResponse				"Internal server error"
 //	@Router			/product [post]
@@ -68,6 +71,7 @@ func (ch *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	setETag(w, result)
 	handleSuccessWithMessage(w, http.StatusCreated, result, "Product created successfully")
 }
 
@@ -80,6 +84,7 @@ func (ch *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request)
 //	@Produce		json
 //	@Param			id	path		string			true	"Product id"
 //	@Success		200	{object}	response		"Success"
+//	@Header			200	{string}	ETag			"Version of the product, sent back as If-Match to update it"
 //	@Failure		400	{object}	errorResponse	"Validation error"
 //	@Failure		500	{object}	errorResponse	"Internal server error"
 //	@Router			/product/{id} [get]
@@ -99,22 +104,52 @@ func (ch *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, result)
 	handleSuccess(w, http.StatusOK, result)
 }
 
 // ListProducts godoc
 //
-//	@Summary		List all products
-//	@Description	list all active products
+//	@Summary		List products
+//	@Description	list a page of products matching the search and filters. Deleted products are only listed for admins that ask for them.
+//	@Description	Products can be filtered by attribute with attr.<name>=<value>, repeated to accept any of several values of an attribute.
+//	@Description	The first page also returns the facet counts of the tags and attribute values of the matching products
 //	@Tags			Product
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	response		"Success"
-//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Param			q				query		string			false	"Text search over name and description"
+//	@Param			min_price		query		number			false	"Minimum price"
+//	@Param			max_price		query		number			false	"Maximum price"
+//	@Param			status			query		string			false	"Filter by status"	Enums(active, inactive, out_of_stock)
+//	@Param			owner_id		query		string			false	"Filter by owner id"
+//	@Param			category_id		query		string			false	"Filter by category, including its descendants"
+//	@Param			in_stock		query		bool			false	"Filter by stock availability"
+//	@Param			tag				query		[]string		false	"Only list products with all of the tags"	collectionFormat(multi)
+//	@Param			include_deleted	query		bool			false	"Include deleted products (admin only)"
+//	@Param			sort_by			query		string			false	"Sort field"	Enums(price, name, created_at)
+//	@Param			sort_order		query		string			false	"Sort order"	Enums(asc, desc)
+//	@Param			limit			query		int				false	"Page size, at most 100"
+//	@Param			cursor			query		string			false	"Cursor returned as next_cursor by the previous page"
+//	@Success		200				{object}	response		"Success"
+//	@Failure		400				{object}	errorResponse	"Validation error"
+//	@Failure		403				{object}	errorResponse	"Forbidden error"
+//	@Failure		500				{object}	errorResponse	"Internal server error"
 //	@Router			/products [get]
 //	@Security		BearerAuth
 func (ch *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	result, cerr := ch.svc.ListProducts(r.Context())
+	filter, cerr := parseProductFilter(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	ctxInfo := r.Context().Value(authContextKey).(contextInfo)
+	if filter.IncludeDeleted && ctxInfo.Role != domain.RAdmin {
+		handleError(w, domain.ErrForbidden)
+		return
+	}
+
+	result, cerr := ch.svc.ListProducts(r.Context(), filter)
 	if cerr != nil {
 		handleError(w, cerr)
 		return
@@ -126,14 +161,18 @@ func (ch *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 // UpdateProduct godoc
 //
 //	@Summary		Update a product
-//	@Description	update a product
+//	@Description	update a product. The If-Match header must hold the ETag the product was fetched with, so changes made in the meantime are not overwritten
 //	@Tags			Product
 //	@Accept			json
 //	@Produce		json
 //	@Param			id							path		string						true	"Product id"
+//	@Param			If-Match					header		string						true	"ETag of the product"
 //	@Param			domain.UpdateProductRequest	body		domain.UpdateProductRequest	true	"Product"
 //	@Success		200							{object}	response					"Success"
+//	@Header			200							{string}	ETag						"New version of the product"
 //	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		412							{object}	errorResponse				"The product has been changed"
+//	@Failure		428							{object}	errorResponse				"If-Match header missing"
 //	@Failure		500							{object}	errorResponse				"Internal server error"
 //	@Router			/product/{id} [patch]
 //	@Security		BearerAuth
@@ -146,6 +185,18 @@ func (ch *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	version, cerr := ifMatchVersion(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
 	var req domain.UpdateProductRequest
 	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
 		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
@@ -158,12 +209,13 @@ func (ch *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	result, cerr := ch.svc.UpdateProduct(r.Context(), id, &req)
+	result, cerr := ch.svc.UpdateProduct(r.Context(), id, userID, version, &req)
 	if cerr != nil {
 		handleError(w, cerr)
 		return
 	}
 
+	setETag(w, result)
 	handleSuccess(w, http.StatusOK, result)
 }
 
@@ -198,3 +250,152 @@ func (ch *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request)
 
 	handleSuccessWithMessage(w, http.StatusOK, nil, "Deleted product successfully")
 }
+
+// setETag exposes the version of a product as its ETag
+func setETag(w http.ResponseWriter, prod *domain.Product) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(prod.Version, 10)))
+}
+
+// ifMatchVersion reads the version an update is based on from the If-Match header. It is nil for
+// If-Match: *, which matches any version. An ETag that is not one of ours matches no version
+func ifMatchVersion(r *http.Request) (*int64, domain.CError) {
+	etag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if etag == "" {
+		return nil, domain.NewCError(http.StatusPreconditionRequired, "the If-Match header with the ETag of the product is required")
+	}
+
+	if etag == "*" {
+		return nil, nil
+	}
+
+	unquoted, err := strconv.Unquote(etag)
+	if err != nil || !strings.HasPrefix(etag, `"`) {
+		return nil, domain.ErrVersionConflict
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, domain.ErrVersionConflict
+	}
+
+	return &version, nil
+}
+
+// parseProductFilter reads the product listing options from the query string
+func parseProductFilter(r *http.Request) (*domain.ProductFilter, domain.CError) {
+	q := r.URL.Query()
+	filter := domain.ProductFilter{
+		Query:     strings.TrimSpace(q.Get("q")),
+		SortBy:    q.Get("sort_by"),
+		SortOrder: q.Get("sort_order"),
+	}
+
+	if v := q.Get("min_price"); v != "" {
+		minPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil || minPrice < 0 {
+			return nil, domain.NewBadRequestCError("min_price must be a non-negative number")
+		}
+		filter.MinPrice = &minPrice
+	}
+
+	if v := q.Get("max_price"); v != "" {
+		maxPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil || maxPrice < 0 {
+			return nil, domain.NewBadRequestCError("max_price must be a non-negative number")
+		}
+		filter.MaxPrice = &maxPrice
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, domain.NewBadRequestCError("min_price must not be greater than max_price")
+	}
+
+	if v := q.Get("status"); v != "" {
+		status, ok := domain.StringToProductStatus[v]
+		if !ok {
+			return nil, domain.NewBadRequestCError("invalid status specified: " + v)
+		}
+		filter.Status = status
+	}
+
+	if v := q.Get("owner_id"); v != "" {
+		ownerID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("Invalid owner id")
+		}
+		filter.OwnerID = &ownerID
+	}
+
+	if v := q.Get("category_id"); v != "" {
+		categoryID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("Invalid category id")
+		}
+		filter.CategoryID = &categoryID
+	}
+
+	if v := q.Get("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("in_stock must be a boolean")
+		}
+		filter.InStock = &inStock
+	}
+
+	if v := q.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("include_deleted must be a boolean")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 1 {
+			return nil, domain.NewBadRequestCError("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	for _, v := range q["tag"] {
+		if tag := strings.ToLower(strings.TrimSpace(v)); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	// Attribute filters are given as attr.<name>=<value>, repeated to accept any of several values
+	for key, values := range q {
+		name, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, domain.NewBadRequestCError("attribute filters need an attribute name")
+		}
+
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string][]string)
+		}
+		for _, v := range values {
+			if value := strings.TrimSpace(v); value != "" {
+				filter.Attributes[name] = append(filter.Attributes[name], value)
+			}
+		}
+		if len(filter.Attributes[name]) == 0 {
+			return nil, domain.NewBadRequestCError("attribute filter without a value: " + name)
+		}
+	}
+
+	if v := q.Get("cursor"); v != "" {
+		after, err := domain.DecodeProductCursor(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("invalid cursor")
+		}
+		filter.After = after
+	}
+
+	return &filter, nil
+}
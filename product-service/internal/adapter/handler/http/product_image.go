@@ -0,0 +1,167 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.uber.org/zap"
+)
+
+// multipartOverhead is allowed on top of the image size limit for the rest of the multipart body
+const multipartOverhead int64 = 1 << 20
+
+// maxImageSize returns the configured image size limit
+func maxImageSize() int64 {
+	if size := config.GetConfig().Media.MaxUploadSize; size > 0 {
+		return size
+	}
+	return domain.DefaultMaxImageSize
+}
+
+// UploadProductImage godoc
+//
+//	@Summary		Upload a product image
+//	@Description	upload a JPEG, PNG or GIF image of a product as the image field of a multipart form. The image is appended to the product images and a thumbnail is generated
+//	@Tags			Product
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			image	formData	file			true	"Image"
+//	@Success		201		{object}	response		"Image uploaded successfully"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		413		{object}	errorResponse	"Image too large"
+//	@Failure		415		{object}	errorResponse	"Unsupported image type"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/images [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) UploadProductImage(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	limit := maxImageSize()
+	tooLarge := domain.NewCError(http.StatusRequestEntityTooLarge, fmt.Sprintf("image is larger than the limit of %d bytes", limit))
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleError(w, tooLarge)
+			return
+		}
+		handleError(w, domain.NewBadRequestCError("the image field of a multipart form is required"))
+		return
+	}
+	defer file.Close()
+
+	// Read one byte past the limit so an oversized image is detected without reading all of it
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		logger.FromCtx(r.Context()).Error("Error reading uploaded image", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+	if int64(len(data)) > limit {
+		handleError(w, tooLarge)
+		return
+	}
+
+	result, cerr := ch.svc.AddProductImage(r.Context(), id, &domain.ImageUpload{Filename: header.Filename, Data: data})
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Image uploaded successfully")
+}
+
+// DeleteProductImage godoc
+//
+//	@Summary		Delete a product image
+//	@Description	remove an image from a product and delete its files
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Product id"
+//	@Param			image_id	path		string			true	"Image id"
+//	@Success		200			{object}	response		"Success"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/image/{image_id} [delete]
+//	@Security		BearerAuth
+func (ch *ProductHandler) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	imageID, cerr := objectIDParam(r, "image_id", "Invalid image id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.DeleteProductImage(r.Context(), id, imageID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// ReorderProductImages godoc
+//
+//	@Summary		Reorder product images
+//	@Description	set the display order of the images of a product. Every image of the product must be listed once
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Product id"
+//	@Param			domain.ReorderImagesRequest	body		domain.ReorderImagesRequest	true	"Image order"
+//	@Success		200							{object}	response					"Success"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		404							{object}	errorResponse				"Not found error"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/product/{id}/images [put]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ReorderProductImages(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.ReorderImagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.ReorderProductImages(r.Context(), id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.uber.org/zap"
+)
+
+// SchedulePrice godoc
+//
+//	@Summary		Schedule a price change
+//	@Description	set the price of a product at a set time, given in RFC 3339. With ends_at, the price the product had before is put back when the schedule ends, unless the price was changed in the meantime. Schedules of a product cannot overlap
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Product id"
+//	@Param			domain.SchedulePriceRequest	body		domain.SchedulePriceRequest	true	"Price schedule"
+//	@Success		201							{object}	response					"Price scheduled successfully"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		404							{object}	errorResponse				"Not found error"
+//	@Failure		409							{object}	errorResponse				"Overlapping schedule"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/product/{id}/price-schedules [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.SchedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.SchedulePrice(r.Context(), id, userID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Price scheduled successfully")
+}
+
+// ListPriceSchedules godoc
+//
+//	@Summary		List the price schedules of a product
+//	@Description	list every price schedule of a product by its start, with its status
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Product id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/price-schedules [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ListPriceSchedules(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.ListPriceSchedules(r.Context(), id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// CancelPriceSchedule godoc
+//
+//	@Summary		Cancel a price schedule
+//	@Description	cancel a price schedule that has not completed. Cancelling an active schedule puts the previous price back at once
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Product id"
+//	@Param			schedule_id	path		string			true	"Price schedule id"
+//	@Success		200			{object}	response		"Success"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		409			{object}	errorResponse	"The schedule changed meanwhile"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/price-schedule/{schedule_id} [delete]
+//	@Security		BearerAuth
+func (ch *ProductHandler) CancelPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	scheduleID, cerr := objectIDParam(r, "schedule_id", "Invalid price schedule id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.CancelPriceSchedule(r.Context(), id, scheduleID, userID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Price schedule cancelled successfully")
+}
+
+// GetPriceHistory godoc
+//
+//	@Summary		Get the price history of a product
+//	@Description	list every price of a product from the newest, with who changed it and the schedule that applied it
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Product id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/price-history [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.GetPriceHistory(r.Context(), id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
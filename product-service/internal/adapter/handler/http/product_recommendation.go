@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"product-service/internal/core/domain"
+)
+
+// GetRecommendations godoc
+//
+//	@Summary		Get products frequently bought together
+//	@Description	list the active products in stock most often in the same delivered orders as a product, from the most often. They are computed periodically by the order-service
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			limit	query		int				false	"Number of products, at most 20"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/recommendations [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	result, cerr := ch.svc.GetRecommendations(r.Context(), id, limit)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// CreateReview godoc
+//
+//	@Summary		Review a product
+//	@Description	rate a product from 1 to 5 stars with an optional text. Only customers with a delivered order of the product can review it, once
+//	@Tags			Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Product id"
+//	@Param			domain.ReviewRequest	body		domain.ReviewRequest	true	"Review"
+//	@Success		201						{object}	response				"Review created successfully"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		403						{object}	errorResponse			"No delivered order of the product"
+//	@Failure		404						{object}	errorResponse			"Not found error"
+//	@Failure		409						{object}	errorResponse			"Product already reviewed"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/product/{id}/reviews [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	req, ok := ch.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := ch.svc.CreateReview(r.Context(), id, userID, req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Review created successfully")
+}
+
+// ListReviews godoc
+//
+//	@Summary		List the reviews of a product
+//	@Description	list the published reviews of a product from the newest. Admins also get hidden reviews
+//	@Tags			Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			before	query		string			false	"Only list reviews older than this review id"
+//	@Param			limit	query		int				false	"Page size, at most 100"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/reviews [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var before *primitive.ObjectID
+	if v := q.Get("before"); v != "" {
+		beforeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid before review id"))
+			return
+		}
+		before = &beforeID
+	}
+
+	var limit int64
+	if v := q.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	ctxInfo := r.Context().Value(authContextKey).(contextInfo)
+	result, cerr := ch.svc.ListReviews(r.Context(), id, ctxInfo.Role == domain.RAdmin, before, limit)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// UpdateReview godoc
+//
+//	@Summary		Update a review
+//	@Description	change the rating and text of a review of the authenticated user
+//	@Tags			Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Product id"
+//	@Param			review_id				path		string					true	"Review id"
+//	@Param			domain.ReviewRequest	body		domain.ReviewRequest	true	"Review"
+//	@Success		200						{object}	response				"Review updated successfully"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		403						{object}	errorResponse			"Review of another user"
+//	@Failure		404						{object}	errorResponse			"Not found error"
+//	@Failure		409						{object}	errorResponse			"The review changed meanwhile"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/product/{id}/review/{review_id} [patch]
+//	@Security		BearerAuth
+func (ch *ProductHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	reviewID, cerr := objectIDParam(r, "review_id", "Invalid review id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	req, ok := ch.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := ch.svc.UpdateReview(r.Context(), id, reviewID, userID, req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Review updated successfully")
+}
+
+// DeleteReview godoc
+//
+//	@Summary		Delete a review
+//	@Description	delete a review of the authenticated user
+//	@Tags			Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Product id"
+//	@Param			review_id	path		string			true	"Review id"
+//	@Success		200			{object}	response		"Review deleted successfully"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		403			{object}	errorResponse	"Review of another user"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		409			{object}	errorResponse	"The review changed meanwhile"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/review/{review_id} [delete]
+//	@Security		BearerAuth
+func (ch *ProductHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	reviewID, cerr := objectIDParam(r, "review_id", "Invalid review id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	if cerr := ch.svc.DeleteReview(r.Context(), id, reviewID, userID); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Review deleted successfully")
+}
+
+// ModerateReview godoc
+//
+//	@Summary		Moderate a review
+//	@Description	hide a review, which takes it out of the rating of the product, or publish a hidden review again
+//	@Tags			Review
+//	@Accept			json
+//	@Produce		json
+//	@Param			id								path		string							true	"Product id"
+//	@Param			review_id						path		string							true	"Review id"
+//	@Param			domain.ModerateReviewRequest	body		domain.ModerateReviewRequest	true	"Moderation"
+//	@Success		200								{object}	response						"Review moderated successfully"
+//	@Failure		400								{object}	errorResponse					"Validation error"
+//	@Failure		404								{object}	errorResponse					"Not found error"
+//	@Failure		409								{object}	errorResponse					"The review changed meanwhile"
+//	@Failure		500								{object}	errorResponse					"Internal server error"
+//	@Router			/product/{id}/review/{review_id}/moderation [put]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	adminID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	reviewID, cerr := objectIDParam(r, "review_id", "Invalid review id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var req domain.ModerateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := ch.svc.ModerateReview(r.Context(), id, reviewID, adminID, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Review moderated successfully")
+}
+
+// decodeReview decodes and validates the review in the request body, writing the error response when it is invalid
+func (ch *ProductHandler) decodeReview(w http.ResponseWriter, r *http.Request) (*domain.ReviewRequest, bool) {
+	var req domain.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return nil, false
+	}
+
+	if err := ch.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return nil, false
+	}
+
+	return &req, true
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListRevisions godoc
+//
+//	@Summary		List the revisions of a product
+//	@Description	list the revision history of a product from the newest revision, with the fields changed by each revision and its editor
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			before	query		string			false	"Only list revisions older than this revision id"
+//	@Param			limit	query		int				false	"Page size, at most 100"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/revisions [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	q := r.URL.Query()
+
+	var before *primitive.ObjectID
+	if v := q.Get("before"); v != "" {
+		beforeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid before revision id"))
+			return
+		}
+		before = &beforeID
+	}
+
+	var limit int64
+	if v := q.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	result, cerr := ch.svc.ListRevisions(r.Context(), id, before, limit)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// DiffRevisions godoc
+//
+//	@Summary		Compare two revisions of a product
+//	@Description	list the fields that differ between two revisions of a product
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string			true	"Product id"
+//	@Param			from	query		string			true	"Revision id to compare from"
+//	@Param			to		query		string			true	"Revision id to compare to"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/revisions/diff [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	q := r.URL.Query()
+	fromID, err := primitive.ObjectIDFromHex(q.Get("from"))
+	if err != nil {
+		handleError(w, domain.NewBadRequestCError("Invalid from revision id"))
+		return
+	}
+
+	toID, err := primitive.ObjectIDFromHex(q.Get("to"))
+	if err != nil {
+		handleError(w, domain.NewBadRequestCError("Invalid to revision id"))
+		return
+	}
+
+	result, cerr := ch.svc.DiffRevisions(r.Context(), id, fromID, toID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// RevertProduct godoc
+//
+//	@Summary		Revert a product to a revision
+//	@Description	restore the fields of a product to one of its revisions, which records a new revision. Stock is not reverted, and variants removed since the revision come back without stock. The If-Match header must hold the ETag of the product
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Product id"
+//	@Param			revision_id	path		string			true	"Revision id"
+//	@Param			If-Match	header		string			true	"ETag of the product"
+//	@Success		200			{object}	response		"Product reverted successfully"
+//	@Header			200			{string}	ETag			"New version of the product"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		412			{object}	errorResponse	"The product has been changed"
+//	@Failure		428			{object}	errorResponse	"If-Match header missing"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/product/{id}/revision/{revision_id}/revert [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) RevertProduct(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	revisionID, cerr := objectIDParam(r, "revision_id", "Invalid revision id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	version, cerr := ifMatchVersion(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.RevertProduct(r.Context(), id, revisionID, userID, version)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	setETag(w, result)
+	handleSuccessWithMessage(w, http.StatusOK, result, "Product reverted successfully")
+}
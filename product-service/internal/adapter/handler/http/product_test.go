@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"product-service/internal/core/domain"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		wantVersion *int64
+		wantCode    int
+	}{
+		{name: "version", ifMatch: `"3"`, wantVersion: func() *int64 { v := int64(3); return &v }()},
+		{name: "any version", ifMatch: "*"},
+		{name: "missing", ifMatch: "", wantCode: http.StatusPreconditionRequired},
+		{name: "unquoted", ifMatch: "3", wantCode: domain.ErrVersionConflict.Code()},
+		{name: "weak etag", ifMatch: `W/"3"`, wantCode: domain.ErrVersionConflict.Code()},
+		{name: "not a version", ifMatch: `"abc"`, wantCode: domain.ErrVersionConflict.Code()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			version, cerr := ifMatchVersion(r)
+			if tt.wantCode != 0 {
+				if cerr == nil || cerr.Code() != tt.wantCode {
+					t.Fatalf("ifMatchVersion() error = %v, want code %d", cerr, tt.wantCode)
+				}
+				return
+			}
+			if cerr != nil {
+				t.Fatalf("ifMatchVersion() failed: %v", cerr)
+			}
+
+			if (version == nil) != (tt.wantVersion == nil) || (version != nil && *version != *tt.wantVersion) {
+				t.Errorf("ifMatchVersion() = %v, want %v", version, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.uber.org/zap"
+)
+
+// transferFormat reads the format of an import or export from the format query parameter, falling
+// back to the content type of an import body
+func transferFormat(r *http.Request, fallback domain.TransferFormat) (domain.TransferFormat, domain.CError) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		format, ok := domain.StringToTransferFormat[v]
+		if !ok {
+			return "", domain.NewBadRequestCError("format must be csv or ndjson")
+		}
+		return format, nil
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+		switch mediaType {
+		case "text/csv":
+			return domain.TransferFormatCSV, nil
+		case "application/x-ndjson", "application/ndjson":
+			return domain.TransferFormatNDJSON, nil
+		}
+	}
+
+	if fallback == "" {
+		return "", domain.NewBadRequestCError("the format query parameter, or a text/csv or application/x-ndjson content type, is required")
+	}
+	return fallback, nil
+}
+
+// ImportProducts godoc
+//
+//	@Summary		Import products
+//	@Description	create or update products in bulk from a CSV or NDJSON file sent as the request body. A row updates the product with its id, or with its sku, and creates a new product otherwise. CSV files start with a header naming the columns id, sku, name, description, price, quantity, status and category_ids (separated by "|"), and variants can only be given in NDJSON. The file is checked and then imported in the background, and the progress is fetched from the returned job
+//	@Tags			Product
+//	@Accept			text/csv
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			format	query		string			false	"File format, taken from the content type when left out"	Enums(csv, ndjson)
+//	@Success		202		{object}	response		"Import started"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		413		{object}	errorResponse	"File too large"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/products/import [post]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	format, cerr := transferFormat(r, "")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxImportSize)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			handleError(w, domain.NewCError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than the limit of %d bytes", domain.MaxImportSize)))
+			return
+		}
+		logger.FromCtx(r.Context()).Error("Error reading import file", zap.Error(err))
+		handleError(w, domain.ErrInternal)
+		return
+	}
+
+	result, cerr := ch.svc.ImportProducts(r.Context(), userID, format, data)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusAccepted, result, "Import started")
+}
+
+// GetImportJob godoc
+//
+//	@Summary		Get the progress of an import
+//	@Description	fetch the status of a product import with its counts and the rows that failed
+//	@Tags			Product
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Import job id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/products/import/{id} [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	id, cerr := objectIDParam(r, "id", "Invalid import job id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := ch.svc.GetImportJob(r.Context(), id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// ExportProducts godoc
+//
+//	@Summary		Export products
+//	@Description	stream every product that is not deleted as a CSV or NDJSON file, in the format read by the import. Variants are only exported in NDJSON
+//	@Tags			Product
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Param			format	query		string			false	"File format, csv by default"	Enums(csv, ndjson)
+//	@Success		200		{file}		file			"Products"
+//	@Failure		400		{object}	errorResponse	"Validation error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/products/export [get]
+//	@Security		BearerAuth
+func (ch *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format, cerr := transferFormat(r, domain.TransferFormatCSV)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	// Once the file has started, the status can no longer change and a failure leaves it cut short
+	sw := &startedWriter{w: w}
+	if cerr := ch.svc.ExportProducts(r.Context(), format, sw); cerr != nil && !sw.started {
+		w.Header().Del("Content-Disposition")
+		w.Header().Set("Content-Type", "application/json")
+		handleError(w, cerr)
+	}
+}
+
+// startedWriter records whether anything was written to the response
+type startedWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (sw *startedWriter) Write(p []byte) (int, error) {
+	sw.started = true
+	return sw.w.Write(p)
+}
@@ -23,18 +23,22 @@ type Router struct {
 // NewRouter creates a new HTTP router
 func NewRouter(
 	config *config.ServerConfiguration,
+	mediaConfig *config.MediaConfiguration,
 	token port.TokenService,
 	logger *zap.Logger,
 	pingHandler PingHandler,
 	productHandler ProductHandler,
+	categoryHandler CategoryHandler,
+	inventoryHandler InventoryHandler,
+	wishlistHandler WishlistHandler,
 ) (*Router, error) {
 
 	// CORS
 	corsConfig := cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}
@@ -57,6 +61,11 @@ func NewRouter(
 		httpSwagger.URL("0.0.0.0:"+config.HttpPort+"/swagger/doc.json"), //The url pointing to API definition
 	))
 
+	// Media files of the local media store
+	if mediaConfig.Driver == "" || mediaConfig.Driver == "local" {
+		router.Handle("/media/*", http.StripPrefix("/media/", http.FileServer(http.Dir(mediaConfig.Local.Directory))))
+	}
+
 	// v1
 	router.Route("/api/v1", func(r chi.Router) {
 
@@ -71,10 +80,77 @@ func NewRouter(
 			r.Post("/", adminMiddleware(http.HandlerFunc(productHandler.CreateProduct), token, logger))
 			r.Patch("/{id}", adminMiddleware(http.HandlerFunc(productHandler.UpdateProduct), token, logger))
 			r.Delete("/{id}", adminMiddleware(http.HandlerFunc(productHandler.DeleteProduct), token, logger))
+			r.Post("/{id}/images", adminMiddleware(http.HandlerFunc(productHandler.UploadProductImage), token, logger))
+			r.Put("/{id}/images", adminMiddleware(http.HandlerFunc(productHandler.ReorderProductImages), token, logger))
+			r.Delete("/{id}/image/{image_id}", adminMiddleware(http.HandlerFunc(productHandler.DeleteProductImage), token, logger))
+			r.Post("/{id}/stock", adminMiddleware(http.HandlerFunc(inventoryHandler.AdjustStock), token, logger))
+			r.Get("/{id}/movements", adminMiddleware(http.HandlerFunc(inventoryHandler.ListMovements), token, logger))
+			r.Get("/{id}/revisions", adminMiddleware(http.HandlerFunc(productHandler.ListRevisions), token, logger))
+			r.Get("/{id}/revisions/diff", adminMiddleware(http.HandlerFunc(productHandler.DiffRevisions), token, logger))
+			r.Post("/{id}/revision/{revision_id}/revert", adminMiddleware(http.HandlerFunc(productHandler.RevertProduct), token, logger))
+			r.Post("/{id}/price-schedules", adminMiddleware(http.HandlerFunc(productHandler.SchedulePrice), token, logger))
+			r.Get("/{id}/price-schedules", adminMiddleware(http.HandlerFunc(productHandler.ListPriceSchedules), token, logger))
+			r.Delete("/{id}/price-schedule/{schedule_id}", adminMiddleware(http.HandlerFunc(productHandler.CancelPriceSchedule), token, logger))
+			r.Get("/{id}/price-history", adminMiddleware(http.HandlerFunc(productHandler.GetPriceHistory), token, logger))
+			r.Put("/{id}/review/{review_id}/moderation", adminMiddleware(http.HandlerFunc(productHandler.ModerateReview), token, logger))
+			r.Post("/{id}/reviews", authMiddleware(http.HandlerFunc(productHandler.CreateReview), token, logger))
+			r.Get("/{id}/reviews", authMiddleware(http.HandlerFunc(productHandler.ListReviews), token, logger))
+			r.Patch("/{id}/review/{review_id}", authMiddleware(http.HandlerFunc(productHandler.UpdateReview), token, logger))
+			r.Delete("/{id}/review/{review_id}", authMiddleware(http.HandlerFunc(productHandler.DeleteReview), token, logger))
+			r.Post("/{id}/subscription", authMiddleware(http.HandlerFunc(inventoryHandler.SubscribeToStock), token, logger))
+			r.Delete("/{id}/subscription", authMiddleware(http.HandlerFunc(inventoryHandler.UnsubscribeFromStock), token, logger))
+			r.Get("/{id}/recommendations", authMiddleware(http.HandlerFunc(productHandler.GetRecommendations), token, logger))
 
 			r.Get("/{id}", authMiddleware(http.HandlerFunc(productHandler.GetProduct), token, logger))
 		})
 		r.Get("/products", authMiddleware(http.HandlerFunc(productHandler.ListProducts), token, logger))
+		r.Post("/products/import", adminMiddleware(http.HandlerFunc(productHandler.ImportProducts), token, logger))
+		r.Get("/products/import/{id}", adminMiddleware(http.HandlerFunc(productHandler.GetImportJob), token, logger))
+		r.Get("/products/export", adminMiddleware(http.HandlerFunc(productHandler.ExportProducts), token, logger))
+
+		// Category
+		r.Route("/category", func(r chi.Router) {
+			r.Post("/", adminMiddleware(http.HandlerFunc(categoryHandler.CreateCategory), token, logger))
+			r.Patch("/{id}", adminMiddleware(http.HandlerFunc(categoryHandler.RenameCategory), token, logger))
+			r.Post("/{id}/move", adminMiddleware(http.HandlerFunc(categoryHandler.MoveCategory), token, logger))
+			r.Delete("/{id}", adminMiddleware(http.HandlerFunc(categoryHandler.DeleteCategory), token, logger))
+
+			r.Get("/{id}", authMiddleware(http.HandlerFunc(categoryHandler.GetCategory), token, logger))
+		})
+		r.Get("/categories", authMiddleware(http.HandlerFunc(categoryHandler.ListCategories), token, logger))
+
+		// Wishlists of the authenticated user. A shared wishlist can be viewed by anyone with its token
+		r.Route("/wishlists", func(r chi.Router) {
+			r.Post("/", authMiddleware(http.HandlerFunc(wishlistHandler.CreateWishlist), token, logger))
+			r.Get("/", authMiddleware(http.HandlerFunc(wishlistHandler.ListWishlists), token, logger))
+			r.Get("/shared/{token}", wishlistHandler.GetSharedWishlist)
+			r.Get("/{id}", authMiddleware(http.HandlerFunc(wishlistHandler.GetWishlist), token, logger))
+			r.Patch("/{id}", authMiddleware(http.HandlerFunc(wishlistHandler.RenameWishlist), token, logger))
+			r.Delete("/{id}", authMiddleware(http.HandlerFunc(wishlistHandler.DeleteWishlist), token, logger))
+			r.Post("/{id}/items", authMiddleware(http.HandlerFunc(wishlistHandler.AddWishlistItem), token, logger))
+			r.Delete("/{id}/item/{product_id}", authMiddleware(http.HandlerFunc(wishlistHandler.RemoveWishlistItem), token, logger))
+			r.Post("/{id}/share", authMiddleware(http.HandlerFunc(wishlistHandler.ShareWishlist), token, logger))
+			r.Delete("/{id}/share", authMiddleware(http.HandlerFunc(wishlistHandler.UnshareWishlist), token, logger))
+			r.Post("/{id}/order", authMiddleware(http.HandlerFunc(wishlistHandler.OrderWishlist), token, logger))
+		})
+
+		// Products of the authenticated user. Ownership is checked against the owner of each product
+		r.Route("/me/products", func(r chi.Router) {
+			r.Post("/", authMiddleware(http.HandlerFunc(productHandler.CreateOwnProduct), token, logger))
+			r.Get("/", authMiddleware(http.HandlerFunc(productHandler.ListOwnProducts), token, logger))
+			r.Get("/{id}", authMiddleware(http.HandlerFunc(productHandler.GetOwnProduct), token, logger))
+			r.Patch("/{id}", authMiddleware(http.HandlerFunc(productHandler.UpdateOwnProduct), token, logger))
+			r.Delete("/{id}", authMiddleware(http.HandlerFunc(productHandler.DeleteOwnProduct), token, logger))
+			r.Post("/{id}/stock", authMiddleware(http.HandlerFunc(inventoryHandler.AdjustOwnStock), token, logger))
+		})
+
+		// Organization products. Membership and permissions are checked against the owner-service
+		r.Route("/organization/{org_id}", func(r chi.Router) {
+			r.Post("/product", authMiddleware(http.HandlerFunc(productHandler.CreateOrganizationProduct), token, logger))
+			r.Get("/products", authMiddleware(http.HandlerFunc(productHandler.ListOrganizationProducts), token, logger))
+			r.Patch("/product/{id}", authMiddleware(http.HandlerFunc(productHandler.UpdateOrganizationProduct), token, logger))
+			r.Delete("/product/{id}", authMiddleware(http.HandlerFunc(productHandler.DeleteOrganizationProduct), token, logger))
+		})
 	})
 
 	return &Router{
@@ -0,0 +1,416 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// WishlistHandler represents the HTTP handler for wishlist-related requests
+type WishlistHandler struct {
+	svc      port.WishlistService
+	validate *validator.Validate
+}
+
+// NewWishlistHandler creates a new WishlistHandler instance
+func NewWishlistHandler(svc port.WishlistService, vld *validator.Validate) *WishlistHandler {
+	return &WishlistHandler{
+		svc,
+		vld,
+	}
+}
+
+// CreateWishlist godoc
+//
+//	@Summary		Create a wishlist
+//	@Description	create an empty named wishlist for the authenticated user
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			domain.WishlistRequest	body		domain.WishlistRequest	true	"Wishlist"
+//	@Success		201						{object}	response				"Wishlist created successfully"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		409						{object}	errorResponse			"Too many wishlists"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/wishlists [post]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) CreateWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	req, ok := wh.decodeWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.CreateWishlist(r.Context(), userID, req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusCreated, result, "Wishlist created successfully")
+}
+
+// ListWishlists godoc
+//
+//	@Summary		List wishlists
+//	@Description	list the wishlists of the authenticated user with the live price and stock of their items
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	response		"Success"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists [get]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) ListWishlists(w http.ResponseWriter, r *http.Request) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	result, cerr := wh.svc.ListWishlists(r.Context(), userID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// GetWishlist godoc
+//
+//	@Summary		Get a wishlist
+//	@Description	get a wishlist of the authenticated user with the live price and stock of its items
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Wishlist id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id} [get]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.GetWishlist(r.Context(), userID, id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// GetSharedWishlist godoc
+//
+//	@Summary		Get a shared wishlist
+//	@Description	get the wishlist shared with a public link. No authentication is needed
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string			true	"Share token"
+//	@Success		200		{object}	response		"Success"
+//	@Failure		404		{object}	errorResponse	"Not found error"
+//	@Failure		500		{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/shared/{token} [get]
+func (wh *WishlistHandler) GetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	result, cerr := wh.svc.GetSharedWishlist(r.Context(), chi.URLParam(r, "token"))
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// RenameWishlist godoc
+//
+//	@Summary		Rename a wishlist
+//	@Description	change the name of a wishlist of the authenticated user
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id						path		string					true	"Wishlist id"
+//	@Param			domain.WishlistRequest	body		domain.WishlistRequest	true	"Wishlist"
+//	@Success		200						{object}	response				"Wishlist renamed successfully"
+//	@Failure		400						{object}	errorResponse			"Validation error"
+//	@Failure		404						{object}	errorResponse			"Not found error"
+//	@Failure		500						{object}	errorResponse			"Internal server error"
+//	@Router			/wishlists/{id} [patch]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) RenameWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := wh.decodeWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.RenameWishlist(r.Context(), userID, id, req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Wishlist renamed successfully")
+}
+
+// DeleteWishlist godoc
+//
+//	@Summary		Delete a wishlist
+//	@Description	delete a wishlist of the authenticated user, which also stops its public link from working
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Wishlist id"
+//	@Success		200	{object}	response		"Wishlist deleted successfully"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id} [delete]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) DeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	if cerr := wh.svc.DeleteWishlist(r.Context(), userID, id); cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, nil, "Wishlist deleted successfully")
+}
+
+// AddWishlistItem godoc
+//
+//	@Summary		Add an item to a wishlist
+//	@Description	save a product, or one of its variants, in a wishlist of the authenticated user. Saving an item the wishlist already holds changes its quantity
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id							path		string						true	"Wishlist id"
+//	@Param			domain.WishlistItemRequest	body		domain.WishlistItemRequest	true	"Item"
+//	@Success		200							{object}	response					"Item saved successfully"
+//	@Failure		400							{object}	errorResponse				"Validation error"
+//	@Failure		404							{object}	errorResponse				"Not found error"
+//	@Failure		409							{object}	errorResponse				"The wishlist is full"
+//	@Failure		500							{object}	errorResponse				"Internal server error"
+//	@Router			/wishlists/{id}/items [post]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) AddWishlistItem(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return
+	}
+
+	if err := wh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return
+	}
+
+	result, cerr := wh.svc.AddWishlistItem(r.Context(), userID, id, &req)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Item saved successfully")
+}
+
+// RemoveWishlistItem godoc
+//
+//	@Summary		Remove an item from a wishlist
+//	@Description	remove a product, or one of its variants, from a wishlist of the authenticated user
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string			true	"Wishlist id"
+//	@Param			product_id	path		string			true	"Product id"
+//	@Param			variant_id	query		string			false	"Variant id"
+//	@Success		200			{object}	response		"Item removed successfully"
+//	@Failure		400			{object}	errorResponse	"Validation error"
+//	@Failure		404			{object}	errorResponse	"Not found error"
+//	@Failure		500			{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id}/item/{product_id} [delete]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) RemoveWishlistItem(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	productID, cerr := objectIDParam(r, "product_id", "Invalid product id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	var variantID *primitive.ObjectID
+	if v := r.URL.Query().Get("variant_id"); v != "" {
+		parsed, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			handleError(w, domain.NewBadRequestCError("Invalid variant id"))
+			return
+		}
+		variantID = &parsed
+	}
+
+	result, cerr := wh.svc.RemoveWishlistItem(r.Context(), userID, id, productID, variantID)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Item removed successfully")
+}
+
+// ShareWishlist godoc
+//
+//	@Summary		Share a wishlist
+//	@Description	create the share token of a public link to a wishlist of the authenticated user. A wishlist already shared keeps its token
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Wishlist id"
+//	@Success		200	{object}	response		"Wishlist shared successfully"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id}/share [post]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) ShareWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.ShareWishlist(r.Context(), userID, id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Wishlist shared successfully")
+}
+
+// UnshareWishlist godoc
+//
+//	@Summary		Stop sharing a wishlist
+//	@Description	remove the share token of a wishlist of the authenticated user, so its public link stops working
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Wishlist id"
+//	@Success		200	{object}	response		"Wishlist unshared successfully"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id}/share [delete]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) UnshareWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.UnshareWishlist(r.Context(), userID, id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccessWithMessage(w, http.StatusOK, result, "Wishlist unshared successfully")
+}
+
+// OrderWishlist godoc
+//
+//	@Summary		Move a wishlist to an order
+//	@Description	build the body of an order of the available items of a wishlist of the authenticated user, to be placed with the order-service. Unavailable items are returned as skipped, and all items stay in the wishlist
+//	@Tags			Wishlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string			true	"Wishlist id"
+//	@Success		200	{object}	response		"Success"
+//	@Failure		400	{object}	errorResponse	"Validation error"
+//	@Failure		404	{object}	errorResponse	"Not found error"
+//	@Failure		500	{object}	errorResponse	"Internal server error"
+//	@Router			/wishlists/{id}/order [post]
+//	@Security		BearerAuth
+func (wh *WishlistHandler) OrderWishlist(w http.ResponseWriter, r *http.Request) {
+	userID, id, ok := wishlistParams(w, r)
+	if !ok {
+		return
+	}
+
+	result, cerr := wh.svc.OrderWishlist(r.Context(), userID, id)
+	if cerr != nil {
+		handleError(w, cerr)
+		return
+	}
+
+	handleSuccess(w, http.StatusOK, result)
+}
+
+// wishlistParams reads the authenticated user and the wishlist id, writing the error response when either is invalid
+func wishlistParams(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, primitive.ObjectID, bool) {
+	userID, cerr := currentUserID(r)
+	if cerr != nil {
+		handleError(w, cerr)
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	id, cerr := objectIDParam(r, "id", "Invalid wishlist id")
+	if cerr != nil {
+		handleError(w, cerr)
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	return userID, id, true
+}
+
+// decodeWishlist decodes and validates the wishlist in the request body, writing the error response when it is invalid
+func (wh *WishlistHandler) decodeWishlist(w http.ResponseWriter, r *http.Request) (*domain.WishlistRequest, bool) {
+	var req domain.WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromCtx(r.Context()).Error("Error decoding json body", zap.Error(err))
+		handleError(w, domain.NewBadRequestCError("Invalid request body"))
+		return nil, false
+	}
+
+	if err := wh.validate.Struct(&req); err != nil {
+		validationError(w, err)
+		return nil, false
+	}
+
+	return &req, true
+}
@@ -0,0 +1,88 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/core/port"
+)
+
+/**
+ * Local implements port.MediaStore interface
+ * and stores media files in a directory of the local filesystem
+ */
+type Local struct {
+	directory string
+	baseUrl   string
+}
+
+// NewLocal creates a new local media store, creating its directory when it does not exist
+func NewLocal(config *config.LocalMediaConfiguration) (port.MediaStore, error) {
+	if config.Directory == "" {
+		return nil, fmt.Errorf("local media directory is not configured")
+	}
+
+	if err := os.MkdirAll(config.Directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		directory: config.Directory,
+		baseUrl:   strings.TrimSuffix(config.BaseUrl, "/"),
+	}, nil
+}
+
+// Put writes the file to the media directory
+func (l *Local) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a partially written file is never served
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return l.baseUrl + "/" + key, nil
+}
+
+// Delete removes the file from the media directory. Deleting a missing file is not an error
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the location of the key in the media directory, rejecting keys that escape it
+func (l *Local) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid media key: %s", key)
+	}
+
+	return filepath.Join(l.directory, filepath.FromSlash(key)), nil
+}
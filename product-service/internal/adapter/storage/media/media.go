@@ -0,0 +1,20 @@
+package media
+
+import (
+	"fmt"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/core/port"
+)
+
+// New creates the media store selected by the media configuration. The local store is used when no driver is set
+func New(config *config.MediaConfiguration) (port.MediaStore, error) {
+	switch config.Driver {
+	case "", "local":
+		return NewLocal(&config.Local)
+	case "s3":
+		return NewS3(&config.S3)
+	default:
+		return nil, fmt.Errorf("unknown media driver: %s", config.Driver)
+	}
+}
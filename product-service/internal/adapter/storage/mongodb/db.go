@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"pkg/migrate"
 	"product-service/internal/adapter/config"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -72,3 +74,50 @@ func (db *DB) Close() {
 func (db *DB) Url() string {
 	return db.url
 }
+
+// RunMigrations applies all pending migrations to the service database
+func (db *DB) RunMigrations(ctx context.Context, config *config.DatabaseConfiguration) error {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// RollbackMigrations rolls back the service database to the target migration version
+func (db *DB) RollbackMigrations(ctx context.Context, config *config.DatabaseConfiguration, target int64) error {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Down(ctx, target)
+}
+
+// MigrationVersion returns the latest migration version applied to the service database
+func (db *DB) MigrationVersion(ctx context.Context, config *config.DatabaseConfiguration) (int64, error) {
+	migrator, err := migrate.New(db.Client.Database(config.Name), migrations)
+	if err != nil {
+		return 0, err
+	}
+
+	return migrator.Version(ctx)
+}
+
+// SupportsChangeStreams reports whether the database is a replica set or a sharded cluster, the
+// deployments that have change streams
+func (db *DB) SupportsChangeStreams(ctx context.Context) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	err := db.Client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		zap.L().Warn("Error reading the database topology", zap.Error(err))
+		return false
+	}
+
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
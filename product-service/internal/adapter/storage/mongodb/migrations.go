@@ -0,0 +1,513 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"pkg/migrate"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrations lists every migration of the service database. Append new migrations
+// with the next version and never change one that has been released
+var migrations = []migrate.Migration{
+	{
+		Version:     1,
+		Description: "move products from the users collection to the products collection",
+		Up:          moveCollection("users", "products"),
+		Down:        moveCollection("products", "users"),
+	},
+	{
+		Version:     2,
+		Description: "create product indexes",
+		Up: migrate.CreateIndexes("products", []mongo.IndexModel{
+			// Text search over the name and description, weighted towards the name
+			{
+				Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+				Options: options.Index().SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "description", Value: 1}}).SetName("name_description_text_index"),
+			},
+			// Sort orders of the product listing, with _id as the tie breaker for cursor pagination
+			{
+				Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+				Options: options.Index().SetName("created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("price_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("name_index"),
+			},
+			// Owner updates from the queue and the owner filter of the listing
+			{
+				Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("owner_id_created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("status_created_at_index"),
+			},
+			{
+				Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("organization_id_created_at_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("products",
+			"name_description_text_index",
+			"created_at_index",
+			"price_index",
+			"name_index",
+			"owner_id_created_at_index",
+			"status_created_at_index",
+			"organization_id_created_at_index",
+		),
+	},
+	{
+		Version:     3,
+		Description: "create category indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("categories", []mongo.IndexModel{
+				// Subtree lookups by path prefix
+				{
+					Keys:    bson.D{{Key: "path", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("path_unique_index"),
+				},
+				// Sibling categories must have distinct names
+				{
+					Keys:    bson.D{{Key: "parent_id", Value: 1}, {Key: "name", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("parent_id_name_unique_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return migrate.CreateIndexes("products", []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "category_ids", Value: 1}},
+					Options: options.Index().SetName("category_ids_index"),
+				},
+			})(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := migrate.DropIndexes("products", "category_ids_index")(ctx, db); err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("categories", "path_unique_index", "parent_id_name_unique_index")(ctx, db)
+		},
+	},
+	{
+		Version:     4,
+		Description: "create variant sku index",
+		Up: migrate.CreateIndexes("products", []mongo.IndexModel{
+			// SKUs are unique across all products regardless of case. Products without variants are not indexed
+			{
+				Keys: bson.D{{Key: "variants.sku", Value: 1}},
+				Options: options.Index().
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}).
+					SetCollation(&options.Collation{Locale: "en", Strength: 2}).
+					SetName("variants_sku_unique_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("products", "variants_sku_unique_index"),
+	},
+	{
+		Version:     5,
+		Description: "create the inventory ledger with the opening balance of every product",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("stock_movements", []mongo.IndexModel{
+				// Movement history of a product from the newest
+				{
+					Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "_id", Value: -1}},
+					Options: options.Index().SetName("product_id_index"),
+				},
+				// Reservations and releases of an order
+				{
+					Keys:    bson.D{{Key: "reference_id", Value: 1}, {Key: "type", Value: 1}},
+					Options: options.Index().SetSparse(true).SetName("reference_id_type_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return seedOpeningBalances(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("stock_movements").DeleteMany(ctx, bson.M{"type": "initial", "reason": openingBalanceReason})
+			if err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("stock_movements", "product_id_index", "reference_id_type_index")(ctx, db)
+		},
+	},
+	{
+		Version:     6,
+		Description: "create back-in-stock subscriptions and sync the stock status of products",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("stock_subscriptions", []mongo.IndexModel{
+				// A user subscribes once to a product, or to one of its variants
+				{
+					Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "variant_id", Value: 1}, {Key: "user_id", Value: 1}},
+					Options: options.Index().SetUnique(true).SetName("product_id_variant_id_user_id_unique_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			products := db.Collection("products")
+			_, err = products.UpdateMany(ctx, bson.M{"status": "active", "quantity": bson.M{"$lte": 0}},
+				bson.M{"$set": bson.M{"status": "out_of_stock"}})
+			if err != nil {
+				return err
+			}
+
+			_, err = products.UpdateMany(ctx, bson.M{"status": "out_of_stock", "quantity": bson.M{"$gt": 0}},
+				bson.M{"$set": bson.M{"status": "active"}})
+			return err
+		},
+		// The stock status is left as synced, it is valid either way
+		Down: migrate.DropIndexes("stock_subscriptions", "product_id_variant_id_user_id_unique_index"),
+	},
+	{
+		Version:     7,
+		Description: "create product sku index and import jobs index",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("products", []mongo.IndexModel{
+				// Like variant SKUs, product SKUs are unique regardless of case
+				{
+					Keys: bson.D{{Key: "sku", Value: 1}},
+					Options: options.Index().
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"sku": bson.M{"$exists": true}}).
+						SetCollation(&options.Collation{Locale: "en", Strength: 2}).
+						SetName("sku_unique_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return migrate.CreateIndexes("import_jobs", []mongo.IndexModel{
+				// Finished jobs are kept for a month
+				{
+					Keys:    bson.D{{Key: "finished_at", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60).SetName("finished_at_ttl_index"),
+				},
+			})(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := migrate.DropIndexes("import_jobs", "finished_at_ttl_index")(ctx, db); err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("products", "sku_unique_index")(ctx, db)
+		},
+	},
+	{
+		Version:     8,
+		Description: "add a version to products",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("products").UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"version": 1}})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("products").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"version": ""}})
+			return err
+		},
+	},
+	{
+		Version:     9,
+		Description: "create product revisions index",
+		Up: migrate.CreateIndexes("product_revisions", []mongo.IndexModel{
+			// Revision history of a product from the newest
+			{
+				Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "_id", Value: -1}},
+				Options: options.Index().SetName("product_id_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("product_revisions", "product_id_index"),
+	},
+	{
+		Version:     10,
+		Description: "create price schedule and price history indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			err := migrate.CreateIndexes("price_schedules", []mongo.IndexModel{
+				// Schedules of a product by their start
+				{
+					Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "starts_at", Value: 1}},
+					Options: options.Index().SetName("product_id_index"),
+				},
+				// Schedules due to start and to end, read by the scheduler
+				{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "starts_at", Value: 1}},
+					Options: options.Index().SetName("status_starts_at_index"),
+				},
+				{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "ends_at", Value: 1}},
+					Options: options.Index().SetName("status_ends_at_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			err = migrate.CreateIndexes("price_history", []mongo.IndexModel{
+				// Price history of a product from the newest
+				{
+					Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "changed_at", Value: -1}},
+					Options: options.Index().SetName("product_id_index"),
+				},
+			})(ctx, db)
+			if err != nil {
+				return err
+			}
+
+			return seedPriceHistory(ctx, db)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("price_history").DeleteMany(ctx, bson.M{"changed_by": bson.M{"$exists": false}})
+			if err != nil {
+				return err
+			}
+
+			if err := migrate.DropIndexes("price_history", "product_id_index")(ctx, db); err != nil {
+				return err
+			}
+
+			return migrate.DropIndexes("price_schedules", "product_id_index", "status_starts_at_index", "status_ends_at_index")(ctx, db)
+		},
+	},
+	{
+		Version:     11,
+		Description: "create product change log indexes",
+		Up: migrate.CreateIndexes("product_changes", []mongo.IndexModel{
+			// Changes are read in the order of their sequence numbers
+			{
+				Keys:    bson.D{{Key: "seq", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("seq_unique_index"),
+			},
+			// Changes are kept for a week
+			{
+				Keys:    bson.D{{Key: "occurred_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(7 * 24 * 60 * 60).SetName("occurred_at_ttl_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("product_changes", "seq_unique_index", "occurred_at_ttl_index"),
+	},
+	{
+		Version:     12,
+		Description: "create review indexes",
+		Up: migrate.CreateIndexes("reviews", []mongo.IndexModel{
+			// A customer reviews a product once
+			{
+				Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("product_id_user_id_unique_index"),
+			},
+			// Published reviews of a product from the newest
+			{
+				Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "status", Value: 1}, {Key: "_id", Value: -1}},
+				Options: options.Index().SetName("product_id_status_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("reviews", "product_id_user_id_unique_index", "product_id_status_index"),
+	},
+	{
+		Version:     13,
+		Description: "create tag and attribute indexes",
+		Up: migrate.CreateIndexes("products", []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tags", Value: 1}},
+				Options: options.Index().SetName("tags_index"),
+			},
+			// Attribute filters match the name and value of the same attribute
+			{
+				Keys:    bson.D{{Key: "attributes.name", Value: 1}, {Key: "attributes.value", Value: 1}},
+				Options: options.Index().SetName("attributes_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("products", "tags_index", "attributes_index"),
+	},
+	{
+		Version:     14,
+		Description: "create bundle component index",
+		Up: migrate.CreateIndexes("products", []mongo.IndexModel{
+			// Bundles are looked up by component whenever a component changes
+			{
+				Keys:    bson.D{{Key: "bundle.components.product_id", Value: 1}},
+				Options: options.Index().SetName("bundle_components_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("products", "bundle_components_index"),
+	},
+	{
+		Version:     15,
+		Description: "create wishlist indexes",
+		Up: migrate.CreateIndexes("wishlists", []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "_id", Value: 1}},
+				Options: options.Index().SetName("user_id_index"),
+			},
+			// Only shared wishlists have a token
+			{
+				Keys:    bson.D{{Key: "share_token", Value: 1}},
+				Options: options.Index().SetUnique(true).SetSparse(true).SetName("share_token_unique_index"),
+			},
+		}),
+		Down: migrate.DropIndexes("wishlists", "user_id_index", "share_token_unique_index"),
+	},
+}
+
+// openingBalanceReason marks the movements created from the stock products had before the ledger existed
+const openingBalanceReason = "opening balance"
+
+// seedOpeningBalances records the current stock of every product, or of each of its variants, as its
+// first movement. Products that already have movements are skipped, so the step can be run again
+func seedOpeningBalances(ctx context.Context, db *mongo.Database) error {
+	movements := db.Collection("stock_movements")
+
+	cursor, err := db.Collection("products").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var prod struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Quantity int32              `bson:"quantity"`
+			Variants []struct {
+				ID       primitive.ObjectID `bson:"_id"`
+				Quantity int32              `bson:"quantity"`
+			} `bson:"variants"`
+		}
+		if err := cursor.Decode(&prod); err != nil {
+			return err
+		}
+
+		count, err := movements.CountDocuments(ctx, bson.M{"product_id": prod.ID}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		now := time.Now()
+		opening := func(variantID *primitive.ObjectID, quantity int32) bson.M {
+			m := bson.M{
+				"_id":            primitive.NewObjectID(),
+				"product_id":     prod.ID,
+				"type":           "initial",
+				"delta":          quantity,
+				"quantity_after": quantity,
+				"reason":         openingBalanceReason,
+				"created_at":     now,
+			}
+			if variantID != nil {
+				m["variant_id"] = *variantID
+			}
+			return m
+		}
+
+		docs := []interface{}{}
+		if len(prod.Variants) == 0 {
+			docs = append(docs, opening(nil, prod.Quantity))
+		}
+		for _, v := range prod.Variants {
+			docs = append(docs, opening(&v.ID, v.Quantity))
+		}
+
+		if _, err := movements.InsertMany(ctx, docs); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// seedPriceHistory records the current price of every product as the start of its price history,
+// without the user that set it. Products that already have a history are skipped, so the step can
+// be run again
+func seedPriceHistory(ctx context.Context, db *mongo.Database) error {
+	history := db.Collection("price_history")
+
+	cursor, err := db.Collection("products").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var prod struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Price     float64            `bson:"price"`
+			UpdatedAt time.Time          `bson:"updated_at"`
+		}
+		if err := cursor.Decode(&prod); err != nil {
+			return err
+		}
+
+		count, err := history.CountDocuments(ctx, bson.M{"product_id": prod.ID}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		_, err = history.InsertOne(ctx, bson.M{
+			"_id":        primitive.NewObjectID(),
+			"product_id": prod.ID,
+			"price":      prod.Price,
+			"changed_at": prod.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// moveCollection returns a migration step that copies every document of one collection into
+// another and then drops the source. Documents already present in the target are kept, so a
+// step interrupted before the drop can be run again
+func moveCollection(from, to string) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		names, err := db.ListCollectionNames(ctx, bson.M{"name": from})
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+
+		cursor, err := db.Collection(from).Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$merge", Value: bson.M{
+				"into":           to,
+				"on":             "_id",
+				"whenMatched":    "keepExisting",
+				"whenNotMatched": "insert",
+			}}},
+		})
+		if err != nil {
+			return err
+		}
+		if err := cursor.Close(ctx); err != nil {
+			return err
+		}
+
+		return db.Collection(from).Drop(ctx)
+	}
+}
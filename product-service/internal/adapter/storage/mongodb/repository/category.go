@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * CategoryRepository implements port.CategoryRepository interface
+ * and provides an access to the mongo database
+ */
+type CategoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCategoryRepository creates a new category repository instance
+func NewCategoryRepository(db *mongodb.DB) *CategoryRepository {
+	return &CategoryRepository{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("categories"),
+	}
+}
+
+// subtreeFilter matches a category and all of its descendants through the prefix of their paths
+func subtreeFilter(path string) bson.M {
+	return bson.M{"path": bson.M{"$regex": "^" + regexp.QuoteMeta(path)}}
+}
+
+// CreateCategory inserts a category into the database
+func (cr *CategoryRepository) CreateCategory(ctx context.Context, category *domain.Category, parent *domain.Category) (*domain.Category, domain.CError) {
+	category.ID = primitive.NewObjectID()
+	category.Path = domain.CategoryPath(parent, category.ID)
+	category.Depth = 0
+	category.ParentID = nil
+	if parent != nil {
+		category.ParentID = &parent.ID
+		category.Depth = parent.Depth + 1
+	}
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	_, err := cr.collection.InsertOne(ctx, category)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return category, nil
+}
+
+// GetCategoryByID gets a category by its ID from the database
+func (cr *CategoryRepository) GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*domain.Category, domain.CError) {
+	var category domain.Category
+
+	err := cr.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&category)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &category, nil
+}
+
+// GetCategoriesByIDs gets a number of categories by their ids
+func (cr *CategoryRepository) GetCategoriesByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Category, domain.CError) {
+	return cr.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// ListCategories lists every category. Sorting by path puts each parent before its children
+func (cr *CategoryRepository) ListCategories(ctx context.Context) ([]domain.Category, domain.CError) {
+	return cr.find(ctx, bson.M{})
+}
+
+// ListSubtree lists a category and all of its descendants
+func (cr *CategoryRepository) ListSubtree(ctx context.Context, category *domain.Category) ([]domain.Category, domain.CError) {
+	return cr.find(ctx, subtreeFilter(category.Path))
+}
+
+func (cr *CategoryRepository) find(ctx context.Context, filter bson.M) ([]domain.Category, domain.CError) {
+	var categories = make([]domain.Category, 0)
+
+	cursor, err := cr.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "path", Value: 1}}))
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return categories, nil
+}
+
+// RenameCategory changes the name of a category
+func (cr *CategoryRepository) RenameCategory(ctx context.Context, id primitive.ObjectID, name string) (*domain.Category, domain.CError) {
+	var category domain.Category
+
+	err := cr.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"name": name, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&category)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &category, nil
+}
+
+// MoveCategory moves a category under a new parent and rewrites the paths and depths of its subtree
+func (cr *CategoryRepository) MoveCategory(ctx context.Context, category *domain.Category, parent *domain.Category) (*domain.Category, domain.CError) {
+	var parentID *primitive.ObjectID
+	depth := int32(0)
+	if parent != nil {
+		parentID = &parent.ID
+		depth = parent.Depth + 1
+	}
+
+	now := time.Now()
+	_, err := cr.collection.UpdateOne(ctx,
+		bson.M{"_id": category.ID},
+		bson.M{"$set": bson.M{"parent_id": parentID, "updated_at": now}},
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	if cerr := cr.rewritePaths(ctx, category.Path, domain.CategoryPath(parent, category.ID), depth-category.Depth, now); cerr != nil {
+		return nil, cerr
+	}
+
+	return cr.GetCategoryByID(ctx, category.ID)
+}
+
+// DeleteCategory deletes a category and moves its children, with their subtrees, up to its parent
+func (cr *CategoryRepository) DeleteCategory(ctx context.Context, category *domain.Category) domain.CError {
+	children, cerr := cr.find(ctx, bson.M{"parent_id": category.ID})
+	if cerr != nil {
+		return cerr
+	}
+
+	// Moving the children up must not give two siblings the same name
+	if len(children) > 0 {
+		names := make([]string, len(children))
+		for i, child := range children {
+			names[i] = child.Name
+		}
+
+		clashes, err := cr.collection.CountDocuments(ctx, bson.M{
+			"parent_id": category.ParentID,
+			"name":      bson.M{"$in": names},
+			"_id":       bson.M{"$ne": category.ID},
+		})
+		if err != nil {
+			return domain.NewInternalCError(err.Error())
+		}
+		if clashes > 0 {
+			return domain.NewCError(http.StatusConflict, "a child category has the same name as a category under the parent")
+		}
+	}
+
+	now := time.Now()
+	_, err := cr.collection.UpdateMany(ctx,
+		bson.M{"parent_id": category.ID},
+		bson.M{"$set": bson.M{"parent_id": category.ParentID, "updated_at": now}},
+	)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	result, err := cr.collection.DeleteOne(ctx, bson.M{"_id": category.ID})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if result.DeletedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	// With the category gone, its prefix only matches its former descendants
+	parentPath := strings.TrimSuffix(category.Path, category.ID.Hex()+",")
+	return cr.rewritePaths(ctx, category.Path, parentPath, -1, now)
+}
+
+// rewritePaths replaces the oldPrefix of every path in a subtree with newPrefix and shifts their depths
+func (cr *CategoryRepository) rewritePaths(ctx context.Context, oldPrefix, newPrefix string, depthDelta int32, now time.Time) domain.CError {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"path": bson.M{"$concat": bson.A{
+				newPrefix,
+				bson.M{"$substrCP": bson.A{"$path", len(oldPrefix), bson.M{"$subtract": bson.A{bson.M{"$strLenCP": "$path"}, len(oldPrefix)}}}},
+			}},
+			"depth":      bson.M{"$add": bson.A{"$depth", depthDelta}},
+			"updated_at": now,
+		}}},
+	}
+
+	_, err := cr.collection.UpdateMany(ctx, subtreeFilter(oldPrefix), update)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	return nil
+}
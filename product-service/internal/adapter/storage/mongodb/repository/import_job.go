@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/**
+ * ImportJobRepository implements port.ImportJobRepository interface
+ * and provides an access to the mongo database
+ */
+type ImportJobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewImportJobRepository creates a new import job repository instance
+func NewImportJobRepository(db *mongodb.DB) *ImportJobRepository {
+	return &ImportJobRepository{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("import_jobs"),
+	}
+}
+
+// CreateImportJob inserts a new import job
+func (ir *ImportJobRepository) CreateImportJob(ctx context.Context, job *domain.ImportJob) (*domain.ImportJob, domain.CError) {
+	job.ID = primitive.NewObjectID()
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+
+	_, err := ir.collection.InsertOne(ctx, job)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return job, nil
+}
+
+// UpdateImportJob saves the progress of an import job
+func (ir *ImportJobRepository) UpdateImportJob(ctx context.Context, job *domain.ImportJob) domain.CError {
+	job.UpdatedAt = time.Now()
+
+	res, err := ir.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// GetImportJob gets an import job by its id
+func (ir *ImportJobRepository) GetImportJob(ctx context.Context, id primitive.ObjectID) (*domain.ImportJob, domain.CError) {
+	var job domain.ImportJob
+
+	err := ir.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &job, nil
+}
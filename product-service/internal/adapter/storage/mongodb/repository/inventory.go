@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * InventoryRepository implements port.InventoryRepository interface
+ * and provides an access to the mongo database
+ */
+type InventoryRepository struct {
+	collection       *mongo.Collection
+	subscriptionsCol *mongo.Collection
+}
+
+// NewInventoryRepository creates a new inventory repository instance
+func NewInventoryRepository(db *mongodb.DB) *InventoryRepository {
+	database := db.Client.Database(config.GetConfig().Database.Name)
+	return &InventoryRepository{
+		collection:       database.Collection("stock_movements"),
+		subscriptionsCol: database.Collection("stock_subscriptions"),
+	}
+}
+
+// CreateMovement inserts a movement into the ledger. Movements are never updated or deleted
+func (ir *InventoryRepository) CreateMovement(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, domain.CError) {
+	movement.ID = primitive.NewObjectID()
+	movement.CreatedAt = time.Now()
+
+	_, err := ir.collection.InsertOne(ctx, movement)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return movement, nil
+}
+
+// ListMovements lists the movements of a product from the newest
+func (ir *InventoryRepository) ListMovements(ctx context.Context, productID primitive.ObjectID, before *primitive.ObjectID, limit int64) ([]domain.StockMovement, domain.CError) {
+	var movements = make([]domain.StockMovement, 0)
+
+	filter := bson.M{"product_id": productID}
+	if before != nil {
+		filter["_id"] = bson.M{"$lt": *before}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := ir.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return movements, nil
+}
+
+// ListMovementsByReference lists the movements of a type made for a reference, from the oldest
+func (ir *InventoryRepository) ListMovementsByReference(ctx context.Context, referenceID string, movementType domain.MovementType) ([]domain.StockMovement, domain.CError) {
+	var movements = make([]domain.StockMovement, 0)
+
+	filter := bson.M{"reference_id": referenceID, "type": movementType}
+	cursor, err := ir.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return movements, nil
+}
+
+// GetBalance sums the movements of a product, in total and for each of its variants
+func (ir *InventoryRepository) GetBalance(ctx context.Context, productID primitive.ObjectID) (*domain.LedgerBalance, domain.CError) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product_id": productID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$variant_id",
+			"quantity": bson.M{"$sum": "$delta"},
+		}}},
+	}
+
+	cursor, err := ir.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	var totals []struct {
+		VariantID *primitive.ObjectID `bson:"_id"`
+		Quantity  int32               `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	balance := domain.LedgerBalance{Variants: make(map[primitive.ObjectID]int32)}
+	for _, t := range totals {
+		balance.Quantity += t.Quantity
+		if t.VariantID != nil {
+			balance.Variants[*t.VariantID] = t.Quantity
+		}
+	}
+
+	return &balance, nil
+}
+
+// CreateSubscription inserts a back-in-stock subscription. Subscribing again returns the existing subscription
+func (ir *InventoryRepository) CreateSubscription(ctx context.Context, subscription *domain.StockSubscription) (*domain.StockSubscription, domain.CError) {
+	subscription.ID = primitive.NewObjectID()
+	subscription.CreatedAt = time.Now()
+
+	filter := bson.M{
+		"product_id": subscription.ProductID,
+		"variant_id": subscription.VariantID,
+		"user_id":    subscription.UserID,
+	}
+	update := bson.M{"$setOnInsert": subscription}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var created domain.StockSubscription
+	err := ir.subscriptionsCol.FindOneAndUpdate(ctx, filter, update, opts).Decode(&created)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &created, nil
+}
+
+// DeleteSubscription removes the back-in-stock subscription of a user
+func (ir *InventoryRepository) DeleteSubscription(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, userID primitive.ObjectID) domain.CError {
+	filter := bson.M{"product_id": productID, "variant_id": variantID, "user_id": userID}
+
+	res, err := ir.subscriptionsCol.DeleteOne(ctx, filter)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.DeletedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// ListSubscriptions lists the back-in-stock subscriptions of a product, or of one of its variants
+func (ir *InventoryRepository) ListSubscriptions(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID) ([]domain.StockSubscription, domain.CError) {
+	var subscriptions = make([]domain.StockSubscription, 0)
+
+	filter := bson.M{"product_id": productID, "variant_id": variantID}
+	cursor, err := ir.subscriptionsCol.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscriptionsByIDs removes a number of subscriptions by their ids
+func (ir *InventoryRepository) DeleteSubscriptionsByIDs(ctx context.Context, ids []primitive.ObjectID) domain.CError {
+	_, err := ir.subscriptionsCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * PriceRepository implements port.PriceRepository interface
+ * and provides an access to the mongo database
+ */
+type PriceRepository struct {
+	schedules *mongo.Collection
+	history   *mongo.Collection
+}
+
+// NewPriceRepository creates a new price repository instance
+func NewPriceRepository(db *mongodb.DB) *PriceRepository {
+	database := db.Client.Database(config.GetConfig().Database.Name)
+	return &PriceRepository{
+		schedules: database.Collection("price_schedules"),
+		history:   database.Collection("price_history"),
+	}
+}
+
+// CreatePriceSchedule inserts a price schedule
+func (pr *PriceRepository) CreatePriceSchedule(ctx context.Context, schedule *domain.PriceSchedule) (*domain.PriceSchedule, domain.CError) {
+	schedule.ID = primitive.NewObjectID()
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	_, err := pr.schedules.InsertOne(ctx, schedule)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return schedule, nil
+}
+
+// GetPriceSchedule gets a price schedule of a product by its id
+func (pr *PriceRepository) GetPriceSchedule(ctx context.Context, productID, id primitive.ObjectID) (*domain.PriceSchedule, domain.CError) {
+	var schedule domain.PriceSchedule
+
+	err := pr.schedules.FindOne(ctx, bson.M{"_id": id, "product_id": productID}).Decode(&schedule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &schedule, nil
+}
+
+// ListPriceSchedules lists the price schedules of a product by their start
+func (pr *PriceRepository) ListPriceSchedules(ctx context.Context, productID primitive.ObjectID, statuses ...domain.PriceScheduleStatus) ([]domain.PriceSchedule, domain.CError) {
+	filter := bson.M{"product_id": productID}
+	if len(statuses) > 0 {
+		filter["status"] = bson.M{"$in": statuses}
+	}
+
+	return pr.findSchedules(ctx, filter)
+}
+
+// ListDuePriceSchedules lists the schedules to start or end, the oldest first
+func (pr *PriceRepository) ListDuePriceSchedules(ctx context.Context, now time.Time) ([]domain.PriceSchedule, domain.CError) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": domain.PriceScheduleStatusScheduled, "starts_at": bson.M{"$lte": now}},
+		bson.M{"status": domain.PriceScheduleStatusActive, "ends_at": bson.M{"$lte": now}},
+	}}
+
+	return pr.findSchedules(ctx, filter)
+}
+
+func (pr *PriceRepository) findSchedules(ctx context.Context, filter bson.M) ([]domain.PriceSchedule, domain.CError) {
+	var schedules = make([]domain.PriceSchedule, 0)
+
+	opts := options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := pr.schedules.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return schedules, nil
+}
+
+// UpdatePriceSchedule moves a schedule on from the given status. It returns ErrDataNotFound when the
+// schedule is no longer in that status, as another instance has already moved it on
+func (pr *PriceRepository) UpdatePriceSchedule(ctx context.Context, schedule *domain.PriceSchedule, from domain.PriceScheduleStatus) domain.CError {
+	schedule.UpdatedAt = time.Now()
+
+	set := bson.M{
+		"status":     schedule.Status,
+		"updated_at": schedule.UpdatedAt,
+	}
+	if schedule.PreviousPrice != nil {
+		set["previous_price"] = *schedule.PreviousPrice
+	}
+	if schedule.Note != "" {
+		set["note"] = schedule.Note
+	}
+
+	result, err := pr.schedules.UpdateOne(ctx, bson.M{"_id": schedule.ID, "status": from}, bson.M{"$set": set})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// CreatePriceChange inserts an entry of the price history. Entries are never updated or deleted
+func (pr *PriceRepository) CreatePriceChange(ctx context.Context, change *domain.PriceChange) (*domain.PriceChange, domain.CError) {
+	change.ID = primitive.NewObjectID()
+	change.ChangedAt = time.Now()
+
+	_, err := pr.history.InsertOne(ctx, change)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return change, nil
+}
+
+// ListPriceChanges lists the price history of a product from the newest
+func (pr *PriceRepository) ListPriceChanges(ctx context.Context, productID primitive.ObjectID) ([]domain.PriceChange, domain.CError) {
+	var changes = make([]domain.PriceChange, 0)
+
+	opts := options.Find().SetSort(bson.D{{Key: "changed_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := pr.history.Find(ctx, bson.M{"product_id": productID}, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return changes, nil
+}
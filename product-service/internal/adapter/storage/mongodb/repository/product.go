@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
 	"product-service/internal/adapter/storage/mongodb"
 	"product-service/internal/core/domain"
 
@@ -12,6 +15,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
 )
 
 /**
@@ -20,25 +24,77 @@ import (
  */
 type ProductRepository struct {
 	collection *mongo.Collection
+	// changeLog records every change when set, for databases without change streams
+	changeLog *ProductChangeLog
 }
 
 // NewProductRepository creates a new product repository instance
 func NewProductRepository(db *mongodb.DB) *ProductRepository {
 	return &ProductRepository{
-		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("users"),
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("products"),
 	}
 }
 
+// WithChangeLog makes the repository record every change to products in the change log
+func (ur *ProductRepository) WithChangeLog(changeLog *ProductChangeLog) *ProductRepository {
+	ur.changeLog = changeLog
+	return ur
+}
+
+// recordChange records a change of the products in the change log, if there is one. The change is
+// already made when it is recorded, so a failure is only logged
+func (ur *ProductRepository) recordChange(ctx context.Context, changeType domain.ProductChangeType, ids ...primitive.ObjectID) {
+	if ur.changeLog == nil {
+		return
+	}
+
+	if err := ur.changeLog.record(ctx, changeType, ids...); err != nil {
+		logger.FromCtx(ctx).Error("Error recording product change", zap.String("type", string(changeType)), zap.Error(err))
+	}
+}
+
+// changedIDs returns the ids of the products a change to many products is about to make, when the
+// changes are recorded
+func (ur *ProductRepository) changedIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	if ur.changeLog == nil {
+		return nil, nil
+	}
+
+	cursor, err := ur.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
+}
+
 func (ur *ProductRepository) CreateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
 	prod.ID = primitive.NewObjectID()
+	prod.Version = 1
 	prod.CreatedAt = time.Now()
 	prod.UpdatedAt = time.Now()
 
 	_, err := ur.collection.InsertOne(ctx, prod)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
 		return nil, domain.NewInternalCError(err.Error())
 	}
 
+	ur.recordChange(ctx, domain.ProductChangeCreated, prod.ID)
 	return prod, nil
 }
 
@@ -57,56 +113,348 @@ func (ur *ProductRepository) GetProductByID(ctx context.Context, id primitive.Ob
 	return &prod, nil
 }
 
-// ListProducts lists all products in the database
-func (ur *ProductRepository) ListProducts(ctx context.Context) ([]domain.Product, domain.CError) {
-	var prods = make([]domain.Product, 0)
+// GetProductBySKU gets the product with the SKU, or with a variant with the SKU, regardless of case.
+// Deleted products are included as they keep their SKUs
+func (ur *ProductRepository) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, domain.CError) {
+	var prod domain.Product
 
-	cursor, err := ur.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}))
+	filter := bson.M{"$or": bson.A{bson.M{"sku": sku}, bson.M{"variants.sku": sku}}}
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	err := ur.collection.FindOne(ctx, filter, opts).Decode(&prod)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
 		return nil, domain.NewInternalCError(err.Error())
 	}
+
+	return &prod, nil
+}
+
+// ExportProducts calls fn with every product that is not deleted, in the order they were created.
+// It stops at the first error returned by fn
+func (ur *ProductRepository) ExportProducts(ctx context.Context, fn func(*domain.Product) error) domain.CError {
+	filter := bson.M{"deleted_at": bson.M{"$exists": false}}
+	cursor, err := ur.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
 		var prod domain.Product
-		err := cursor.Decode(&prod)
-		if err != nil {
-			return nil, domain.NewInternalCError(err.Error())
+		if err := cursor.Decode(&prod); err != nil {
+			return domain.NewInternalCError(err.Error())
+		}
+
+		if err := fn(&prod); err != nil {
+			return domain.NewInternalCError(err.Error())
 		}
-		prods = append(prods, prod)
 	}
 
 	if err := cursor.Err(); err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	return nil
+}
+
+// ListProducts lists a page of products matching the filter. One more product than the
+// limit is fetched so the caller can tell whether there is a next page
+func (ur *ProductRepository) ListProducts(ctx context.Context, filter *domain.ProductFilter) ([]domain.Product, domain.CError) {
+	var prods = make([]domain.Product, 0)
+
+	conditions := append(productConditions(filter), attributeConditions(filter.Attributes, "")...)
+
+	sortField := domain.ProductSortFields[filter.SortBy]
+	sortOrder, cmp := -1, "$lt"
+	if filter.SortOrder == "asc" {
+		sortOrder, cmp = 1, "$gt"
+	}
+
+	// Keyset pagination: continue strictly after the last product of the previous page
+	if filter.After != nil {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{cmp: filter.After.Value()}},
+			bson.M{sortField: filter.After.Value(), "_id": bson.M{cmp: filter.After.ID}},
+		}})
+	}
+
+	query := bson.M{}
+	if len(conditions) > 0 {
+		query["$and"] = conditions
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: sortOrder}, {Key: "_id", Value: sortOrder}}).
+		SetLimit(filter.Limit + 1)
+
+	cursor, err := ur.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &prods); err != nil {
 		return nil, domain.NewInternalCError(err.Error())
 	}
 
 	return prods, nil
 }
 
-// UpdateProduct updates a product by ID in the database
+// productConditions returns the conditions of a product listing filter, other than its attributes
+// and its cursor
+func productConditions(filter *domain.ProductFilter) bson.A {
+	conditions := bson.A{}
+
+	if filter.Query != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": filter.Query}})
+	}
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, bson.M{"deleted_at": bson.M{"$exists": false}})
+	}
+
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		price := bson.M{}
+		if filter.MinPrice != nil {
+			price["$gte"] = *filter.MinPrice
+		}
+		if filter.MaxPrice != nil {
+			price["$lte"] = *filter.MaxPrice
+		}
+		conditions = append(conditions, bson.M{"price": price})
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, bson.M{"status": filter.Status})
+	}
+
+	if filter.OwnerID != nil {
+		conditions = append(conditions, bson.M{"owner_id": *filter.OwnerID})
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		conditions = append(conditions, bson.M{"category_ids": bson.M{"$in": filter.CategoryIDs}})
+	}
+
+	if filter.InStock != nil {
+		if *filter.InStock {
+			conditions = append(conditions, bson.M{"quantity": bson.M{"$gt": 0}})
+		} else {
+			conditions = append(conditions, bson.M{"quantity": bson.M{"$lte": 0}})
+		}
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, bson.M{"tags": bson.M{"$all": filter.Tags}})
+	}
+
+	return conditions
+}
+
+// attributeConditions returns the conditions matching products with one of the values of each
+// attribute name, leaving out the except name
+func attributeConditions(attributes map[string][]string, except string) bson.A {
+	conditions := bson.A{}
+	for _, name := range sortedAttributeNames(attributes) {
+		if name == except {
+			continue
+		}
+		conditions = append(conditions, bson.M{"attributes": bson.M{"$elemMatch": bson.M{
+			"name":  name,
+			"value": bson.M{"$in": attributes[name]},
+		}}})
+	}
+
+	return conditions
+}
+
+// sortedAttributeNames returns the attribute names of a filter in order, so the queries built from it are stable
+func sortedAttributeNames(attributes map[string][]string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CountProductFacets counts the products matching a listing filter by tag and by attribute value in
+// a single aggregation. Each attribute that is filtered on is counted in its own facet without its
+// own filter, so the counts of its other values are not all zero
+func (ur *ProductRepository) CountProductFacets(ctx context.Context, filter *domain.ProductFilter) (*domain.ProductFacets, domain.CError) {
+	names := sortedAttributeNames(filter.Attributes)
+
+	matching := func(conditions bson.A) mongo.Pipeline {
+		if len(conditions) == 0 {
+			return mongo.Pipeline{}
+		}
+		return mongo.Pipeline{{{Key: "$match", Value: bson.M{"$and": conditions}}}}
+	}
+	countValues := func(field string) mongo.Pipeline {
+		return mongo.Pipeline{
+			{{Key: "$group", Value: bson.M{"_id": field, "count": bson.M{"$sum": 1}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$limit", Value: domain.MaxFacetValues}},
+			{{Key: "$project", Value: bson.M{"_id": 0, "value": "$_id", "count": 1}}},
+		}
+	}
+
+	all := attributeConditions(filter.Attributes, "")
+
+	tags := append(matching(all), bson.D{{Key: "$unwind", Value: "$tags"}})
+	tags = append(tags, countValues("$tags")...)
+
+	attributes := append(matching(all),
+		bson.D{{Key: "$unwind", Value: "$attributes"}},
+		bson.D{{Key: "$match", Value: bson.M{"attributes.name": bson.M{"$nin": names}}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"name": "$attributes.name", "value": "$attributes.value"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id.value", Value: 1}}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":    "$_id.name",
+			"values": bson.M{"$push": bson.M{"value": "$_id.value", "count": "$count"}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{"values": bson.M{"$slice": bson.A{"$values", domain.MaxFacetValues}}}}},
+	)
+
+	facets := bson.D{{Key: "tags", Value: tags}, {Key: "attributes", Value: attributes}}
+	for i, name := range names {
+		pipeline := append(matching(attributeConditions(filter.Attributes, name)),
+			bson.D{{Key: "$unwind", Value: "$attributes"}},
+			bson.D{{Key: "$match", Value: bson.M{"attributes.name": name}}},
+		)
+		facets = append(facets, bson.E{Key: fmt.Sprintf("attribute_%d", i), Value: append(pipeline, countValues("$attributes.value")...)})
+	}
+
+	pipeline := append(matching(productConditions(filter)), bson.D{{Key: "$facet", Value: facets}})
+
+	cursor, err := ur.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, domain.NewInternalCError(err.Error())
+		}
+		return nil, domain.NewInternalCError("the facet aggregation returned no result")
+	}
+
+	var result struct {
+		Tags       []domain.FacetCount `bson:"tags"`
+		Attributes []struct {
+			Name   string              `bson:"_id"`
+			Values []domain.FacetCount `bson:"values"`
+		} `bson:"attributes"`
+	}
+	if err := cursor.Decode(&result); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	productFacets := &domain.ProductFacets{
+		Attributes: make(map[string][]domain.FacetCount, len(result.Attributes)+len(names)),
+		Tags:       append(make([]domain.FacetCount, 0, len(result.Tags)), result.Tags...),
+	}
+	for _, attribute := range result.Attributes {
+		productFacets.Attributes[attribute.Name] = attribute.Values
+	}
+
+	for i, name := range names {
+		values := make([]domain.FacetCount, 0)
+		if err := cursor.Current.Lookup(fmt.Sprintf("attribute_%d", i)).Unmarshal(&values); err != nil {
+			return nil, domain.NewInternalCError(err.Error())
+		}
+		productFacets.Attributes[name] = values
+	}
+
+	return productFacets, nil
+}
+
+// ListProductsByOrganization lists the products of an organization that have not been deleted
+func (ur *ProductRepository) ListProductsByOrganization(ctx context.Context, orgID primitive.ObjectID) ([]domain.Product, domain.CError) {
+	var prods = make([]domain.Product, 0)
+
+	filter := bson.M{"organization_id": orgID, "deleted_at": bson.M{"$exists": false}}
+	cursor, err := ur.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &prods); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return prods, nil
+}
+
+// UpdateProduct updates a product by ID in the database, as long as its version is still the one it
+// was read with. ErrVersionConflict is returned when the product was changed in between
 func (ur *ProductRepository) UpdateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
 	prod.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": prod.ID, "deleted_at": bson.M{"$exists": false}}
-	update := bson.M{
-		"$set": bson.M{
-			"name":        prod.Name,
-			"description": prod.Description,
-			"price":       prod.Price,
-			"quantity":    prod.Quantity,
-			"status":      prod.Status,
-			"updated_at":  prod.UpdatedAt,
-		},
+	filter := bson.M{"_id": prod.ID, "deleted_at": bson.M{"$exists": false}, "version": prod.Version}
+	set := bson.M{
+		"name":         prod.Name,
+		"description":  prod.Description,
+		"price":        prod.Price,
+		"quantity":     prod.Quantity,
+		"status":       prod.Status,
+		"category_ids": prod.CategoryIDs,
+		"variants":     prod.Variants,
+		"tags":         prod.Tags,
+		"attributes":   prod.Attributes,
+		"updated_at":   prod.UpdatedAt,
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+
+	// An empty SKU is removed rather than stored, so it is not caught by the unique index
+	if prod.SKU != "" {
+		set["sku"] = prod.SKU
+	} else {
+		update["$unset"] = bson.M{"sku": ""}
+	}
+
+	// Products that are not bundles are left without the field, as they are created
+	if prod.Bundle != nil {
+		set["bundle"] = prod.Bundle
 	}
 
 	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&prod)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ur.missedUpdate(ctx, prod.ID)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
 		return nil, domain.NewInternalCError(err.Error())
 	}
 
+	ur.recordChange(ctx, domain.ProductChangeUpdated, prod.ID)
 	return prod, nil
 }
 
+// missedUpdate tells why a conditional update of a product matched nothing: the product is gone,
+// or its version changed since it was read
+func (ur *ProductRepository) missedUpdate(ctx context.Context, id primitive.ObjectID) domain.CError {
+	count, err := ur.collection.CountDocuments(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if count == 0 {
+		return domain.ErrDataNotFound
+	}
+	return domain.ErrVersionConflict
+}
+
 // DeleteProduct deletes a product by ID from the database
 func (ur *ProductRepository) DeleteProduct(ctx context.Context, id primitive.ObjectID) domain.CError {
 	filter := bson.M{"_id": id}
@@ -115,9 +463,10 @@ func (ur *ProductRepository) DeleteProduct(ctx context.Context, id primitive.Obj
 			"deleted_at": time.Now(),
 			"status":     domain.ProductStatusInactive,
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	_, err := ur.collection.UpdateOne(ctx, filter, update)
+	res, err := ur.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return domain.ErrDataNotFound
@@ -125,6 +474,9 @@ func (ur *ProductRepository) DeleteProduct(ctx context.Context, id primitive.Obj
 		return domain.NewInternalCError(err.Error())
 	}
 
+	if res.MatchedCount > 0 {
+		ur.recordChange(ctx, domain.ProductChangeDeleted, id)
+	}
 	return nil
 }
 
@@ -135,7 +487,7 @@ func (ur *ProductRepository) GetProductsByIDs(ctx context.Context, productIds []
 	filter := bson.M{
 		"_id":        bson.M{"$in": productIds},
 		"deleted_at": bson.M{"$exists": false},
-		"status":     domain.ProductStatusActive,
+		"status":     bson.M{"$in": bson.A{domain.ProductStatusActive, domain.ProductStatusOutOfStock}},
 	}
 
 	cursor, err := ur.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
@@ -161,7 +513,7 @@ func (ur *ProductRepository) GetProductsByIDs(ctx context.Context, productIds []
 }
 
 // UpdateProductOwner updates a product by ID in the database
-func (ur *ProductRepository) UpdateProductOwner(ctx context.Context, owner *domain.UserUpdateForQueue) (int64, domain.CError) {
+func (ur *ProductRepository) UpdateProductOwner(ctx context.Context, owner *domain.UserProfile) (int64, domain.CError) {
 	oId, err := primitive.ObjectIDFromHex(owner.Id)
 	if err != nil {
 		return -1, domain.NewBadRequestCError("Error parsing owner id received: " + err.Error())
@@ -172,8 +524,14 @@ func (ur *ProductRepository) UpdateProductOwner(ctx context.Context, owner *doma
 		"$set": bson.M{
 			"owner_name":  owner.FirstName + " " + owner.LastName,
 			"owner_phone": owner.Phone,
-			"updated_at":  owner.UpdatedAt,
+			"updated_at":  time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	ids, err := ur.changedIDs(ctx, filter)
+	if err != nil {
+		return -1, domain.NewInternalCError(err.Error())
 	}
 
 	res, err := ur.collection.UpdateMany(ctx, filter, update)
@@ -181,5 +539,271 @@ func (ur *ProductRepository) UpdateProductOwner(ctx context.Context, owner *doma
 		return -1, domain.NewInternalCError(err.Error())
 	}
 
+	ur.recordChange(ctx, domain.ProductChangeUpdated, ids...)
 	return res.MatchedCount, nil
 }
+
+// AdjustStock changes the stock of a product, and of one of its variants when variantID is set, by delta.
+// Stock never goes below zero and, when expected is set, the change only applies if the current stock is
+// still the expected one. ErrInsufficientStock is returned when the change does not apply
+func (ur *ProductRepository) AdjustStock(ctx context.Context, id primitive.ObjectID, variantID *primitive.ObjectID, delta int32, expected *int32) (*domain.Product, domain.CError) {
+	var prod domain.Product
+
+	condition := bson.M{}
+	if delta < 0 {
+		condition["$gte"] = -delta
+	}
+	if expected != nil {
+		condition = bson.M{"$eq": *expected}
+	}
+
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}
+	update := bson.M{
+		"$inc": bson.M{"quantity": delta, "version": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	if variantID != nil {
+		variant := bson.M{"_id": *variantID}
+		if len(condition) > 0 {
+			variant["quantity"] = condition
+		}
+		filter["variants"] = bson.M{"$elemMatch": variant}
+		update["$inc"] = bson.M{"quantity": delta, "variants.$[variant].quantity": delta, "version": 1}
+		opts.SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"variant._id": *variantID}}})
+	} else if len(condition) > 0 {
+		filter["quantity"] = condition
+	}
+
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&prod)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrInsufficientStock
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return &prod, nil
+}
+
+// SyncStockStatus marks an active product without stock as out of stock, and an out of stock product
+// with stock as active again. It returns nil when the status did not need to change
+func (ur *ProductRepository) SyncStockStatus(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	var prod domain.Product
+
+	soldOut := bson.M{"$and": bson.A{
+		bson.M{"$lte": bson.A{"$quantity", 0}},
+		bson.M{"$eq": bson.A{"$status", domain.ProductStatusActive}},
+	}}
+	restocked := bson.M{"$and": bson.A{
+		bson.M{"$gt": bson.A{"$quantity", 0}},
+		bson.M{"$eq": bson.A{"$status", domain.ProductStatusOutOfStock}},
+	}}
+
+	filter := bson.M{
+		"_id":        id,
+		"deleted_at": bson.M{"$exists": false},
+		"$expr":      bson.M{"$or": bson.A{soldOut, restocked}},
+	}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"status": bson.M{"$cond": bson.A{
+			soldOut, domain.ProductStatusOutOfStock, domain.ProductStatusActive,
+		}},
+		"version":    bson.M{"$add": bson.A{"$version", 1}},
+		"updated_at": time.Now(),
+	}}}}
+
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&prod)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return &prod, nil
+}
+
+// AddProductImage appends an image to a product, unless the product already has the maximum number of images
+func (ur *ProductRepository) AddProductImage(ctx context.Context, id primitive.ObjectID, image *domain.ProductImage) (*domain.Product, domain.CError) {
+	var prod domain.Product
+
+	filter := bson.M{
+		"_id":        id,
+		"deleted_at": bson.M{"$exists": false},
+		fmt.Sprintf("images.%d", domain.MaxProductImages-1): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"images": image},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&prod)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return &prod, nil
+}
+
+// RemoveProductImage removes an image from a product
+func (ur *ProductRepository) RemoveProductImage(ctx context.Context, id, imageID primitive.ObjectID) (*domain.Product, domain.CError) {
+	var prod domain.Product
+
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}, "images._id": imageID}
+	update := bson.M{
+		"$pull": bson.M{"images": bson.M{"_id": imageID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&prod)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return &prod, nil
+}
+
+// SetProductImages replaces the images of a product, which is used to reorder them
+func (ur *ProductRepository) SetProductImages(ctx context.Context, id primitive.ObjectID, images []domain.ProductImage) (*domain.Product, domain.CError) {
+	var prod domain.Product
+
+	filter := bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set": bson.M{
+			"images":     images,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&prod)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return &prod, nil
+}
+
+// ReplaceCategory moves every product in a category to another category, or only removes the
+// category from the products when to is nil
+func (ur *ProductRepository) ReplaceCategory(ctx context.Context, from primitive.ObjectID, to *primitive.ObjectID) (int64, domain.CError) {
+	filter := bson.M{"category_ids": from}
+
+	ids, err := ur.changedIDs(ctx, filter)
+	if err != nil {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	if to != nil {
+		_, err := ur.collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"category_ids": *to}})
+		if err != nil {
+			return 0, domain.NewInternalCError(err.Error())
+		}
+	}
+
+	result, err := ur.collection.UpdateMany(ctx, filter, bson.M{
+		"$pull": bson.M{"category_ids": from},
+		"$set":  bson.M{"updated_at": time.Now()},
+		"$inc":  bson.M{"version": 1},
+	})
+	if err != nil {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, ids...)
+	return result.ModifiedCount, nil
+}
+
+// AdjustRating changes the review count and rating total of a product and computes its average from
+// them in the same update, so concurrent reviews cannot leave a stale average. The rating is not an
+// edit of the product, so its version is left as it is
+func (ur *ProductRepository) AdjustRating(ctx context.Context, id primitive.ObjectID, countDelta, totalDelta int64) domain.CError {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"rating_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_count", 0}}, countDelta}},
+			"rating_total": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating_total", 0}}, totalDelta}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"rating_average": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$rating_count", 0}},
+				bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$rating_total", "$rating_count"}}, 2}},
+				0,
+			}},
+		}}},
+	}
+
+	res, err := ur.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, id)
+	return nil
+}
+
+// ListBundlesByComponent lists the bundles that are not deleted and contain the product
+func (ur *ProductRepository) ListBundlesByComponent(ctx context.Context, productID primitive.ObjectID) ([]domain.Product, domain.CError) {
+	var prods = make([]domain.Product, 0)
+
+	filter := bson.M{"bundle.components.product_id": productID, "deleted_at": bson.M{"$exists": false}}
+	cursor, err := ur.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &prods); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return prods, nil
+}
+
+// SyncBundle saves the terms of a bundle derived from its components, as long as the bundle is still
+// at the version it was read with. ErrVersionConflict is returned when it was changed in between
+func (ur *ProductRepository) SyncBundle(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
+	filter := bson.M{"_id": prod.ID, "deleted_at": bson.M{"$exists": false}, "version": prod.Version}
+	update := bson.M{
+		"$set": bson.M{
+			"bundle":     prod.Bundle,
+			"price":      prod.Price,
+			"quantity":   prod.Quantity,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	var updated domain.Product
+	err := ur.collection.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ur.missedUpdate(ctx, prod.ID)
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	ur.recordChange(ctx, domain.ProductChangeUpdated, prod.ID)
+	return &updated, nil
+}
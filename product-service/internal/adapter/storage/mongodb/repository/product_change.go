@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// changeLogPollInterval is how often the change log is read for new changes
+	changeLogPollInterval = time.Second
+	// changeLogGrace is how long a missing sequence number is waited for. Numbers are taken before
+	// their change is written, so a later change can be written first
+	changeLogGrace = 10 * time.Second
+	// changeLogBatchSize bounds the changes read from the change log at once
+	changeLogBatchSize = 100
+	// changeLogCounterID is the id of the counter of change log sequence numbers
+	changeLogCounterID = "product_changes"
+)
+
+// Error codes of change streams that cannot be resumed from the requested token
+const (
+	invalidResumeTokenCode      = 260
+	changeStreamFatalErrorCode  = 280
+	changeStreamHistoryLostCode = 286
+)
+
+// storedProduct is a product as it is stored, with the mark of deleted products
+type storedProduct struct {
+	domain.Product `bson:",inline"`
+	DeletedAt      *time.Time `bson:"deleted_at,omitempty"`
+}
+
+// productChange builds a change from the product read with it. A product that is deleted or gone
+// is sent as deleted, whatever the change was, so a consumer never keeps a deleted product
+func productChange(token string, changeType domain.ProductChangeType, id primitive.ObjectID, stored *storedProduct, at time.Time) *domain.ProductChange {
+	change := &domain.ProductChange{
+		Token:      token,
+		Type:       changeType,
+		ProductID:  id,
+		OccurredAt: at,
+	}
+
+	if stored == nil || stored.DeletedAt != nil {
+		change.Type = domain.ProductChangeDeleted
+		return change
+	}
+
+	change.Product = &stored.Product
+	return change
+}
+
+/**
+ * ProductChangeStream implements port.ProductFeedRepository interface
+ * over the change streams of the products collection
+ */
+type ProductChangeStream struct {
+	collection *mongo.Collection
+}
+
+// NewProductChangeStream creates a product feed reading change streams. Change streams need a replica set
+func NewProductChangeStream(db *mongodb.DB) *ProductChangeStream {
+	return &ProductChangeStream{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("products"),
+	}
+}
+
+// changeEvent is an event of the change stream of the products collection
+type changeEvent struct {
+	ID struct {
+		Data string `bson:"_data"`
+	} `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *storedProduct `bson:"fullDocument"`
+}
+
+// WatchProducts follows the change stream of the products collection. The token is the resume token
+// of the change stream, and the product of each change is looked up when the change is read
+func (cs *ProductChangeStream) WatchProducts(ctx context.Context, token string, fn func(*domain.ProductChange) error) domain.CError {
+	// Products are only soft deleted, so deletes come as updates
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace"}},
+	}}}}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if token != "" {
+		opts.SetResumeAfter(bson.M{"_data": token})
+	}
+
+	stream, err := cs.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return changeStreamError(ctx, err)
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	for stream.Next(ctx) {
+		var event changeEvent
+		if err := stream.Decode(&event); err != nil {
+			return domain.NewInternalCError(err.Error())
+		}
+
+		changeType := domain.ProductChangeUpdated
+		if event.OperationType == "insert" {
+			changeType = domain.ProductChangeCreated
+		}
+
+		at := time.Unix(int64(event.ClusterTime.T), 0)
+		if err := fn(productChange(event.ID.Data, changeType, event.DocumentKey.ID, event.FullDocument, at)); err != nil {
+			return domain.NewInternalCError(err.Error())
+		}
+	}
+
+	return changeStreamError(ctx, stream.Err())
+}
+
+// changeStreamError converts the error of a change stream. Ending the stream by cancelling the context is not an error
+func changeStreamError(ctx context.Context, err error) domain.CError {
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		switch {
+		case serverErr.HasErrorCode(changeStreamHistoryLostCode):
+			return domain.ErrResumeTokenExpired
+		case serverErr.HasErrorCode(invalidResumeTokenCode), serverErr.HasErrorCode(changeStreamFatalErrorCode):
+			return domain.NewBadRequestCError("invalid resume token")
+		}
+	}
+
+	return domain.NewInternalCError(err.Error())
+}
+
+/**
+ * ProductChangeLog implements port.ProductFeedRepository interface over
+ * a log of product changes, for databases without change streams.
+ * The product repository appends to it on every change
+ */
+type ProductChangeLog struct {
+	collection *mongo.Collection
+	counters   *mongo.Collection
+	products   *mongo.Collection
+}
+
+// NewProductChangeLog creates a new product change log instance
+func NewProductChangeLog(db *mongodb.DB) *ProductChangeLog {
+	database := db.Client.Database(config.GetConfig().Database.Name)
+	return &ProductChangeLog{
+		collection: database.Collection("product_changes"),
+		counters:   database.Collection("counters"),
+		products:   database.Collection("products"),
+	}
+}
+
+// productChangeRecord is an entry of the change log. Entries are numbered in the order of their changes
+type productChangeRecord struct {
+	ID         primitive.ObjectID       `bson:"_id"`
+	Seq        int64                    `bson:"seq"`
+	Type       domain.ProductChangeType `bson:"type"`
+	ProductID  primitive.ObjectID       `bson:"product_id"`
+	OccurredAt time.Time                `bson:"occurred_at"`
+}
+
+// record appends a change of the same type for each of the products
+func (cl *ProductChangeLog) record(ctx context.Context, changeType domain.ProductChangeType, ids ...primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	last, err := cl.takeSeq(ctx, int64(len(ids)))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	records := make([]interface{}, 0, len(ids))
+	for i, id := range ids {
+		records = append(records, productChangeRecord{
+			ID:         primitive.NewObjectID(),
+			Seq:        last - int64(len(ids)-1-i),
+			Type:       changeType,
+			ProductID:  id,
+			OccurredAt: now,
+		})
+	}
+
+	_, err = cl.collection.InsertMany(ctx, records)
+	return err
+}
+
+// takeSeq takes n sequence numbers and returns the last of them. A count of zero reads the last
+// number taken
+func (cl *ProductChangeLog) takeSeq(ctx context.Context, n int64) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := cl.counters.FindOneAndUpdate(ctx, bson.M{"_id": changeLogCounterID}, bson.M{"$inc": bson.M{"seq": n}}, opts).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq, nil
+}
+
+// WatchProducts polls the change log. The token is the sequence number of the last change received
+func (cl *ProductChangeLog) WatchProducts(ctx context.Context, token string, fn func(*domain.ProductChange) error) domain.CError {
+	after, cerr := cl.resumeFrom(ctx, token)
+	if cerr != nil {
+		return cerr
+	}
+
+	ticker := time.NewTicker(changeLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		after, cerr = cl.sendChanges(ctx, after, fn)
+		if cerr != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return cerr
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// resumeFrom returns the sequence number the feed resumes after. Changes are removed from the log
+// after a while, so a token older than the oldest change kept cannot be resumed from
+func (cl *ProductChangeLog) resumeFrom(ctx context.Context, token string) (int64, domain.CError) {
+	last, err := cl.takeSeq(ctx, 0)
+	if err != nil {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	if token == "" {
+		return last, nil
+	}
+
+	after, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || after < 0 || after > last {
+		return 0, domain.NewBadRequestCError("invalid resume token")
+	}
+	if after == last {
+		return after, nil
+	}
+
+	var oldest productChangeRecord
+	err = cl.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"seq": 1})).Decode(&oldest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	if err == mongo.ErrNoDocuments || oldest.Seq > after+1 {
+		return 0, domain.ErrResumeTokenExpired
+	}
+
+	return after, nil
+}
+
+// sendChanges sends the changes logged after a sequence number and returns the number of the last
+// change sent. It stops at a missing number until the change is written or the grace period passes,
+// after which the number is taken to belong to a change that failed
+func (cl *ProductChangeLog) sendChanges(ctx context.Context, after int64, fn func(*domain.ProductChange) error) (int64, domain.CError) {
+	opts := options.Find().SetSort(bson.M{"seq": 1}).SetLimit(changeLogBatchSize)
+	cursor, err := cl.collection.Find(ctx, bson.M{"seq": bson.M{"$gt": after}}, opts)
+	if err != nil {
+		return after, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record productChangeRecord
+		if err := cursor.Decode(&record); err != nil {
+			return after, domain.NewInternalCError(err.Error())
+		}
+
+		if record.Seq != after+1 && time.Since(record.OccurredAt) < changeLogGrace {
+			return after, nil
+		}
+
+		var stored storedProduct
+		err := cl.products.FindOne(ctx, bson.M{"_id": record.ProductID}).Decode(&stored)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return after, domain.NewInternalCError(err.Error())
+		}
+
+		product := &stored
+		if err == mongo.ErrNoDocuments {
+			product = nil
+		}
+
+		token := strconv.FormatInt(record.Seq, 10)
+		if err := fn(productChange(token, record.Type, record.ProductID, product, record.OccurredAt)); err != nil {
+			return after, domain.NewInternalCError(err.Error())
+		}
+		after = record.Seq
+	}
+
+	if err := cursor.Err(); err != nil {
+		return after, domain.NewInternalCError(err.Error())
+	}
+
+	return after, nil
+}
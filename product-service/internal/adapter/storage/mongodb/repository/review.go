@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * ReviewRepository implements port.ReviewRepository interface
+ * and provides an access to the mongo database
+ */
+type ReviewRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReviewRepository creates a new review repository instance
+func NewReviewRepository(db *mongodb.DB) *ReviewRepository {
+	return &ReviewRepository{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("reviews"),
+	}
+}
+
+func (rr *ReviewRepository) CreateReview(ctx context.Context, review *domain.Review) (*domain.Review, domain.CError) {
+	review.ID = primitive.NewObjectID()
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = review.CreatedAt
+
+	_, err := rr.collection.InsertOne(ctx, review)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return review, nil
+}
+
+func (rr *ReviewRepository) GetReview(ctx context.Context, productID, id primitive.ObjectID) (*domain.Review, domain.CError) {
+	var review domain.Review
+
+	err := rr.collection.FindOne(ctx, bson.M{"_id": id, "product_id": productID}).Decode(&review)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &review, nil
+}
+
+// ListReviews lists the reviews of a product from the newest
+func (rr *ReviewRepository) ListReviews(ctx context.Context, productID primitive.ObjectID, includeHidden bool, before *primitive.ObjectID, limit int64) ([]domain.Review, domain.CError) {
+	var reviews = make([]domain.Review, 0)
+
+	filter := bson.M{"product_id": productID}
+	if !includeHidden {
+		filter["status"] = domain.ReviewStatusPublished
+	}
+	if before != nil {
+		filter["_id"] = bson.M{"$lt": *before}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := rr.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return reviews, nil
+}
+
+// UpdateReview saves the rating, text and moderation of a review. The rating of the product is kept from
+// the rating and status of the review, so the update only applies while they are still the ones read
+func (rr *ReviewRepository) UpdateReview(ctx context.Context, review, previous *domain.Review) (*domain.Review, domain.CError) {
+	review.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": review.ID, "rating": previous.Rating, "status": previous.Status}
+	update := bson.M{
+		"$set": bson.M{
+			"rating":          review.Rating,
+			"text":            review.Text,
+			"status":          review.Status,
+			"moderated_by":    review.ModeratedBy,
+			"moderation_note": review.ModerationNote,
+			"updated_at":      review.UpdatedAt,
+		},
+	}
+
+	res, err := rr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrDataNotFound
+	}
+
+	return review, nil
+}
+
+func (rr *ReviewRepository) DeleteReview(ctx context.Context, review *domain.Review) domain.CError {
+	res, err := rr.collection.DeleteOne(ctx, bson.M{"_id": review.ID, "rating": review.Rating, "status": review.Status})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.DeletedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
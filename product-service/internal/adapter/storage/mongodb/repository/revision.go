@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * RevisionRepository implements port.RevisionRepository interface
+ * and provides an access to the mongo database
+ */
+type RevisionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRevisionRepository creates a new revision repository instance
+func NewRevisionRepository(db *mongodb.DB) *RevisionRepository {
+	return &RevisionRepository{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("product_revisions"),
+	}
+}
+
+// CreateRevision inserts a revision. Revisions are never updated or deleted
+func (rr *RevisionRepository) CreateRevision(ctx context.Context, revision *domain.ProductRevision) (*domain.ProductRevision, domain.CError) {
+	revision.ID = primitive.NewObjectID()
+	revision.CreatedAt = time.Now()
+
+	_, err := rr.collection.InsertOne(ctx, revision)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return revision, nil
+}
+
+// GetRevision gets a revision of a product by its id
+func (rr *RevisionRepository) GetRevision(ctx context.Context, productID, id primitive.ObjectID) (*domain.ProductRevision, domain.CError) {
+	var revision domain.ProductRevision
+
+	err := rr.collection.FindOne(ctx, bson.M{"_id": id, "product_id": productID}).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &revision, nil
+}
+
+// GetLatestRevision gets the newest revision of a product
+func (rr *RevisionRepository) GetLatestRevision(ctx context.Context, productID primitive.ObjectID) (*domain.ProductRevision, domain.CError) {
+	var revision domain.ProductRevision
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := rr.collection.FindOne(ctx, bson.M{"product_id": productID}, opts).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &revision, nil
+}
+
+// ListRevisions lists the revisions of a product from the newest
+func (rr *RevisionRepository) ListRevisions(ctx context.Context, productID primitive.ObjectID, before *primitive.ObjectID, limit int64) ([]domain.ProductRevision, domain.CError) {
+	var revisions = make([]domain.ProductRevision, 0)
+
+	filter := bson.M{"product_id": productID}
+	if before != nil {
+		filter["_id"] = bson.M{"$lt": *before}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := rr.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/storage/mongodb"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/**
+ * WishlistRepository implements port.WishlistRepository interface
+ * and provides an access to the mongo database
+ */
+type WishlistRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWishlistRepository creates a new wishlist repository instance
+func NewWishlistRepository(db *mongodb.DB) *WishlistRepository {
+	return &WishlistRepository{
+		collection: db.Client.Database(config.GetConfig().Database.Name).Collection("wishlists"),
+	}
+}
+
+func (wr *WishlistRepository) CreateWishlist(ctx context.Context, wishlist *domain.Wishlist) (*domain.Wishlist, domain.CError) {
+	wishlist.ID = primitive.NewObjectID()
+	wishlist.CreatedAt = time.Now()
+	wishlist.UpdatedAt = wishlist.CreatedAt
+	if wishlist.Items == nil {
+		wishlist.Items = make([]domain.WishlistItem, 0)
+	}
+
+	_, err := wr.collection.InsertOne(ctx, wishlist)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return wishlist, nil
+}
+
+func (wr *WishlistRepository) GetWishlist(ctx context.Context, id primitive.ObjectID) (*domain.Wishlist, domain.CError) {
+	return wr.findOne(ctx, bson.M{"_id": id})
+}
+
+func (wr *WishlistRepository) GetWishlistByShareToken(ctx context.Context, token string) (*domain.Wishlist, domain.CError) {
+	return wr.findOne(ctx, bson.M{"share_token": token})
+}
+
+// ListWishlists lists the wishlists of a user from the oldest
+func (wr *WishlistRepository) ListWishlists(ctx context.Context, userID primitive.ObjectID) ([]domain.Wishlist, domain.CError) {
+	var wishlists = make([]domain.Wishlist, 0)
+
+	cursor, err := wr.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &wishlists); err != nil {
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return wishlists, nil
+}
+
+func (wr *WishlistRepository) CountWishlists(ctx context.Context, userID primitive.ObjectID) (int64, domain.CError) {
+	count, err := wr.collection.CountDocuments(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, domain.NewInternalCError(err.Error())
+	}
+
+	return count, nil
+}
+
+func (wr *WishlistRepository) RenameWishlist(ctx context.Context, id primitive.ObjectID, name string) (*domain.Wishlist, domain.CError) {
+	return wr.findOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"name": name, "updated_at": time.Now()},
+	})
+}
+
+func (wr *WishlistRepository) DeleteWishlist(ctx context.Context, id primitive.ObjectID) domain.CError {
+	res, err := wr.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return domain.NewInternalCError(err.Error())
+	}
+
+	if res.DeletedCount == 0 {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// SaveWishlistItem changes the quantity of the item when the wishlist holds it, and appends it otherwise.
+// The append only applies while the wishlist neither holds the item nor is full, so an item saved twice
+// at the same time is only appended once
+func (wr *WishlistRepository) SaveWishlistItem(ctx context.Context, id primitive.ObjectID, item *domain.WishlistItem) (*domain.Wishlist, domain.CError) {
+	match := bson.M{"product_id": item.ProductID, "variant_id": item.VariantID}
+	now := time.Now()
+
+	wishlist, cerr := wr.findOneAndUpdate(ctx, bson.M{"_id": id, "items": bson.M{"$elemMatch": match}}, bson.M{
+		"$set": bson.M{"items.$.quantity": item.Quantity, "updated_at": now},
+	})
+	if cerr != domain.ErrDataNotFound {
+		return wishlist, cerr
+	}
+
+	item.AddedAt = now
+	filter := bson.M{
+		"_id":   id,
+		"items": bson.M{"$not": bson.M{"$elemMatch": match}},
+		fmt.Sprintf("items.%d", domain.MaxWishlistItems-1): bson.M{"$exists": false},
+	}
+	wishlist, cerr = wr.findOneAndUpdate(ctx, filter, bson.M{
+		"$push": bson.M{"items": item},
+		"$set":  bson.M{"updated_at": now},
+	})
+	if cerr != domain.ErrDataNotFound {
+		return wishlist, cerr
+	}
+
+	// Neither update applied: the wishlist is gone, full, or the item was appended meanwhile
+	wishlist, cerr = wr.GetWishlist(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	for _, i := range wishlist.Items {
+		if i.ProductID == item.ProductID && sameObjectID(i.VariantID, item.VariantID) {
+			return wr.findOneAndUpdate(ctx, bson.M{"_id": id, "items": bson.M{"$elemMatch": match}}, bson.M{
+				"$set": bson.M{"items.$.quantity": item.Quantity, "updated_at": now},
+			})
+		}
+	}
+
+	return nil, domain.ErrWishlistFull
+}
+
+func (wr *WishlistRepository) RemoveWishlistItem(ctx context.Context, id, productID primitive.ObjectID, variantID *primitive.ObjectID) (*domain.Wishlist, domain.CError) {
+	match := bson.M{"product_id": productID, "variant_id": variantID}
+
+	return wr.findOneAndUpdate(ctx, bson.M{"_id": id, "items": bson.M{"$elemMatch": match}}, bson.M{
+		"$pull": bson.M{"items": match},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+}
+
+func (wr *WishlistRepository) SetShareToken(ctx context.Context, id primitive.ObjectID, token string) (*domain.Wishlist, domain.CError) {
+	update := bson.M{"$set": bson.M{"share_token": token, "updated_at": time.Now()}}
+	if token == "" {
+		update = bson.M{
+			"$unset": bson.M{"share_token": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		}
+	}
+
+	return wr.findOneAndUpdate(ctx, bson.M{"_id": id}, update)
+}
+
+func (wr *WishlistRepository) findOne(ctx context.Context, filter bson.M) (*domain.Wishlist, domain.CError) {
+	var wishlist domain.Wishlist
+
+	err := wr.collection.FindOne(ctx, filter).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &wishlist, nil
+}
+
+func (wr *WishlistRepository) findOneAndUpdate(ctx context.Context, filter, update bson.M) (*domain.Wishlist, domain.CError) {
+	var wishlist domain.Wishlist
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := wr.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&wishlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrDataNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, domain.ErrConflictingData
+		}
+		return nil, domain.NewInternalCError(err.Error())
+	}
+
+	return &wishlist, nil
+}
+
+func sameObjectID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
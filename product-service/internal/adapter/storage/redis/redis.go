@@ -46,6 +46,36 @@ func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
 	return bytes, err
 }
 
+// MGet retrieves the values from the redis database
+func (r *Redis) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	res, err := r.client.MGet(ctx, keys...).Result()
+	var results = make([][]byte, 0, len(res))
+	for _, v := range res {
+		r, ok := v.(string)
+		if ok {
+			results = append(results, []byte(r))
+			continue
+		}
+
+		results = append(results, nil)
+	}
+	return results, err
+}
+
+// Incr increments the counter stored in the redis database and resets its expiry
+func (r *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
 // Delete removes the value from the redis database
 func (r *Redis) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
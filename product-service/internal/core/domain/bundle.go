@@ -0,0 +1,46 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type BundlePricing string
+
+const (
+	// BundlePricingFixed sells the bundle at the price set on it
+	BundlePricingFixed BundlePricing = "fixed"
+	// BundlePricingDiscount sells the bundle at the total price of its components less a discount
+	BundlePricingDiscount BundlePricing = "discount"
+)
+
+// Bundle makes a product out of other products, such as a gift box. A bundle holds no stock of its
+// own: its quantity is the number of bundles the stock of its components can make up
+type Bundle struct {
+	Components []BundleComponent `json:"components" bson:"components"`
+	Pricing    BundlePricing     `json:"pricing" bson:"pricing"`
+	// DiscountPercent is taken off the total price of the components with discount pricing
+	DiscountPercent float64 `json:"discount_percent,omitempty" bson:"discount_percent,omitempty"`
+}
+
+// BundleComponent is a product, or one of its variants, in a bundle. Name and SKU are copied from
+// the component whenever it changes
+type BundleComponent struct {
+	ProductID primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	Name      string              `json:"name" bson:"name"`
+	SKU       string              `json:"sku,omitempty" bson:"sku,omitempty"`
+	// Quantity is the number of units of the component in one bundle
+	Quantity int32 `json:"quantity" bson:"quantity"`
+}
+
+// BundleRequest describes the components and the pricing of a bundle
+type BundleRequest struct {
+	Components      []BundleComponentRequest `json:"components" validate:"required,min=1,max=20,dive"`
+	Pricing         string                   `json:"pricing" validate:"required,oneof=fixed discount"`
+	DiscountPercent float64                  `json:"discount_percent" validate:"gte=0,lt=100"`
+}
+
+// BundleComponentRequest is a component of a bundle. VariantID is required for products that have variants
+type BundleComponentRequest struct {
+	ProductID string `json:"product_id" validate:"required,mongodb"`
+	VariantID string `json:"variant_id" validate:"omitempty,mongodb"`
+	Quantity  int32  `json:"quantity" validate:"required,gte=1"`
+}
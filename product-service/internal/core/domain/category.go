@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Category represents a row in the "categories" table. Categories form a tree stored with
+// materialized paths: Path holds the ids from the root down to the category itself,
+// each followed by a comma, so a subtree shares its root's path as a prefix
+type Category struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	Name      string              `json:"name" bson:"name"`
+	ParentID  *primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id"`
+	Path      string              `json:"path" bson:"path"`
+	Depth     int32               `json:"depth" bson:"depth"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at" bson:"updated_at"`
+	// Breadcrumbs is the trail from the root down to the category. It is not stored
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs,omitempty" bson:"-"`
+}
+
+// Breadcrumb is a single step in the trail from a root category down to a category
+type Breadcrumb struct {
+	ID   primitive.ObjectID `json:"id"`
+	Name string             `json:"name"`
+}
+
+// CategoryPath returns the materialized path of a category under parent, or at the root when parent is nil
+func CategoryPath(parent *Category, id primitive.ObjectID) string {
+	if parent == nil {
+		return id.Hex() + ","
+	}
+	return parent.Path + id.Hex() + ","
+}
+
+// PathIDs returns the ids in the category's path, from the root down to the category itself
+func (c *Category) PathIDs() []primitive.ObjectID {
+	parts := strings.Split(strings.TrimSuffix(c.Path, ","), ",")
+	ids := make([]primitive.ObjectID, 0, len(parts))
+	for _, part := range parts {
+		if id, err := primitive.ObjectIDFromHex(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// IsAncestorOf reports whether other is the category itself or lies in its subtree
+func (c *Category) IsAncestorOf(other *Category) bool {
+	return strings.HasPrefix(other.Path, c.Path)
+}
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+type RenameCategoryRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// MoveCategoryRequest moves a category with its subtree under a new parent. An empty parent moves it to the root
+type MoveCategoryRequest struct {
+	ParentID string `json:"parent_id"`
+}
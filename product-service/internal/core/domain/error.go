@@ -48,6 +48,8 @@ var (
 	ErrInternal = NewCError(http.StatusInternalServerError, "internal server error")
 	// ErrDataNotFound is an error for when requested data is not found
 	ErrDataNotFound = NewCError(http.StatusNotFound, "data not found")
+	// ErrUserNotFound is an error for when a user does not exist or was deleted
+	ErrUserNotFound = NewCError(http.StatusNotFound, "user not found")
 	// ErrConflictingData is an error for when data conflicts with existing data
 	ErrConflictingData = NewCError(http.StatusConflict, "data conflicts with existing data in unique column")
 	// ErrForeignKeyViolation is an error for when there is a foreign key violation
@@ -60,6 +62,10 @@ var (
 	ErrExpiredToken = NewUnauthorizedCError("access token has expired")
 	// ErrInvalidToken is an error for when the access token is invalid
 	ErrInvalidToken = NewUnauthorizedCError("access token is invalid")
+	// ErrRevokedToken is an error for when the access token was revoked by a password change or an account deletion
+	ErrRevokedToken = NewUnauthorizedCError("access token has been revoked")
+	// ErrAuthUnavailable is an error for when the access token cannot be checked with the owner-service
+	ErrAuthUnavailable = NewCError(http.StatusServiceUnavailable, "could not check the access token, try again later")
 	// ErrEmptyAuthorizationHeader is an error for when the authorization header is empty
 	ErrEmptyAuthorizationHeader = NewUnauthorizedCError("authorization header is not provided")
 	// ErrInvalidAuthorizationHeader is an error for when the authorization header is invalid
@@ -68,6 +74,16 @@ var (
 	ErrInvalidAuthorizationType = NewUnauthorizedCError("authorization type is not supported")
 	// ErrUnauthorized is an error for when the user is unauthorized
 	ErrUnauthorized = NewUnauthorizedCError("user is unauthorized to access the resource")
+	// ErrForbidden is an error for when the user lacks the permission to perform an action
+	ErrForbidden = NewCError(http.StatusForbidden, "user does not have permission to perform this action")
 	// ErrInvalidCredentials is an error for when the credentials are invalid
 	ErrInvalidCredentials = NewUnauthorizedCError("invalid email or password")
+	// ErrInsufficientStock is an error for when a product does not have enough stock for a change
+	ErrInsufficientStock = NewCError(http.StatusConflict, "not enough stock")
+	// ErrVersionConflict is an error for when data was changed since the version the change is based on
+	ErrVersionConflict = NewCError(http.StatusPreconditionFailed, "the product has been changed since it was fetched")
+	// ErrResumeTokenExpired is an error for when the changes after a resume token are no longer kept
+	ErrResumeTokenExpired = NewCError(http.StatusGone, "the changes after the resume token are no longer available")
+	// ErrWishlistFull is an error for when a wishlist already holds the maximum number of items
+	ErrWishlistFull = NewCError(http.StatusConflict, "the wishlist is full")
 )
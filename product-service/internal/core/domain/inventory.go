@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MovementType string
+
+const (
+	// MovementInitial records the opening stock of a product or variant
+	MovementInitial MovementType = "initial"
+	// MovementAdjustment is a manual correction of the stock by an admin
+	MovementAdjustment MovementType = "adjustment"
+	// MovementReservation takes stock for an order
+	MovementReservation MovementType = "reservation"
+	// MovementRelease gives back the stock reserved for an order that was cancelled
+	MovementRelease MovementType = "release"
+	// MovementReturn puts returned items back into stock
+	MovementReturn MovementType = "return"
+	// MovementStockCount sets the stock to the quantity found in a physical count
+	MovementStockCount MovementType = "stock_count"
+	// MovementRemoval takes out the remaining stock of a variant removed from its product
+	MovementRemoval MovementType = "removal"
+)
+
+func (mt MovementType) String() string {
+	return string(mt)
+}
+
+// StockMovement is an immutable entry of the inventory ledger. The stock of a product is the sum of its movements
+type StockMovement struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	Type      MovementType        `json:"type" bson:"type"`
+	// Delta is the change of the stock, negative when stock is taken out
+	Delta int32 `json:"delta" bson:"delta"`
+	// QuantityAfter is the stock of the product, or of the variant, right after the movement
+	QuantityAfter int32               `json:"quantity_after" bson:"quantity_after"`
+	Reason        string              `json:"reason" bson:"reason"`
+	ActorID       *primitive.ObjectID `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	// ReferenceID links the movement to what caused it, such as an order id
+	ReferenceID string    `json:"reference_id,omitempty" bson:"reference_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// AdjustStockRequest is a manual stock change. Adjustments and returns change the stock by Delta,
+// while a stock count sets it to Quantity
+type AdjustStockRequest struct {
+	VariantID   string `json:"variant_id" validate:"omitempty,mongodb"`
+	Type        string `json:"type" validate:"required,oneof=adjustment return stock_count"`
+	Delta       int32  `json:"delta" validate:"required_unless=Type stock_count"`
+	Quantity    *int32 `json:"quantity" validate:"required_if=Type stock_count,omitempty,gte=0"`
+	Reason      string `json:"reason" validate:"required"`
+	ReferenceID string `json:"reference_id"`
+}
+
+// StockLine is the quantity of a product, or of one of its variants, reserved for an order
+type StockLine struct {
+	ProductID primitive.ObjectID
+	VariantID *primitive.ObjectID
+	Quantity  int32
+}
+
+// LedgerBalance is the stock of a product as derived from its movements
+type LedgerBalance struct {
+	Quantity int32
+	Variants map[primitive.ObjectID]int32
+}
+
+// StockLedger is a page of the movements of a product, together with a check of its stock against the ledger
+type StockLedger struct {
+	Movements []StockMovement `json:"movements"`
+	// NextBefore is passed as the before query parameter to fetch older movements. It is empty on the last page
+	NextBefore string `json:"next_before,omitempty"`
+	// Quantity is the stored stock of the product and LedgerQuantity the stock derived from all of its movements
+	Quantity       int32 `json:"quantity"`
+	LedgerQuantity int32 `json:"ledger_quantity"`
+	InSync         bool  `json:"in_sync"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductImage is an image of a product. The images of a product are kept in display order
+type ProductImage struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	URL          string             `json:"url" bson:"url"`
+	ThumbnailURL string             `json:"thumbnail_url" bson:"thumbnail_url"`
+	ContentType  string             `json:"content_type" bson:"content_type"`
+	Size         int64              `json:"size" bson:"size"`
+	Width        int                `json:"width" bson:"width"`
+	Height       int                `json:"height" bson:"height"`
+	// Key and ThumbnailKey locate the files in the media store
+	Key          string    `json:"-" bson:"key"`
+	ThumbnailKey string    `json:"-" bson:"thumbnail_key"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+// ImageUpload is an image file uploaded for a product
+type ImageUpload struct {
+	Filename string
+	Data     []byte
+}
+
+// ReorderImagesRequest lists every image of a product in the new display order
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1,dive,mongodb"`
+}
+
+const (
+	// DefaultMaxImageSize is the largest image upload accepted when the media configuration does not set one
+	DefaultMaxImageSize int64 = 5 << 20
+	// DefaultThumbnailSize is the longest side of a thumbnail when the media configuration does not set one
+	DefaultThumbnailSize = 320
+	// MaxProductImages is the largest number of images a product can have
+	MaxProductImages = 10
+	// MaxImagePixels is the largest number of pixels of an accepted image, which bounds the memory used to decode it
+	MaxImagePixels = 40_000_000
+)
+
+// ImageExtensions maps the accepted image content types, as sniffed from the uploaded bytes, to their file extensions
+var ImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
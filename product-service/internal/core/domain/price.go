@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PriceScheduleStatus string
+
+const (
+	// PriceScheduleStatusScheduled is a schedule waiting for its start
+	PriceScheduleStatusScheduled PriceScheduleStatus = "scheduled"
+	// PriceScheduleStatusActive is a schedule whose price is applied until its end
+	PriceScheduleStatusActive PriceScheduleStatus = "active"
+	// PriceScheduleStatusCompleted is a schedule that was applied, and restored the previous price
+	// if it has an end
+	PriceScheduleStatusCompleted PriceScheduleStatus = "completed"
+	// PriceScheduleStatusCancelled is a schedule cancelled before it completed
+	PriceScheduleStatusCancelled PriceScheduleStatus = "cancelled"
+)
+
+// PriceSchedule is a price a product takes at a set time. A schedule with an end puts the price
+// the product had before back when it ends
+type PriceSchedule struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	Price     float64             `json:"price" bson:"price"`
+	StartsAt  time.Time           `json:"starts_at" bson:"starts_at"`
+	EndsAt    *time.Time          `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	Status    PriceScheduleStatus `json:"status" bson:"status"`
+	// PreviousPrice is the price of the product when the schedule started
+	PreviousPrice *float64 `json:"previous_price,omitempty" bson:"previous_price,omitempty"`
+	// Note tells why a schedule completed or was cancelled without changing the price
+	Note      string             `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type SchedulePriceRequest struct {
+	Price    float64    `json:"price" validate:"required,gt=0"`
+	StartsAt time.Time  `json:"starts_at" validate:"required"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// PriceChange is an entry of the price history of a product
+type PriceChange struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	Price     float64            `json:"price" bson:"price"`
+	// PreviousPrice is not set for the price a product was created with
+	PreviousPrice *float64 `json:"previous_price,omitempty" bson:"previous_price,omitempty"`
+	// ChangedBy is the user that changed the price, or that created the schedule that changed it.
+	// It is not set for the prices recorded when the history of existing products started
+	ChangedBy *primitive.ObjectID `json:"changed_by,omitempty" bson:"changed_by,omitempty"`
+	// ScheduleID is the price schedule that made the change
+	ScheduleID *primitive.ObjectID `json:"schedule_id,omitempty" bson:"schedule_id,omitempty"`
+	ChangedAt  time.Time           `json:"changed_at" bson:"changed_at"`
+}
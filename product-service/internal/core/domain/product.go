@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,8 +27,10 @@ func (ur ProductStatus) String() string {
 }
 
 type Product struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id"`
-	Name        string             `json:"name" bson:"name"`
+	ID   primitive.ObjectID `json:"id" bson:"_id"`
+	Name string             `json:"name" bson:"name"`
+	// SKU identifies a product without variants. Products with variants are identified by the SKUs of their variants
+	SKU         string             `json:"sku,omitempty" bson:"sku,omitempty"`
 	Description string             `json:"description" bson:"description"`
 	Price       float64            `json:"price" bson:"price"`
 	Quantity    int32              `json:"quantity" bson:"quantity"`
@@ -35,28 +39,254 @@ type Product struct {
 	OwnerName   string             `json:"owner_name" bson:"owner_name"`
 	OwnerPhone  string             `json:"owner_phone" bson:"owner_phone"`
 	OwnerEmail  string             `json:"owner_email" bson:"owner_email"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	// OrganizationID is set when the product is managed by an organization rather than only by its owner
+	OrganizationID *primitive.ObjectID  `json:"organization_id,omitempty" bson:"organization_id,omitempty"`
+	CategoryIDs    []primitive.ObjectID `json:"category_ids" bson:"category_ids"`
+	// Variants are the purchasable versions of the product. When a product has variants its
+	// quantity is the total stock of all of them
+	Variants []Variant `json:"variants" bson:"variants,omitempty"`
+	// Bundle is set on products made of other products. Their price, with discount pricing, and
+	// their quantity are derived from the components and kept up to date as the components change
+	Bundle *Bundle `json:"bundle,omitempty" bson:"bundle,omitempty"`
+	// Images are in display order, the first one being the main image of the product
+	Images []ProductImage `json:"images" bson:"images,omitempty"`
+	// Tags are free-form lowercase labels, kept sorted
+	Tags []string `json:"tags" bson:"tags,omitempty"`
+	// Attributes are structured properties such as brand, material or weight, one value per name
+	Attributes []ProductAttribute `json:"attributes" bson:"attributes,omitempty"`
+	// RatingAverage and RatingCount summarize the published reviews of the product. RatingTotal is
+	// the sum of their ratings, from which the average is kept
+	RatingAverage float64 `json:"rating_average" bson:"rating_average"`
+	RatingCount   int64   `json:"rating_count" bson:"rating_count"`
+	RatingTotal   int64   `json:"-" bson:"rating_total"`
+	// Version is incremented by every change to the product, and is exposed as its ETag
+	Version   int64     `json:"version" bson:"version"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// Breadcrumbs holds the trail from the root of each of the product's categories. It is not stored
+	Breadcrumbs [][]Breadcrumb `json:"breadcrumbs,omitempty" bson:"-"`
+}
+
+// Variant is a purchasable version of a product, such as a size or colour, with its own SKU and stock
+type Variant struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	SKU     string             `json:"sku" bson:"sku"`
+	Options map[string]string  `json:"options" bson:"options"`
+	// Price overrides the product price when set
+	Price    *float64 `json:"price,omitempty" bson:"price,omitempty"`
+	Quantity int32    `json:"quantity" bson:"quantity"`
+}
+
+// EffectivePrice returns the variant price, falling back to the price of its product
+func (v *Variant) EffectivePrice(productPrice float64) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return productPrice
+}
+
+// ProductAttribute is a named property of a product. Names are lowercase and unique within a product
+type ProductAttribute struct {
+	Name  string `json:"name" bson:"name" validate:"required,max=50"`
+	Value string `json:"value" bson:"value" validate:"required,max=200"`
+}
+
+// MaxFacetValues bounds the values counted for each facet, the most frequent being kept
+const MaxFacetValues = 20
+
+// VariantRequest describes a variant when creating or updating a product. ID is set to keep
+// an existing variant, so orders that reference it stay valid
+type VariantRequest struct {
+	ID       string            `json:"id" validate:"omitempty,mongodb"`
+	SKU      string            `json:"sku" validate:"required"`
+	Options  map[string]string `json:"options" validate:"required,min=1"`
+	Price    *float64          `json:"price" validate:"omitempty,gte=0"`
+	Quantity int32             `json:"quantity" validate:"gte=0"`
 }
 
 type CreateProductRequest struct {
-	Name        string        `json:"name" validate:"required"`
-	Description string        `json:"description" validate:"required"`
-	Price       float64       `json:"price" validate:"required,gte=0"`
-	Quantity    int32         `json:"quantity" validate:"required,gte=1"`
-	Status      ProductStatus `json:"-"`
+	Name        string `json:"name" validate:"required"`
+	SKU         string `json:"sku"`
+	Description string `json:"description" validate:"required"`
+	// Price is ignored for bundles with discount pricing
+	Price float64 `json:"price" validate:"required_without=Bundle,gte=0"`
+	// Quantity is ignored when variants or a bundle are given, as the stock is then held by the
+	// variants or by the components
+	Quantity    int32              `json:"quantity" validate:"required_without_all=Variants Bundle,gte=0"`
+	CategoryIDs []string           `json:"category_ids" validate:"omitempty,dive,mongodb"`
+	Variants    []VariantRequest   `json:"variants" validate:"omitempty,dive"`
+	Tags        []string           `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	Attributes  []ProductAttribute `json:"attributes" validate:"omitempty,max=30,dive"`
+	// Bundle makes the product a bundle of other products. A bundle cannot have variants
+	Bundle *BundleRequest `json:"bundle" validate:"omitempty"`
+	Status ProductStatus  `json:"-"`
 }
 
 type UpdateProductRequest struct {
-	Name        string  `json:"name"`
+	Name string `json:"name"`
+	// SKU replaces the product's SKU when set. An empty SKU removes it
+	SKU         *string `json:"sku"`
 	Description string  `json:"description"`
 	Price       float64 `json:"price"`
 	Quantity    int32   `json:"quantity"`
 	Status      string  `json:"status"`
+	// CategoryIDs replaces the product's categories when set. An empty list removes all categories
+	CategoryIDs *[]string `json:"category_ids" validate:"omitempty,dive,mongodb"`
+	// Variants replaces the product's variants when set. An empty list removes all variants
+	Variants *[]VariantRequest `json:"variants" validate:"omitempty,dive"`
+	// Tags replaces the product's tags when set. An empty list removes all tags
+	Tags *[]string `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	// Attributes replaces the product's attributes when set. An empty list removes all attributes
+	Attributes *[]ProductAttribute `json:"attributes" validate:"omitempty,max=30,dive"`
+	// Bundle replaces the components and pricing of a bundle when set. It can only be set on bundles,
+	// whose quantity, and price with discount pricing, are derived and not taken from the update
+	Bundle *BundleRequest `json:"bundle" validate:"omitempty"`
 }
 
+// Organization permissions, granted to members by the owner-service according to their role
+const (
+	PermWriteProducts  = "products:write"
+	PermDeleteProducts = "products:delete"
+)
+
 // User roles
 const (
 	RAdmin string = "admin"
 	RUser  string = "user"
 )
+
+// ProductFilter holds the search, filter, sort and pagination options of a product listing
+type ProductFilter struct {
+	Query          string
+	MinPrice       *float64
+	MaxPrice       *float64
+	Status         ProductStatus
+	OwnerID        *primitive.ObjectID
+	CategoryID     *primitive.ObjectID
+	InStock        *bool
+	IncludeDeleted bool
+	SortBy         string
+	SortOrder      string
+	Limit          int64
+	// After is the decoded cursor of the last product of the previous page
+	After *ProductCursor
+	// CategoryIDs is CategoryID together with all of its descendants, resolved by the service
+	CategoryIDs []primitive.ObjectID
+	// Tags only lists products with all of the tags
+	Tags []string
+	// Attributes only lists products with one of the values of each attribute name
+	Attributes map[string][]string
+}
+
+// ProductPage is a page of products returned from a listing
+type ProductPage struct {
+	Products []Product `json:"products"`
+	// NextCursor is passed as the cursor query parameter to fetch the next page. It is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int64  `json:"limit"`
+	// Facets count the products matching the listing by tag and attribute value. They are only
+	// returned with the first page
+	Facets *ProductFacets `json:"facets,omitempty"`
+}
+
+// ProductFacets holds the facet counts of a listing. The counts of an attribute that is filtered on
+// ignore the filter on that attribute, so they tell how many products each other value would add
+type ProductFacets struct {
+	Attributes map[string][]FacetCount `json:"attributes"`
+	Tags       []FacetCount            `json:"tags"`
+}
+
+// FacetCount is the number of products with a value of a facet
+type FacetCount struct {
+	Value string `json:"value" bson:"value"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+const (
+	// DefaultPageSize is used when a listing does not specify a limit
+	DefaultPageSize int64 = 20
+	// MaxPageSize is the largest limit a listing may request
+	MaxPageSize int64 = 100
+)
+
+// ProductSortFields maps the accepted sort_by values to their stored field names
+var ProductSortFields = map[string]string{
+	"price":      "price",
+	"name":       "name",
+	"created_at": "created_at",
+}
+
+// Normalize fills in default pagination and sorting values and caps the limit
+func (f *ProductFilter) Normalize() {
+	if f.Limit < 1 {
+		f.Limit = DefaultPageSize
+	}
+
+	if f.Limit > MaxPageSize {
+		f.Limit = MaxPageSize
+	}
+
+	if f.SortBy == "" {
+		f.SortBy = "created_at"
+	}
+
+	if f.SortOrder != "asc" {
+		f.SortOrder = "desc"
+	}
+}
+
+// ProductCursor records the position of the last product of a page. It is bound to
+// the sort it was created for so it cannot be replayed against a different ordering
+type ProductCursor struct {
+	SortBy    string             `json:"s"`
+	SortOrder string             `json:"o"`
+	ID        primitive.ObjectID `json:"id"`
+	Price     float64            `json:"p,omitempty"`
+	Name      string             `json:"n,omitempty"`
+	CreatedAt time.Time          `json:"c,omitempty"`
+}
+
+// NewProductCursor creates the cursor pointing after prod for the filter's sort
+func NewProductCursor(f *ProductFilter, prod *Product) *ProductCursor {
+	return &ProductCursor{
+		SortBy:    f.SortBy,
+		SortOrder: f.SortOrder,
+		ID:        prod.ID,
+		Price:     prod.Price,
+		Name:      prod.Name,
+		CreatedAt: prod.CreatedAt,
+	}
+}
+
+// Value returns the cursor value of the field it is sorted by
+func (c *ProductCursor) Value() any {
+	switch c.SortBy {
+	case "price":
+		return c.Price
+	case "name":
+		return c.Name
+	default:
+		return c.CreatedAt
+	}
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *ProductCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeProductCursor parses a cursor produced by ProductCursor.Encode
+func DecodeProductCursor(s string) (*ProductCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c ProductCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
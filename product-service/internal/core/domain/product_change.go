@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductChangeType is the kind of change made to a product
+type ProductChangeType string
+
+const (
+	ProductChangeCreated ProductChangeType = "created"
+	ProductChangeUpdated ProductChangeType = "updated"
+	ProductChangeDeleted ProductChangeType = "deleted"
+)
+
+// ProductChange is a change made to a product, read from the product feed
+type ProductChange struct {
+	// Token resumes the feed right after this change
+	Token     string
+	Type      ProductChangeType
+	ProductID primitive.ObjectID
+	// Product is the product as it was when the change was read, so it can already hold the changes
+	// that follow. It is nil for deletes
+	Product    *Product
+	OccurredAt time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+const (
+	// MaxRecommendations is the largest number of recommendations returned at once
+	MaxRecommendations = 20
+	// DefaultRecommendations is the number of recommendations returned when no limit is asked for
+	DefaultRecommendations = 10
+)
+
+// Recommendation is a product frequently bought together with another one
+type Recommendation struct {
+	Product *Product `json:"product"`
+	// Orders is the number of delivered orders containing both products
+	Orders int64 `json:"orders"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewStatus tells whether a review is shown
+type ReviewStatus string
+
+const (
+	ReviewStatusPublished ReviewStatus = "published"
+	// ReviewStatusHidden is set by admins. Hidden reviews are only listed to admins and are left out of the rating of the product
+	ReviewStatusHidden ReviewStatus = "hidden"
+)
+
+// Review is the rating and opinion of a customer who received a product. A customer reviews a product once
+type Review struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	// Rating is from 1 to 5 stars
+	Rating int32        `json:"rating" bson:"rating"`
+	Text   string       `json:"text" bson:"text"`
+	Status ReviewStatus `json:"status" bson:"status"`
+	// ModeratedBy and ModerationNote record the last moderation of the review
+	ModeratedBy    *primitive.ObjectID `json:"moderated_by,omitempty" bson:"moderated_by,omitempty"`
+	ModerationNote string              `json:"moderation_note,omitempty" bson:"moderation_note,omitempty"`
+	CreatedAt      time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+type ReviewRequest struct {
+	Rating int32  `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text" validate:"max=5000"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" validate:"required,oneof=published hidden"`
+	// Note tells the reviewer why the review was hidden
+	Note string `json:"note" validate:"max=500"`
+}
+
+// ReviewPage is a page of the reviews of a product from the newest
+type ReviewPage struct {
+	Reviews []Review `json:"reviews"`
+	// NextBefore is passed as the before query parameter to fetch older reviews. It is empty on the last page
+	NextBefore string `json:"next_before,omitempty"`
+}
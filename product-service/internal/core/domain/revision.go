@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductRevision is the state of a product after one of its changes. Stock is not part of a revision,
+// as it changes through the inventory ledger
+type ProductRevision struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID `json:"product_id" bson:"product_id"`
+	// Version is the version of the product the change produced
+	Version  int64           `json:"version" bson:"version"`
+	Snapshot ProductSnapshot `json:"snapshot" bson:"snapshot"`
+	// Changes are the fields changed from the previous revision
+	Changes []FieldChange `json:"changes" bson:"changes"`
+	// EditorID is the user that made the change. It is not set for the revision recorded when the
+	// history of a product that existed before revisions starts
+	EditorID *primitive.ObjectID `json:"editor_id,omitempty" bson:"editor_id,omitempty"`
+	// RevertedFrom is the revision the product was reverted to by the change
+	RevertedFrom *primitive.ObjectID `json:"reverted_from,omitempty" bson:"reverted_from,omitempty"`
+	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// ProductSnapshot holds the fields of a product tracked by its revisions
+type ProductSnapshot struct {
+	Name        string               `json:"name" bson:"name"`
+	SKU         string               `json:"sku,omitempty" bson:"sku,omitempty"`
+	Description string               `json:"description" bson:"description"`
+	Price       float64              `json:"price" bson:"price"`
+	Status      ProductStatus        `json:"status" bson:"status"`
+	CategoryIDs []primitive.ObjectID `json:"category_ids" bson:"category_ids"`
+	Variants    []VariantSnapshot    `json:"variants" bson:"variants"`
+	// Tags and Attributes are not set in revisions recorded before products had them
+	Tags       []string           `json:"tags" bson:"tags"`
+	Attributes []ProductAttribute `json:"attributes" bson:"attributes"`
+	Bundle     *Bundle            `json:"bundle,omitempty" bson:"bundle,omitempty"`
+}
+
+// VariantSnapshot is a variant in a revision, without its stock
+type VariantSnapshot struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	SKU     string             `json:"sku" bson:"sku"`
+	Options map[string]string  `json:"options" bson:"options"`
+	Price   *float64           `json:"price,omitempty" bson:"price,omitempty"`
+}
+
+// FieldChange is the change of a field between two revisions. Variants are compared one by one
+// as variants.<variant id>, with a null value when the variant was added or removed
+type FieldChange struct {
+	Field string          `json:"field" bson:"field"`
+	From  json.RawMessage `json:"from" bson:"from"`
+	To    json.RawMessage `json:"to" bson:"to"`
+}
+
+// RevisionPage is a page of the revisions of a product from the newest
+type RevisionPage struct {
+	Revisions []ProductRevision `json:"revisions"`
+	// NextBefore is passed as the before query parameter to fetch older revisions. It is empty on the last page
+	NextBefore string `json:"next_before,omitempty"`
+}
+
+// RevisionDiff is the difference between two revisions of a product
+type RevisionDiff struct {
+	From    primitive.ObjectID `json:"from"`
+	To      primitive.ObjectID `json:"to"`
+	Changes []FieldChange      `json:"changes"`
+}
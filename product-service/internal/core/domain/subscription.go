@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventProductBackInStock is the type of the event published when a product, or one of its variants, is back in stock
+const EventProductBackInStock = "product.back_in_stock"
+
+// StockSubscription is the request of a user to be alerted when a product, or one of its variants, is back in stock.
+// A subscription is removed once its alert is sent
+type StockSubscription struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	ProductID primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id"`
+	UserID    primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	Email     string              `json:"email" bson:"email"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// SubscribeRequest is the request to subscribe to, or unsubscribe from, the back-in-stock alert of a product.
+// Leaving the variant out of a product with variants alerts when any of them is back in stock
+type SubscribeRequest struct {
+	VariantID string `json:"variant_id" validate:"omitempty,mongodb"`
+}
+
+// BackInStockEvent is published when a product, or one of its variants, is back in stock, with the
+// subscribers to alert
+type BackInStockEvent struct {
+	Event       string                  `json:"event"`
+	ProductID   string                  `json:"product_id"`
+	ProductName string                  `json:"product_name"`
+	VariantID   string                  `json:"variant_id,omitempty"`
+	SKU         string                  `json:"sku,omitempty"`
+	Quantity    int32                   `json:"quantity"`
+	Subscribers []BackInStockSubscriber `json:"subscribers"`
+	OccurredAt  time.Time               `json:"occurred_at"`
+}
+
+// BackInStockSubscriber is a user to alert of a back-in-stock event
+type BackInStockSubscriber struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TransferFormat is a file format products are imported from and exported to
+type TransferFormat string
+
+const (
+	TransferFormatCSV    TransferFormat = "csv"
+	TransferFormatNDJSON TransferFormat = "ndjson"
+)
+
+var StringToTransferFormat = map[string]TransferFormat{
+	"csv":    TransferFormatCSV,
+	"ndjson": TransferFormatNDJSON,
+}
+
+// ContentType returns the media type of files in the format
+func (f TransferFormat) ContentType() string {
+	if f == TransferFormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+// TransferColumns are the columns of a CSV import or export, in export order. Only name, description
+// and price are required in an import. Category ids are separated by "|"
+var TransferColumns = []string{"id", "sku", "name", "description", "price", "quantity", "status", "category_ids"}
+
+const (
+	// MaxImportSize is the maximum size of an import file in bytes
+	MaxImportSize int64 = 10 << 20
+	// MaxImportRows is the maximum number of products in an import file
+	MaxImportRows = 10000
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobStatusPending   ImportJobStatus = "pending"
+	ImportJobStatusRunning   ImportJobStatus = "running"
+	ImportJobStatusCompleted ImportJobStatus = "completed"
+	ImportJobStatusFailed    ImportJobStatus = "failed"
+)
+
+// ImportJob tracks a bulk import running in the background
+type ImportJob struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Format    TransferFormat     `json:"format" bson:"format"`
+	Status    ImportJobStatus    `json:"status" bson:"status"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	// Total is the number of rows in the file, Processed how many of them have been imported so far
+	Total     int `json:"total" bson:"total"`
+	Processed int `json:"processed" bson:"processed"`
+	Created   int `json:"created" bson:"created"`
+	Updated   int `json:"updated" bson:"updated"`
+	Unchanged int `json:"unchanged" bson:"unchanged"`
+	Failed    int `json:"failed" bson:"failed"`
+	// Errors lists the rows that could not be imported
+	Errors []ImportRowError `json:"errors" bson:"errors"`
+	// Error is set when the job stopped before all rows were processed
+	Error      string     `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" bson:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// ImportRowError is the reason a row of an import failed. Rows are numbered from 1, not counting the CSV header
+type ImportRowError struct {
+	Row     int    `json:"row" bson:"row"`
+	ID      string `json:"id,omitempty" bson:"id,omitempty"`
+	SKU     string `json:"sku,omitempty" bson:"sku,omitempty"`
+	Message string `json:"message" bson:"message"`
+}
+
+// ImportRow is a product in an import file. A row updates the product with its id, or else the product
+// whose SKU, or the SKU of one of whose variants, matches its SKU. Other rows create a new product.
+// Category ids and variants are kept on update when left out, and variants can only be given in NDJSON.
+// The quantity of an existing variant sets its stock through a stock count, like the quantity of a product
+type ImportRow struct {
+	ID          string `json:"id" validate:"omitempty,mongodb"`
+	SKU         string `json:"sku"`
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description" validate:"required"`
+	// Price is required for a new product and kept on update when left out
+	Price *float64 `json:"price" validate:"omitempty,gte=0"`
+	// Quantity sets the stock of a product without variants through a stock count. It is kept on update when left out
+	Quantity *int32 `json:"quantity" validate:"omitempty,gte=0"`
+	// Status is active or inactive. As the stock decides whether an active product is out of stock,
+	// out_of_stock is read as active
+	Status      string            `json:"status" validate:"omitempty,oneof=active inactive out_of_stock"`
+	CategoryIDs *[]string         `json:"category_ids" validate:"omitempty,dive,mongodb"`
+	Variants    *[]VariantRequest `json:"variants" validate:"omitempty,dive"`
+}
+
+// ExportRow is a product in an export file, in the format an import reads
+type ExportRow struct {
+	ID          string           `json:"id"`
+	SKU         string           `json:"sku,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       float64          `json:"price"`
+	Quantity    int32            `json:"quantity"`
+	Status      string           `json:"status"`
+	CategoryIDs []string         `json:"category_ids"`
+	Variants    []VariantRequest `json:"variants,omitempty"`
+}
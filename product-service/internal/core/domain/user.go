@@ -2,12 +2,12 @@ package domain
 
 type UserRole int32
 
-type UserUpdateForQueue struct {
+// UserProfile is the public view of a user published by owner-service. It never carries credentials
+type UserProfile struct {
 	Id        string   `json:"id,omitempty"`
 	FirstName string   `json:"first_name,omitempty"`
 	LastName  string   `json:"last_name,omitempty"`
 	Email     string   `json:"email,omitempty"`
-	Password  string   `json:"password,omitempty"`
 	Phone     string   `json:"phone,omitempty"`
 	Role      UserRole `json:"role,omitempty"`
 	IsActive  bool     `json:"is_active,omitempty"`
@@ -15,3 +15,9 @@ type UserUpdateForQueue struct {
 	UpdatedAt string   `json:"updated_at,omitempty"`
 	DeletedAt string   `json:"deleted_at,omitempty"`
 }
+
+// UserUpdatedEvent is a message received from the "user-updates.v2" queue
+type UserUpdatedEvent struct {
+	SchemaVersion int32        `json:"schema_version,omitempty"`
+	User          *UserProfile `json:"user,omitempty"`
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxWishlists is the largest number of wishlists a user may have
+	MaxWishlists = 20
+	// MaxWishlistItems is the largest number of items a wishlist may hold
+	MaxWishlistItems = 100
+)
+
+// Wishlist is a named list of products a user saved without ordering them
+type Wishlist struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name   string             `json:"name" bson:"name"`
+	Items  []WishlistItem     `json:"items" bson:"items"`
+	// ShareToken is set while the wishlist is shared, and anyone with it can view the wishlist
+	ShareToken string    `json:"share_token,omitempty" bson:"share_token,omitempty"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// WishlistItem is a product, or one of its variants, saved in a wishlist
+type WishlistItem struct {
+	ProductID primitive.ObjectID  `json:"product_id" bson:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty" bson:"variant_id,omitempty"`
+	Quantity  int32               `json:"quantity" bson:"quantity"`
+	AddedAt   time.Time           `json:"added_at" bson:"added_at"`
+}
+
+// WishlistRequest names a wishlist
+type WishlistRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// WishlistItemRequest saves a product in a wishlist. Saving a product that is already in the wishlist
+// changes its quantity
+type WishlistItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,mongodb"`
+	// VariantID is required for products that have variants
+	VariantID string `json:"variant_id" validate:"omitempty,mongodb"`
+	Quantity  int32  `json:"quantity" validate:"omitempty,gte=1,lte=1000"`
+}
+
+// WishlistView is a wishlist with the live price and stock of its items. The share token is only shown to the owner
+type WishlistView struct {
+	ID         primitive.ObjectID `json:"id"`
+	Name       string             `json:"name"`
+	Items      []WishlistItemView `json:"items"`
+	ShareToken string             `json:"share_token,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// WishlistItemView is an item of a wishlist as the product is now. A product that was deleted or
+// made inactive is no longer available, and only its id is known
+type WishlistItemView struct {
+	ProductID primitive.ObjectID  `json:"product_id"`
+	VariantID *primitive.ObjectID `json:"variant_id,omitempty"`
+	Quantity  int32               `json:"quantity"`
+	AddedAt   time.Time           `json:"added_at"`
+	Name      string              `json:"name,omitempty"`
+	SKU       string              `json:"sku,omitempty"`
+	Price     float64             `json:"price"`
+	InStock   int32               `json:"in_stock"`
+	Available bool                `json:"available"`
+	// Reason tells why an item is not available
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrderRequest is the body of an order placed with the order-service
+type OrderRequest struct {
+	Products []OrderRequestLine `json:"products"`
+}
+
+// OrderRequestLine is a product, or one of its variants, in an order placed with the order-service
+type OrderRequestLine struct {
+	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// WishlistOrder is the order built from a wishlist, to be placed with the order-service, and the
+// items that were left out of it because they cannot be ordered
+type WishlistOrder struct {
+	Order   OrderRequest       `json:"order"`
+	Skipped []WishlistItemView `json:"skipped"`
+}
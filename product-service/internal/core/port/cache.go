@@ -11,6 +11,10 @@ type CacheRepository interface {
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	// Get retrieves the value from the cache
 	Get(ctx context.Context, key string) ([]byte, error)
+	// MGet retrieves multiple values from the cache, with nil for the keys that are not found
+	MGet(ctx context.Context, keys []string) ([][]byte, error)
+	// Incr increments the counter stored at the key and keeps it for ttl from now
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
 	// Delete removes the value from the cache
 	Delete(ctx context.Context, key string) error
 	// DeleteByPrefix removes the value from the cache with the given prefix
@@ -0,0 +1,45 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryRepository is an interface for interacting with category-related data
+type CategoryRepository interface {
+	// CreateCategory inserts a new category under parent, or at the root when parent is nil
+	CreateCategory(ctx context.Context, category *domain.Category, parent *domain.Category) (*domain.Category, domain.CError)
+	// GetCategoryByID fetches a category specified by its id
+	GetCategoryByID(ctx context.Context, id primitive.ObjectID) (*domain.Category, domain.CError)
+	// GetCategoriesByIDs fetches all categories that correspond to a list of category ids
+	GetCategoriesByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Category, domain.CError)
+	// ListCategories fetches all categories ordered so that parents come before their children
+	ListCategories(ctx context.Context) ([]domain.Category, domain.CError)
+	// ListSubtree fetches a category and all of its descendants
+	ListSubtree(ctx context.Context, category *domain.Category) ([]domain.Category, domain.CError)
+	// RenameCategory changes the name of a category and returns the updated category
+	RenameCategory(ctx context.Context, id primitive.ObjectID, name string) (*domain.Category, domain.CError)
+	// MoveCategory moves a category and its subtree under a new parent, or to the root when parent is nil
+	MoveCategory(ctx context.Context, category *domain.Category, parent *domain.Category) (*domain.Category, domain.CError)
+	// DeleteCategory deletes a category, moving its children and products up to its parent
+	DeleteCategory(ctx context.Context, category *domain.Category) domain.CError
+}
+
+// CategoryService is an interface for interacting with category-related business logic
+type CategoryService interface {
+	// CreateCategory creates a new category at the root or under a parent
+	CreateCategory(ctx context.Context, req *domain.CreateCategoryRequest) (*domain.Category, domain.CError)
+	// GetCategory returns a category with its breadcrumbs
+	GetCategory(ctx context.Context, id primitive.ObjectID) (*domain.Category, domain.CError)
+	// ListCategories returns the whole category tree, parents before their children
+	ListCategories(ctx context.Context) ([]domain.Category, domain.CError)
+	// RenameCategory changes the name of a category
+	RenameCategory(ctx context.Context, id primitive.ObjectID, req *domain.RenameCategoryRequest) (*domain.Category, domain.CError)
+	// MoveCategory moves a category and its subtree under a new parent
+	MoveCategory(ctx context.Context, id primitive.ObjectID, req *domain.MoveCategoryRequest) (*domain.Category, domain.CError)
+	// DeleteCategory deletes a category and reparents its children and products
+	DeleteCategory(ctx context.Context, id primitive.ObjectID) domain.CError
+}
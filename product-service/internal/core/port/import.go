@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportJobRepository is an interface for interacting with the bulk import jobs
+type ImportJobRepository interface {
+	// CreateImportJob creates a new import job
+	CreateImportJob(ctx context.Context, job *domain.ImportJob) (*domain.ImportJob, domain.CError)
+	// UpdateImportJob saves the progress of an import job
+	UpdateImportJob(ctx context.Context, job *domain.ImportJob) domain.CError
+	// GetImportJob fetches an import job specified by its id
+	GetImportJob(ctx context.Context, id primitive.ObjectID) (*domain.ImportJob, domain.CError)
+}
@@ -0,0 +1,47 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InventoryRepository is an interface for interacting with the inventory ledger
+type InventoryRepository interface {
+	// CreateMovement appends a movement to the ledger
+	CreateMovement(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, domain.CError)
+	// ListMovements fetches the movements of a product from the newest, starting before the movement with the before id when it is set
+	ListMovements(ctx context.Context, productID primitive.ObjectID, before *primitive.ObjectID, limit int64) ([]domain.StockMovement, domain.CError)
+	// ListMovementsByReference fetches the movements of a type made for a reference, such as the reservations of an order
+	ListMovementsByReference(ctx context.Context, referenceID string, movementType domain.MovementType) ([]domain.StockMovement, domain.CError)
+	// GetBalance sums the movements of a product
+	GetBalance(ctx context.Context, productID primitive.ObjectID) (*domain.LedgerBalance, domain.CError)
+	// CreateSubscription subscribes a user to the back-in-stock alert of a product or variant
+	CreateSubscription(ctx context.Context, subscription *domain.StockSubscription) (*domain.StockSubscription, domain.CError)
+	// DeleteSubscription removes the subscription of a user to the back-in-stock alert of a product or variant
+	DeleteSubscription(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID, userID primitive.ObjectID) domain.CError
+	// ListSubscriptions fetches the subscriptions to the back-in-stock alert of a product, or of one of its variants when variantID is set
+	ListSubscriptions(ctx context.Context, productID primitive.ObjectID, variantID *primitive.ObjectID) ([]domain.StockSubscription, domain.CError)
+	// DeleteSubscriptionsByIDs removes subscriptions whose alert was sent
+	DeleteSubscriptionsByIDs(ctx context.Context, ids []primitive.ObjectID) domain.CError
+}
+
+// InventoryService is an interface for interacting with stock-related business logic
+type InventoryService interface {
+	// AdjustStock changes the stock of a product, or of one of its variants, and records the movement
+	AdjustStock(ctx context.Context, id, actorID primitive.ObjectID, req *domain.AdjustStockRequest) (*domain.Product, domain.CError)
+	// AdjustOwnStock changes the stock of a product owned by the user, or of one of its variants, and records the movement
+	AdjustOwnStock(ctx context.Context, userID, id primitive.ObjectID, req *domain.AdjustStockRequest) (*domain.Product, domain.CError)
+	// ListMovements returns a page of the movements of a product and checks its stock against the ledger
+	ListMovements(ctx context.Context, id primitive.ObjectID, before *primitive.ObjectID, limit int64) (*domain.StockLedger, domain.CError)
+	// ReserveStock takes the stock of every line for an order. Either all lines are reserved or none is
+	ReserveStock(ctx context.Context, orderID string, userID primitive.ObjectID, lines []domain.StockLine) domain.CError
+	// ReleaseStock gives back the stock reserved for an order
+	ReleaseStock(ctx context.Context, orderID string, userID primitive.ObjectID) domain.CError
+	// SubscribeToStock subscribes the user to the alert sent when a product, or one of its variants, is back in stock
+	SubscribeToStock(ctx context.Context, id, userID primitive.ObjectID, email string, req *domain.SubscribeRequest) (*domain.StockSubscription, domain.CError)
+	// UnsubscribeFromStock removes the subscription of the user to the back-in-stock alert of a product or variant
+	UnsubscribeFromStock(ctx context.Context, id, userID primitive.ObjectID, req *domain.SubscribeRequest) domain.CError
+}
@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+)
+
+// MediaStore is an interface for storing media files such as product images
+type MediaStore interface {
+	// Put stores the file under the key and returns its public url
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+	// Delete removes the file stored under the key
+	Delete(ctx context.Context, key string) error
+}
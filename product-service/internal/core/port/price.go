@@ -0,0 +1,28 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceRepository is an interface for interacting with price schedules and the price history of products
+type PriceRepository interface {
+	// CreatePriceSchedule inserts a new price schedule into the database
+	CreatePriceSchedule(ctx context.Context, schedule *domain.PriceSchedule) (*domain.PriceSchedule, domain.CError)
+	// GetPriceSchedule fetches a price schedule of a product specified by its id
+	GetPriceSchedule(ctx context.Context, productID, id primitive.ObjectID) (*domain.PriceSchedule, domain.CError)
+	// ListPriceSchedules fetches the price schedules of a product by their start, optionally only those in the given statuses
+	ListPriceSchedules(ctx context.Context, productID primitive.ObjectID, statuses ...domain.PriceScheduleStatus) ([]domain.PriceSchedule, domain.CError)
+	// ListDuePriceSchedules fetches the scheduled price schedules that have started and the active ones that have ended by now
+	ListDuePriceSchedules(ctx context.Context, now time.Time) ([]domain.PriceSchedule, domain.CError)
+	// UpdatePriceSchedule saves the status, previous price and note of a price schedule that is still in the given status
+	UpdatePriceSchedule(ctx context.Context, schedule *domain.PriceSchedule, from domain.PriceScheduleStatus) domain.CError
+	// CreatePriceChange records a change in the price history of a product
+	CreatePriceChange(ctx context.Context, change *domain.PriceChange) (*domain.PriceChange, domain.CError)
+	// ListPriceChanges fetches the price history of a product from the newest
+	ListPriceChanges(ctx context.Context, productID primitive.ObjectID) ([]domain.PriceChange, domain.CError)
+}
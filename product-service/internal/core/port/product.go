@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"io"
 
 	"product-service/internal/core/domain"
 
@@ -14,16 +15,42 @@ type ProductRepository interface {
 	CreateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError)
 	// GetProductByID fetches a product specified by its id
 	GetProductByID(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError)
-	// ListProducts fetches all products in the database
-	ListProducts(ctx context.Context) ([]domain.Product, domain.CError)
-	// UpdateProduct updates a product and returns the updated product
+	// GetProductBySKU fetches the product with the SKU, or with a variant with the SKU
+	GetProductBySKU(ctx context.Context, sku string) (*domain.Product, domain.CError)
+	// ExportProducts iterates over every product that is not deleted
+	ExportProducts(ctx context.Context, fn func(*domain.Product) error) domain.CError
+	// ListProducts fetches a page of products matching the filter, plus one extra product when there is a next page
+	ListProducts(ctx context.Context, filter *domain.ProductFilter) ([]domain.Product, domain.CError)
+	// CountProductFacets counts the products matching a listing filter by tag and by attribute value
+	CountProductFacets(ctx context.Context, filter *domain.ProductFilter) (*domain.ProductFacets, domain.CError)
+	// ListProductsByOrganization fetches the products of an organization
+	ListProductsByOrganization(ctx context.Context, orgID primitive.ObjectID) ([]domain.Product, domain.CError)
+	// UpdateProduct updates a product at the version it was read with and returns the updated product
 	UpdateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError)
 	// DeleteProduct deletes a product specified by its id. It is a soft delete
 	DeleteProduct(ctx context.Context, id primitive.ObjectID) domain.CError
-	// GetProductsByIDs fetches all products that correspond to a list of product ids
+	// GetProductsByIDs fetches all active or out of stock products that correspond to a list of product ids
 	GetProductsByIDs(ctx context.Context, productIds []primitive.ObjectID) ([]domain.Product, domain.CError)
 	// UpdateProductOwner updates the owner of a product stored in the database. This is currently called from the rabbitmq consumer
-	UpdateProductOwner(ctx context.Context, owner *domain.UserUpdateForQueue) (int64, domain.CError)
+	UpdateProductOwner(ctx context.Context, owner *domain.UserProfile) (int64, domain.CError)
+	// AdjustStock changes the stock of a product, or of one of its variants, without letting it go below zero
+	AdjustStock(ctx context.Context, id primitive.ObjectID, variantID *primitive.ObjectID, delta int32, expected *int32) (*domain.Product, domain.CError)
+	// SyncStockStatus switches the status of a product between active and out of stock according to its stock, returning nil when it is unchanged
+	SyncStockStatus(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError)
+	// AddProductImage appends an image to a product that has fewer than the maximum number of images
+	AddProductImage(ctx context.Context, id primitive.ObjectID, image *domain.ProductImage) (*domain.Product, domain.CError)
+	// RemoveProductImage removes an image from a product
+	RemoveProductImage(ctx context.Context, id, imageID primitive.ObjectID) (*domain.Product, domain.CError)
+	// SetProductImages replaces the images of a product
+	SetProductImages(ctx context.Context, id primitive.ObjectID, images []domain.ProductImage) (*domain.Product, domain.CError)
+	// ReplaceCategory moves the products of a category to another one, or removes the category from them when to is nil
+	ReplaceCategory(ctx context.Context, from primitive.ObjectID, to *primitive.ObjectID) (int64, domain.CError)
+	// AdjustRating adds to the number of reviews and to the total of the ratings of a product, and updates its average rating
+	AdjustRating(ctx context.Context, id primitive.ObjectID, countDelta, totalDelta int64) domain.CError
+	// ListBundlesByComponent fetches the bundles that contain a product
+	ListBundlesByComponent(ctx context.Context, productID primitive.ObjectID) ([]domain.Product, domain.CError)
+	// SyncBundle saves the components, price and quantity of a bundle at the version it was read with
+	SyncBundle(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError)
 }
 
 // ProductService is an interface for interacting with product-related business logic
@@ -32,10 +59,63 @@ type ProductService interface {
 	CreateProduct(ctx context.Context, prod *domain.CreateProductRequest, userID primitive.ObjectID) (*domain.Product, domain.CError)
 	// GetProduct fetches a new product specified by its id
 	GetProduct(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError)
-	// ListProducts returns all products in the system
-	ListProducts(ctx context.Context) ([]domain.Product, domain.CError)
-	// UpdateProduct updates a products specified by its id
-	UpdateProduct(ctx context.Context, id primitive.ObjectID, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError)
+	// GetRecommendations returns the active products in stock most often bought together with a product
+	GetRecommendations(ctx context.Context, id primitive.ObjectID, limit int) ([]domain.Recommendation, domain.CError)
+	// ListProducts returns a page of products matching the filter
+	ListProducts(ctx context.Context, filter *domain.ProductFilter) (*domain.ProductPage, domain.CError)
+	// UpdateProduct updates a products specified by its id and records the revision made by the editor.
+	// When version is set, the update only applies if the product is still at that version
+	UpdateProduct(ctx context.Context, id, editorID primitive.ObjectID, version *int64, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError)
 	// DeleteProduct deletes a product in the system specified by its id
 	DeleteProduct(ctx context.Context, id primitive.ObjectID) domain.CError
+	// CreateOrganizationProduct creates a product managed by an organization the user has write permission in
+	CreateOrganizationProduct(ctx context.Context, orgID, userID primitive.ObjectID, prod *domain.CreateProductRequest) (*domain.Product, domain.CError)
+	// ListOrganizationProducts returns the products of an organization the user is a member of
+	ListOrganizationProducts(ctx context.Context, orgID, userID primitive.ObjectID) ([]domain.Product, domain.CError)
+	// UpdateOrganizationProduct updates a product of an organization the user has write permission in
+	UpdateOrganizationProduct(ctx context.Context, orgID, userID, id primitive.ObjectID, version *int64, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError)
+	// DeleteOrganizationProduct deletes a product of an organization the user has delete permission in
+	DeleteOrganizationProduct(ctx context.Context, orgID, userID, id primitive.ObjectID) domain.CError
+	// GetOwnProduct returns a product owned by the user
+	GetOwnProduct(ctx context.Context, userID, id primitive.ObjectID) (*domain.Product, domain.CError)
+	// UpdateOwnProduct updates a product owned by the user
+	UpdateOwnProduct(ctx context.Context, userID, id primitive.ObjectID, version *int64, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError)
+	// DeleteOwnProduct deletes a product owned by the user
+	DeleteOwnProduct(ctx context.Context, userID, id primitive.ObjectID) domain.CError
+	// AddProductImage stores an uploaded image and its thumbnail and appends it to the images of a product
+	AddProductImage(ctx context.Context, id primitive.ObjectID, upload *domain.ImageUpload) (*domain.Product, domain.CError)
+	// DeleteProductImage removes an image from a product and deletes its files
+	DeleteProductImage(ctx context.Context, id, imageID primitive.ObjectID) (*domain.Product, domain.CError)
+	// ReorderProductImages changes the display order of the images of a product
+	ReorderProductImages(ctx context.Context, id primitive.ObjectID, req *domain.ReorderImagesRequest) (*domain.Product, domain.CError)
+	// ImportProducts checks an import file and starts importing its products in the background
+	ImportProducts(ctx context.Context, userID primitive.ObjectID, format domain.TransferFormat, data []byte) (*domain.ImportJob, domain.CError)
+	// GetImportJob returns the progress of an import
+	GetImportJob(ctx context.Context, id primitive.ObjectID) (*domain.ImportJob, domain.CError)
+	// ListRevisions returns a page of the revisions of a product from the newest
+	ListRevisions(ctx context.Context, id primitive.ObjectID, before *primitive.ObjectID, limit int64) (*domain.RevisionPage, domain.CError)
+	// DiffRevisions returns the changes between two revisions of a product
+	DiffRevisions(ctx context.Context, id, fromID, toID primitive.ObjectID) (*domain.RevisionDiff, domain.CError)
+	// RevertProduct restores a product to one of its revisions, which records a new revision
+	RevertProduct(ctx context.Context, id, revisionID, editorID primitive.ObjectID, version *int64) (*domain.Product, domain.CError)
+	// SchedulePrice schedules a price change of a product, optionally ending at a set time
+	SchedulePrice(ctx context.Context, id, userID primitive.ObjectID, req *domain.SchedulePriceRequest) (*domain.PriceSchedule, domain.CError)
+	// ListPriceSchedules returns the price schedules of a product
+	ListPriceSchedules(ctx context.Context, id primitive.ObjectID) ([]domain.PriceSchedule, domain.CError)
+	// CancelPriceSchedule cancels a price schedule of a product that has not completed
+	CancelPriceSchedule(ctx context.Context, id, scheduleID, userID primitive.ObjectID) (*domain.PriceSchedule, domain.CError)
+	// GetPriceHistory returns every price of a product from the newest
+	GetPriceHistory(ctx context.Context, id primitive.ObjectID) ([]domain.PriceChange, domain.CError)
+	// CreateReview reviews a product the user received in a delivered order
+	CreateReview(ctx context.Context, id, userID primitive.ObjectID, req *domain.ReviewRequest) (*domain.Review, domain.CError)
+	// ListReviews returns a page of the reviews of a product from the newest
+	ListReviews(ctx context.Context, id primitive.ObjectID, includeHidden bool, before *primitive.ObjectID, limit int64) (*domain.ReviewPage, domain.CError)
+	// UpdateReview changes the rating and text of a review of the user
+	UpdateReview(ctx context.Context, id, reviewID, userID primitive.ObjectID, req *domain.ReviewRequest) (*domain.Review, domain.CError)
+	// DeleteReview deletes a review of the user
+	DeleteReview(ctx context.Context, id, reviewID, userID primitive.ObjectID) domain.CError
+	// ModerateReview hides a review, or publishes a hidden one again
+	ModerateReview(ctx context.Context, id, reviewID, adminID primitive.ObjectID, req *domain.ModerateReviewRequest) (*domain.Review, domain.CError)
+	// ExportProducts writes every product that is not deleted to w in the format
+	ExportProducts(ctx context.Context, format domain.TransferFormat, w io.Writer) domain.CError
 }
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+)
+
+// ProductFeedRepository is an interface for following the changes made to products
+type ProductFeedRepository interface {
+	// WatchProducts calls fn with every change made to products after the resume token, in order, until
+	// the context is cancelled or fn fails. Without a token it starts with the next change
+	WatchProducts(ctx context.Context, token string, fn func(*domain.ProductChange) error) domain.CError
+}
@@ -0,0 +1,23 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewRepository is an interface for interacting with the reviews of products
+type ReviewRepository interface {
+	// CreateReview inserts a review, failing with ErrConflictingData when the user already reviewed the product
+	CreateReview(ctx context.Context, review *domain.Review) (*domain.Review, domain.CError)
+	// GetReview fetches a review of a product specified by its id
+	GetReview(ctx context.Context, productID, id primitive.ObjectID) (*domain.Review, domain.CError)
+	// ListReviews fetches the reviews of a product older than before, from the newest. Hidden reviews are only included on request
+	ListReviews(ctx context.Context, productID primitive.ObjectID, includeHidden bool, before *primitive.ObjectID, limit int64) ([]domain.Review, domain.CError)
+	// UpdateReview saves a review as long as its rating and status are still those of previous, returning ErrDataNotFound otherwise
+	UpdateReview(ctx context.Context, review, previous *domain.Review) (*domain.Review, domain.CError)
+	// DeleteReview deletes a review as long as its rating and status are unchanged, returning ErrDataNotFound otherwise
+	DeleteReview(ctx context.Context, review *domain.Review) domain.CError
+}
@@ -0,0 +1,21 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RevisionRepository is an interface for interacting with the revision history of products
+type RevisionRepository interface {
+	// CreateRevision records a revision of a product
+	CreateRevision(ctx context.Context, revision *domain.ProductRevision) (*domain.ProductRevision, domain.CError)
+	// GetRevision fetches a revision of a product specified by its id
+	GetRevision(ctx context.Context, productID, id primitive.ObjectID) (*domain.ProductRevision, domain.CError)
+	// GetLatestRevision fetches the newest revision of a product
+	GetLatestRevision(ctx context.Context, productID primitive.ObjectID) (*domain.ProductRevision, domain.CError)
+	// ListRevisions fetches the revisions of a product older than before, from the newest
+	ListRevisions(ctx context.Context, productID primitive.ObjectID, before *primitive.ObjectID, limit int64) ([]domain.ProductRevision, domain.CError)
+}
@@ -0,0 +1,60 @@
+package port
+
+import (
+	"context"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WishlistRepository is an interface for interacting with the wishlists of users
+type WishlistRepository interface {
+	// CreateWishlist inserts a wishlist
+	CreateWishlist(ctx context.Context, wishlist *domain.Wishlist) (*domain.Wishlist, domain.CError)
+	// GetWishlist fetches a wishlist specified by its id
+	GetWishlist(ctx context.Context, id primitive.ObjectID) (*domain.Wishlist, domain.CError)
+	// GetWishlistByShareToken fetches the shared wishlist with the token
+	GetWishlistByShareToken(ctx context.Context, token string) (*domain.Wishlist, domain.CError)
+	// ListWishlists fetches the wishlists of a user from the oldest
+	ListWishlists(ctx context.Context, userID primitive.ObjectID) ([]domain.Wishlist, domain.CError)
+	// CountWishlists counts the wishlists of a user
+	CountWishlists(ctx context.Context, userID primitive.ObjectID) (int64, domain.CError)
+	// RenameWishlist changes the name of a wishlist
+	RenameWishlist(ctx context.Context, id primitive.ObjectID, name string) (*domain.Wishlist, domain.CError)
+	// DeleteWishlist deletes a wishlist
+	DeleteWishlist(ctx context.Context, id primitive.ObjectID) domain.CError
+	// SaveWishlistItem changes the quantity of an item of a wishlist, or appends the item when the
+	// wishlist does not hold it yet, failing with ErrWishlistFull when it holds the maximum number of items
+	SaveWishlistItem(ctx context.Context, id primitive.ObjectID, item *domain.WishlistItem) (*domain.Wishlist, domain.CError)
+	// RemoveWishlistItem removes a product, or one of its variants, from a wishlist
+	RemoveWishlistItem(ctx context.Context, id, productID primitive.ObjectID, variantID *primitive.ObjectID) (*domain.Wishlist, domain.CError)
+	// SetShareToken sets the share token of a wishlist, or removes it when the token is empty
+	SetShareToken(ctx context.Context, id primitive.ObjectID, token string) (*domain.Wishlist, domain.CError)
+}
+
+// WishlistService is an interface for interacting with wishlist-related business logic
+type WishlistService interface {
+	// CreateWishlist creates a wishlist for the user
+	CreateWishlist(ctx context.Context, userID primitive.ObjectID, req *domain.WishlistRequest) (*domain.WishlistView, domain.CError)
+	// ListWishlists returns the wishlists of the user
+	ListWishlists(ctx context.Context, userID primitive.ObjectID) ([]domain.WishlistView, domain.CError)
+	// GetWishlist returns a wishlist of the user with the live price and stock of its items
+	GetWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError)
+	// GetSharedWishlist returns the wishlist shared with the token
+	GetSharedWishlist(ctx context.Context, token string) (*domain.WishlistView, domain.CError)
+	// RenameWishlist changes the name of a wishlist of the user
+	RenameWishlist(ctx context.Context, userID, id primitive.ObjectID, req *domain.WishlistRequest) (*domain.WishlistView, domain.CError)
+	// DeleteWishlist deletes a wishlist of the user
+	DeleteWishlist(ctx context.Context, userID, id primitive.ObjectID) domain.CError
+	// AddWishlistItem saves a product in a wishlist of the user
+	AddWishlistItem(ctx context.Context, userID, id primitive.ObjectID, req *domain.WishlistItemRequest) (*domain.WishlistView, domain.CError)
+	// RemoveWishlistItem removes a product from a wishlist of the user
+	RemoveWishlistItem(ctx context.Context, userID, id, productID primitive.ObjectID, variantID *primitive.ObjectID) (*domain.WishlistView, domain.CError)
+	// ShareWishlist creates the public link of a wishlist of the user, or returns the one it has
+	ShareWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError)
+	// UnshareWishlist removes the public link of a wishlist of the user
+	UnshareWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError)
+	// OrderWishlist builds the order of the available items of a wishlist of the user, to be placed with the order-service
+	OrderWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistOrder, domain.CError)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// bundleSyncAttempts bounds the attempts to save the derived terms of a bundle that keeps changing meanwhile
+const bundleSyncAttempts = 3
+
+var errBundlePrice = domain.NewBadRequestCError("a bundle with fixed pricing needs a price")
+
+// buildBundle turns a bundle request into the bundle of the product with the id, and derives the
+// price and quantity of the bundle. The id is nil for a product being created. The components must
+// belong to the owner of the bundle, or to its organization when it has one, since ordering the
+// bundle reserves their stock
+func (ps *ProductService) buildBundle(ctx context.Context, id, ownerID primitive.ObjectID, orgID *primitive.ObjectID, req *domain.BundleRequest, price float64) (*domain.Bundle, float64, int32, domain.CError) {
+	bundle := domain.Bundle{
+		Components:      make([]domain.BundleComponent, 0, len(req.Components)),
+		Pricing:         domain.BundlePricing(req.Pricing),
+		DiscountPercent: req.DiscountPercent,
+	}
+
+	if bundle.Pricing == domain.BundlePricingFixed {
+		if price <= 0 {
+			return nil, 0, 0, errBundlePrice
+		}
+		bundle.DiscountPercent = 0
+	}
+
+	type line struct {
+		productID primitive.ObjectID
+		variantID primitive.ObjectID
+	}
+	seen := make(map[line]bool, len(req.Components))
+
+	for _, c := range req.Components {
+		component := domain.BundleComponent{Quantity: c.Quantity}
+		component.ProductID, _ = primitive.ObjectIDFromHex(c.ProductID)
+		if component.ProductID == id {
+			return nil, 0, 0, domain.NewBadRequestCError("a bundle cannot contain itself")
+		}
+
+		l := line{productID: component.ProductID}
+		if c.VariantID != "" {
+			variantID, _ := primitive.ObjectIDFromHex(c.VariantID)
+			component.VariantID = &variantID
+			l.variantID = variantID
+		}
+		if seen[l] {
+			return nil, 0, 0, domain.NewBadRequestCError("duplicate bundle component: " + c.ProductID)
+		}
+		seen[l] = true
+
+		bundle.Components = append(bundle.Components, component)
+	}
+
+	components, cerr := ps.bundleComponents(ctx, &bundle)
+	if cerr != nil {
+		return nil, 0, 0, cerr
+	}
+
+	for _, c := range bundle.Components {
+		prod, ok := components[c.ProductID]
+		if !ok {
+			return nil, 0, 0, domain.NewBadRequestCError("bundle component does not exist or is inactive: " + c.ProductID.Hex())
+		}
+		if prod.Bundle != nil {
+			return nil, 0, 0, domain.NewBadRequestCError(fmt.Sprintf("'%s' is a bundle and cannot be a bundle component", prod.Name))
+		}
+		if !ownsComponent(prod, ownerID, orgID) {
+			return nil, 0, 0, domain.NewCError(http.StatusForbidden, fmt.Sprintf("'%s' belongs to another seller and cannot be a bundle component", prod.Name))
+		}
+		if cerr := checkVariant(prod, c.VariantID); cerr != nil {
+			return nil, 0, 0, cerr
+		}
+	}
+
+	resolved, price, quantity := resolveBundle(&bundle, price, components)
+	return resolved, price, quantity, nil
+}
+
+// ownsComponent reports whether a product can be a component of a bundle of the owner, or of the
+// organization when it is set
+func ownsComponent(prod *domain.Product, ownerID primitive.ObjectID, orgID *primitive.ObjectID) bool {
+	if orgID != nil {
+		return prod.OrganizationID != nil && *prod.OrganizationID == *orgID
+	}
+	return prod.OwnerID == ownerID
+}
+
+// bundleComponents fetches the products of the components of a bundle that are active or out of stock
+func (ps *ProductService) bundleComponents(ctx context.Context, bundle *domain.Bundle) (map[primitive.ObjectID]*domain.Product, domain.CError) {
+	ids := make([]primitive.ObjectID, 0, len(bundle.Components))
+	for _, c := range bundle.Components {
+		ids = append(ids, c.ProductID)
+	}
+
+	products, cerr := ps.repo.GetProductsByIDs(ctx, ids)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting bundle components", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	components := make(map[primitive.ObjectID]*domain.Product, len(products))
+	for i := range products {
+		components[products[i].ID] = &products[i]
+	}
+
+	return components, nil
+}
+
+// resolveBundle derives the terms of a bundle from its components: the name and SKU of each
+// component, the price with discount pricing, and the number of bundles the stock of the
+// components can make up. A component that is gone, inactive or without the variant makes the
+// bundle unavailable
+func resolveBundle(bundle *domain.Bundle, price float64, components map[primitive.ObjectID]*domain.Product) (*domain.Bundle, float64, int32) {
+	resolved := *bundle
+	resolved.Components = make([]domain.BundleComponent, 0, len(bundle.Components))
+
+	var total float64
+	quantity := int32(math.MaxInt32)
+
+	for _, c := range bundle.Components {
+		prod, ok := components[c.ProductID]
+		if !ok {
+			resolved.Components = append(resolved.Components, c)
+			quantity = 0
+			continue
+		}
+
+		c.Name = prod.Name
+		c.SKU = prod.SKU
+		unitPrice, stock := prod.Price, prod.Quantity
+		if c.VariantID != nil {
+			stock = 0
+			for _, v := range prod.Variants {
+				if v.ID == *c.VariantID {
+					c.SKU = v.SKU
+					unitPrice, stock = v.EffectivePrice(prod.Price), v.Quantity
+				}
+			}
+		}
+		resolved.Components = append(resolved.Components, c)
+
+		total += unitPrice * float64(c.Quantity)
+		quantity = min(quantity, max(stock, 0)/c.Quantity)
+	}
+
+	if resolved.Pricing == domain.BundlePricingDiscount {
+		price = math.Round(total*(100-resolved.DiscountPercent)) / 100
+	}
+
+	return &resolved, price, quantity
+}
+
+// refreshBundles derives again the terms of the bundles that contain a product that changed or was
+// deleted, and publishes the bundles that changed. Failures are only logged, so they do not fail
+// the change of the component
+func (ps *ProductService) refreshBundles(ctx context.Context, componentID primitive.ObjectID) {
+	log := logger.FromCtx(ctx)
+
+	bundles, cerr := ps.repo.ListBundlesByComponent(ctx, componentID)
+	if cerr != nil {
+		log.Error("Error listing the bundles of a product", zap.String("product_id", componentID.Hex()), zap.Error(cerr))
+		return
+	}
+
+	for i := range bundles {
+		if cerr := ps.refreshBundle(ctx, &bundles[i]); cerr != nil {
+			log.Error("Error refreshing bundle", zap.String("product_id", bundles[i].ID.Hex()), zap.Error(cerr))
+		}
+	}
+}
+
+// refreshBundle saves the terms of a bundle derived from the current state of its components. A
+// bundle changed while its terms are derived is read again, so the terms saved last are never
+// derived from older components
+func (ps *ProductService) refreshBundle(ctx context.Context, bundle *domain.Product) domain.CError {
+	for attempt := 1; ; attempt++ {
+		components, cerr := ps.bundleComponents(ctx, bundle.Bundle)
+		if cerr != nil {
+			return cerr
+		}
+
+		resolved, price, quantity := resolveBundle(bundle.Bundle, bundle.Price, components)
+		if price == bundle.Price && quantity == bundle.Quantity && sameBundleComponents(resolved, bundle.Bundle) {
+			return nil
+		}
+
+		before := *bundle
+		bundle.Bundle, bundle.Price, bundle.Quantity = resolved, price, quantity
+
+		updated, cerr := ps.repo.SyncBundle(ctx, bundle)
+		if cerr == domain.ErrVersionConflict && attempt < bundleSyncAttempts {
+			bundle, cerr = ps.repo.GetProductByID(ctx, bundle.ID)
+			if cerr != nil {
+				return cerr
+			}
+			continue
+		}
+		if cerr != nil {
+			return cerr
+		}
+
+		updated = ps.syncStockStatus(ctx, updated)
+		if updated.Price != before.Price {
+			ps.recordPriceChange(ctx, updated, &before.Price, nil, nil)
+		}
+		ps.publishProductUpdate(ctx, updated, false)
+
+		if before.Quantity <= 0 && updated.Quantity > 0 {
+			ps.alertBackInStock(ctx, updated, nil)
+		}
+
+		return nil
+	}
+}
+
+// sameBundle reports whether both bundles have the same pricing and components, leaving out the
+// names and SKUs copied from the components
+func sameBundle(a, b *domain.Bundle) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.Pricing != b.Pricing || a.DiscountPercent != b.DiscountPercent || len(a.Components) != len(b.Components) {
+		return false
+	}
+
+	for i := range a.Components {
+		x, y := a.Components[i], b.Components[i]
+		if x.ProductID != y.ProductID || x.Quantity != y.Quantity || !sameVariantID(x.VariantID, y.VariantID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameBundleComponents reports whether both bundles hold the same components with the same names and SKUs
+func sameBundleComponents(a, b *domain.Bundle) bool {
+	if !sameBundle(a, b) {
+		return false
+	}
+
+	for i := range a.Components {
+		if a.Components[i].Name != b.Components[i].Name || a.Components[i].SKU != b.Components[i].SKU {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameVariantID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// bundleRequestOf describes a bundle as a request, to save it again
+func bundleRequestOf(bundle *domain.Bundle) *domain.BundleRequest {
+	req := domain.BundleRequest{
+		Components:      make([]domain.BundleComponentRequest, 0, len(bundle.Components)),
+		Pricing:         string(bundle.Pricing),
+		DiscountPercent: bundle.DiscountPercent,
+	}
+
+	for _, c := range bundle.Components {
+		component := domain.BundleComponentRequest{ProductID: c.ProductID.Hex(), Quantity: c.Quantity}
+		if c.VariantID != nil {
+			component.VariantID = c.VariantID.Hex()
+		}
+		req.Components = append(req.Components, component)
+	}
+
+	return &req
+}
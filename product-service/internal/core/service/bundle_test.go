@@ -0,0 +1,134 @@
+package service
+
+import (
+	"testing"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveBundle(t *testing.T) {
+	mugID, shirtID, goneID := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	large := primitive.NewObjectID()
+	largePrice := 25.0
+
+	components := map[primitive.ObjectID]*domain.Product{
+		mugID: {ID: mugID, Name: "Mug", SKU: "MUG", Price: 10, Quantity: 9},
+		shirtID: {ID: shirtID, Name: "Shirt", Price: 20, Variants: []domain.Variant{
+			{ID: primitive.NewObjectID(), SKU: "SHIRT-S", Quantity: 50},
+			{ID: large, SKU: "SHIRT-L", Price: &largePrice, Quantity: 2},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		bundle       domain.Bundle
+		price        float64
+		wantPrice    float64
+		wantQuantity int32
+		wantSKUs     []string
+	}{
+		{
+			name: "fixed price keeps the price of the bundle",
+			bundle: domain.Bundle{Pricing: domain.BundlePricingFixed, Components: []domain.BundleComponent{
+				{ProductID: mugID, Quantity: 2},
+			}},
+			price:        15,
+			wantPrice:    15,
+			wantQuantity: 4,
+			wantSKUs:     []string{"MUG"},
+		},
+		{
+			name: "discount is taken off the total of the components",
+			bundle: domain.Bundle{Pricing: domain.BundlePricingDiscount, DiscountPercent: 10, Components: []domain.BundleComponent{
+				{ProductID: mugID, Quantity: 2},
+				{ProductID: shirtID, VariantID: &large, Quantity: 1},
+			}},
+			wantPrice:    40.5,
+			wantQuantity: 2,
+			wantSKUs:     []string{"MUG", "SHIRT-L"},
+		},
+		{
+			name: "the scarcest component limits the stock",
+			bundle: domain.Bundle{Pricing: domain.BundlePricingFixed, Components: []domain.BundleComponent{
+				{ProductID: mugID, Quantity: 3},
+				{ProductID: shirtID, VariantID: &large, Quantity: 3},
+			}},
+			price:        50,
+			wantPrice:    50,
+			wantQuantity: 0,
+			wantSKUs:     []string{"MUG", "SHIRT-L"},
+		},
+		{
+			name: "a missing variant leaves no stock",
+			bundle: domain.Bundle{Pricing: domain.BundlePricingFixed, Components: []domain.BundleComponent{
+				{ProductID: shirtID, VariantID: &mugID, Quantity: 1},
+			}},
+			price:        30,
+			wantPrice:    30,
+			wantQuantity: 0,
+			wantSKUs:     []string{""},
+		},
+		{
+			name: "a component that is gone leaves no stock",
+			bundle: domain.Bundle{Pricing: domain.BundlePricingFixed, Components: []domain.BundleComponent{
+				{ProductID: mugID, Quantity: 1},
+				{ProductID: goneID, Name: "Old name", Quantity: 1},
+			}},
+			price:        30,
+			wantPrice:    30,
+			wantQuantity: 0,
+			wantSKUs:     []string{"MUG", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, price, quantity := resolveBundle(&tt.bundle, tt.price, components)
+
+			if price != tt.wantPrice {
+				t.Errorf("resolveBundle() price = %v, want %v", price, tt.wantPrice)
+			}
+			if quantity != tt.wantQuantity {
+				t.Errorf("resolveBundle() quantity = %d, want %d", quantity, tt.wantQuantity)
+			}
+
+			if len(resolved.Components) != len(tt.wantSKUs) {
+				t.Fatalf("resolveBundle() components = %+v, want %d", resolved.Components, len(tt.wantSKUs))
+			}
+			for i, c := range resolved.Components {
+				if c.SKU != tt.wantSKUs[i] {
+					t.Errorf("component %d SKU = %q, want %q", i, c.SKU, tt.wantSKUs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOwnsComponent(t *testing.T) {
+	ownerID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+	orgID, otherOrgID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		prod    *domain.Product
+		ownerID primitive.ObjectID
+		orgID   *primitive.ObjectID
+		want    bool
+	}{
+		{name: "product of the owner", prod: &domain.Product{OwnerID: ownerID}, ownerID: ownerID, want: true},
+		{name: "product of another seller", prod: &domain.Product{OwnerID: otherID}, ownerID: ownerID, want: false},
+		{name: "product of the organization", prod: &domain.Product{OwnerID: otherID, OrganizationID: &orgID}, ownerID: ownerID, orgID: &orgID, want: true},
+		{name: "product of another organization", prod: &domain.Product{OwnerID: ownerID, OrganizationID: &otherOrgID}, ownerID: ownerID, orgID: &orgID, want: false},
+		{name: "own product outside the organization", prod: &domain.Product{OwnerID: ownerID}, ownerID: ownerID, orgID: &orgID, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ownsComponent(tt.prod, tt.ownerID, tt.orgID); got != tt.want {
+				t.Errorf("ownsComponent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
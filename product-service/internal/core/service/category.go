@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+/**
+ * CategoryService implements port.CategoryService interface
+ */
+type CategoryService struct {
+	repo        port.CategoryRepository
+	productRepo port.ProductRepository
+}
+
+// NewCategoryService creates a new category service instance
+func NewCategoryService(repo port.CategoryRepository, productRepo port.ProductRepository) *CategoryService {
+	return &CategoryService{
+		repo,
+		productRepo,
+	}
+}
+
+// getCategory fetches a category, hiding the details of unexpected errors
+func (cs *CategoryService) getCategory(ctx context.Context, id primitive.ObjectID) (*domain.Category, domain.CError) {
+	category, cerr := cs.repo.GetCategoryByID(ctx, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting category", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return category, nil
+}
+
+// getParent resolves the parent id of a create or move request. An empty id means the root
+func (cs *CategoryService) getParent(ctx context.Context, parentID string) (*domain.Category, domain.CError) {
+	if parentID == "" {
+		return nil, nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return nil, domain.NewBadRequestCError("Invalid parent category id")
+	}
+
+	parent, cerr := cs.getCategory(ctx, id)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.NewBadRequestCError("parent category does not exist")
+		}
+		return nil, cerr
+	}
+
+	return parent, nil
+}
+
+func (cs *CategoryService) CreateCategory(ctx context.Context, req *domain.CreateCategoryRequest) (*domain.Category, domain.CError) {
+	parent, cerr := cs.getParent(ctx, req.ParentID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	category, cerr := cs.repo.CreateCategory(ctx, &domain.Category{Name: strings.TrimSpace(req.Name)}, parent)
+	if cerr != nil {
+		if cerr.Code() == 409 {
+			return nil, domain.NewCError(cerr.Code(), "a category with this name already exists under the parent")
+		}
+
+		logger.FromCtx(ctx).Error("Error creating category", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return cs.withBreadcrumbs(ctx, category)
+}
+
+func (cs *CategoryService) GetCategory(ctx context.Context, id primitive.ObjectID) (*domain.Category, domain.CError) {
+	category, cerr := cs.getCategory(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	return cs.withBreadcrumbs(ctx, category)
+}
+
+func (cs *CategoryService) ListCategories(ctx context.Context) ([]domain.Category, domain.CError) {
+	categories, cerr := cs.repo.ListCategories(ctx)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing categories", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return categories, nil
+}
+
+func (cs *CategoryService) RenameCategory(ctx context.Context, id primitive.ObjectID, req *domain.RenameCategoryRequest) (*domain.Category, domain.CError) {
+	category, cerr := cs.repo.RenameCategory(ctx, id, strings.TrimSpace(req.Name))
+	if cerr != nil {
+		if cerr.Code() == 409 {
+			return nil, domain.NewCError(cerr.Code(), "a category with this name already exists under the parent")
+		}
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error renaming category", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return cs.withBreadcrumbs(ctx, category)
+}
+
+func (cs *CategoryService) MoveCategory(ctx context.Context, id primitive.ObjectID, req *domain.MoveCategoryRequest) (*domain.Category, domain.CError) {
+	category, cerr := cs.getCategory(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	parent, cerr := cs.getParent(ctx, req.ParentID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if parent != nil && category.IsAncestorOf(parent) {
+		return nil, domain.NewBadRequestCError("a category cannot be moved under itself or one of its descendants")
+	}
+
+	if (parent == nil && category.ParentID == nil) || (parent != nil && category.ParentID != nil && *category.ParentID == parent.ID) {
+		return nil, domain.NewBadRequestCError("category is already under this parent")
+	}
+
+	moved, cerr := cs.repo.MoveCategory(ctx, category, parent)
+	if cerr != nil {
+		if cerr.Code() == 409 {
+			return nil, domain.NewCError(cerr.Code(), "a category with this name already exists under the parent")
+		}
+
+		logger.FromCtx(ctx).Error("Error moving category", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return cs.withBreadcrumbs(ctx, moved)
+}
+
+func (cs *CategoryService) DeleteCategory(ctx context.Context, id primitive.ObjectID) domain.CError {
+	log := logger.FromCtx(ctx)
+	category, cerr := cs.getCategory(ctx, id)
+	if cerr != nil {
+		return cerr
+	}
+
+	// Products keep their place in the tree by moving up to the parent along with the children
+	moved, cerr := cs.productRepo.ReplaceCategory(ctx, category.ID, category.ParentID)
+	if cerr != nil {
+		log.Error("Error moving products out of category", zap.Error(cerr))
+		return domain.ErrInternal
+	}
+	log.Info("Moved products out of deleted category", zap.String("category_id", id.Hex()), zap.Int64("products", moved))
+
+	if cerr := cs.repo.DeleteCategory(ctx, category); cerr != nil {
+		if cerr.Code() == 500 {
+			log.Error("Error deleting category", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+		return cerr
+	}
+
+	return nil
+}
+
+func (cs *CategoryService) withBreadcrumbs(ctx context.Context, category *domain.Category) (*domain.Category, domain.CError) {
+	trails, cerr := categoryTrails(ctx, cs.repo, []primitive.ObjectID{category.ID})
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	category.Breadcrumbs = trails[category.ID]
+	return category, nil
+}
+
+// categoryTrails returns the breadcrumb trail of each of the categories. Ancestors are read
+// from the materialized paths, so every trail is resolved with two queries. Unknown ids are skipped
+func categoryTrails(ctx context.Context, repo port.CategoryRepository, ids []primitive.ObjectID) (map[primitive.ObjectID][]domain.Breadcrumb, domain.CError) {
+	trails := make(map[primitive.ObjectID][]domain.Breadcrumb, len(ids))
+	if len(ids) == 0 {
+		return trails, nil
+	}
+
+	categories, cerr := repo.GetCategoriesByIDs(ctx, ids)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting categories", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	var ancestorIDs []primitive.ObjectID
+	for i := range categories {
+		for _, id := range categories[i].PathIDs() {
+			if !seen[id] {
+				seen[id] = true
+				ancestorIDs = append(ancestorIDs, id)
+			}
+		}
+	}
+
+	ancestors, cerr := repo.GetCategoriesByIDs(ctx, ancestorIDs)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting category ancestors", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	names := make(map[primitive.ObjectID]string, len(ancestors))
+	for _, a := range ancestors {
+		names[a.ID] = a.Name
+	}
+
+	for i := range categories {
+		pathIDs := categories[i].PathIDs()
+		trail := make([]domain.Breadcrumb, 0, len(pathIDs))
+		for _, id := range pathIDs {
+			trail = append(trail, domain.Breadcrumb{ID: id, Name: names[id]})
+		}
+		trails[categories[i].ID] = trail
+	}
+
+	return trails, nil
+}
@@ -0,0 +1,635 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+var errBundleStock = domain.NewBadRequestCError("the stock of a bundle is held by its components, adjust the stock of the components instead")
+
+func (ps *ProductService) AdjustStock(ctx context.Context, id, actorID primitive.ObjectID, req *domain.AdjustStockRequest) (*domain.Product, domain.CError) {
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if retProd.Bundle != nil {
+		return nil, errBundleStock
+	}
+
+	variantID, current, cerr := stockOf(retProd, req.VariantID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	movement := domain.StockMovement{
+		ProductID:   id,
+		VariantID:   variantID,
+		Type:        domain.MovementType(req.Type),
+		Delta:       req.Delta,
+		Reason:      req.Reason,
+		ActorID:     &actorID,
+		ReferenceID: req.ReferenceID,
+	}
+
+	// A stock count only applies to the stock it was computed from, so concurrent changes are not lost
+	var expected *int32
+	switch movement.Type {
+	case domain.MovementStockCount:
+		movement.Delta = *req.Quantity - current
+		expected = &current
+	case domain.MovementReturn:
+		if req.Delta < 0 {
+			return nil, domain.NewBadRequestCError("a return must add stock")
+		}
+	}
+
+	productResponse, cerr := ps.applyMovement(ctx, &movement, expected)
+	if cerr != nil {
+		if cerr == domain.ErrInsufficientStock && expected != nil {
+			return nil, domain.NewCError(http.StatusConflict, "the stock changed during the count, please count again")
+		}
+		return nil, cerr
+	}
+
+	ps.publishProductUpdate(ctx, productResponse, false)
+	ps.refreshBundles(ctx, productResponse.ID)
+
+	if cerr := ps.attachBreadcrumbs(ctx, productResponse); cerr != nil {
+		return nil, cerr
+	}
+
+	return productResponse, nil
+}
+
+func (ps *ProductService) ListMovements(ctx context.Context, id primitive.ObjectID, before *primitive.ObjectID, limit int64) (*domain.StockLedger, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if limit < 1 {
+		limit = domain.DefaultPageSize
+	}
+	if limit > domain.MaxPageSize {
+		limit = domain.MaxPageSize
+	}
+
+	movements, cerr := ps.inventoryRepo.ListMovements(ctx, id, before, limit+1)
+	if cerr != nil {
+		log.Error("Error listing stock movements", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	balance, cerr := ps.inventoryRepo.GetBalance(ctx, id)
+	if cerr != nil {
+		log.Error("Error getting stock ledger balance", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	ledger := domain.StockLedger{
+		Movements:      movements,
+		Quantity:       retProd.Quantity,
+		LedgerQuantity: balance.Quantity,
+		InSync:         balance.Quantity == retProd.Quantity,
+	}
+
+	for _, v := range retProd.Variants {
+		if balance.Variants[v.ID] != v.Quantity {
+			ledger.InSync = false
+		}
+	}
+
+	if !ledger.InSync {
+		log.Warn("Stock of product does not match the inventory ledger",
+			zap.String("product_id", id.Hex()), zap.Int32("quantity", retProd.Quantity), zap.Int32("ledger_quantity", balance.Quantity))
+	}
+
+	if int64(len(movements)) > limit {
+		ledger.Movements = movements[:limit]
+		ledger.NextBefore = ledger.Movements[limit-1].ID.Hex()
+	}
+
+	return &ledger, nil
+}
+
+func (ps *ProductService) ReserveStock(ctx context.Context, orderID string, userID primitive.ObjectID, lines []domain.StockLine) domain.CError {
+	log := logger.FromCtx(ctx)
+
+	reserved, cerr := ps.orderStock(ctx, orderID)
+	if cerr != nil {
+		return cerr
+	}
+
+	// The order already holds its stock, so a retried reservation does nothing
+	if len(reserved) > 0 {
+		log.Info("Stock is already reserved for order", zap.String("order_id", orderID))
+		return nil
+	}
+
+	applied := make([]domain.StockMovement, 0, len(lines))
+	updated := make(map[primitive.ObjectID]*domain.Product)
+
+	for _, line := range lines {
+		retProd, cerr := ps.getProduct(ctx, line.ProductID)
+		if cerr == nil && line.Quantity < 1 {
+			cerr = domain.NewBadRequestCError("the quantity reserved for a product must be at least 1")
+		}
+
+		var movements []domain.StockMovement
+		if cerr == nil {
+			movements, cerr = reservationMovements(retProd, line, userID, orderID)
+		}
+
+		for i := 0; cerr == nil && i < len(movements); i++ {
+			var productResponse *domain.Product
+			productResponse, cerr = ps.applyMovement(ctx, &movements[i], nil)
+			if cerr == nil {
+				applied = append(applied, movements[i])
+				updated[productResponse.ID] = productResponse
+			}
+		}
+
+		if cerr != nil {
+			ps.releaseMovements(ctx, applied, userID, "reservation rolled back")
+			if cerr == domain.ErrInsufficientStock {
+				return domain.NewCError(http.StatusConflict, fmt.Sprintf("not enough stock of '%s' to reserve %d", retProd.Name, line.Quantity))
+			}
+			return cerr
+		}
+	}
+
+	for _, prod := range updated {
+		ps.publishProductUpdate(ctx, prod, false)
+		ps.refreshBundles(ctx, prod.ID)
+	}
+
+	return nil
+}
+
+// reservationMovements returns the movements reserving a line of an order. Inactive products, deleted
+// ones included, cannot be reserved. The stock of a bundle is held by its components, so a bundle is
+// reserved as the stock of each of its components
+func reservationMovements(prod *domain.Product, line domain.StockLine, userID primitive.ObjectID, orderID string) ([]domain.StockMovement, domain.CError) {
+	if prod.Status == domain.ProductStatusInactive {
+		return nil, domain.NewBadRequestCError(fmt.Sprintf("'%s' is not available", prod.Name))
+	}
+
+	if prod.Bundle == nil {
+		if cerr := checkVariant(prod, line.VariantID); cerr != nil {
+			return nil, cerr
+		}
+
+		return []domain.StockMovement{{
+			ProductID:   line.ProductID,
+			VariantID:   line.VariantID,
+			Type:        domain.MovementReservation,
+			Delta:       -line.Quantity,
+			Reason:      "reserved for order",
+			ActorID:     &userID,
+			ReferenceID: orderID,
+		}}, nil
+	}
+
+	if line.VariantID != nil {
+		return nil, domain.NewBadRequestCError(fmt.Sprintf("'%s' is a bundle and has no variants", prod.Name))
+	}
+
+	movements := make([]domain.StockMovement, 0, len(prod.Bundle.Components))
+	for _, c := range prod.Bundle.Components {
+		movements = append(movements, domain.StockMovement{
+			ProductID:   c.ProductID,
+			VariantID:   c.VariantID,
+			Type:        domain.MovementReservation,
+			Delta:       -line.Quantity * c.Quantity,
+			Reason:      fmt.Sprintf("reserved for order in bundle '%s'", prod.Name),
+			ActorID:     &userID,
+			ReferenceID: orderID,
+		})
+	}
+
+	return movements, nil
+}
+
+func (ps *ProductService) ReleaseStock(ctx context.Context, orderID string, userID primitive.ObjectID) domain.CError {
+	reserved, cerr := ps.orderStock(ctx, orderID)
+	if cerr != nil {
+		return cerr
+	}
+
+	ps.releaseMovements(ctx, reserved, userID, "order cancelled")
+	return nil
+}
+
+// orderStock returns the stock still held by an order, as the reservation movements not yet released
+func (ps *ProductService) orderStock(ctx context.Context, orderID string) ([]domain.StockMovement, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	reservations, cerr := ps.inventoryRepo.ListMovementsByReference(ctx, orderID, domain.MovementReservation)
+	if cerr != nil {
+		log.Error("Error listing order reservations", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	releases, cerr := ps.inventoryRepo.ListMovementsByReference(ctx, orderID, domain.MovementRelease)
+	if cerr != nil {
+		log.Error("Error listing order releases", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return heldStock(orderID, append(reservations, releases...)), nil
+}
+
+// heldStock nets the reservation and release movements of an order by product and variant, and
+// returns the stock still held as one movement for each, in the order it was first reserved
+func heldStock(orderID string, movements []domain.StockMovement) []domain.StockMovement {
+	type line struct {
+		productID primitive.ObjectID
+		variantID primitive.ObjectID
+	}
+
+	held := make(map[line]int32)
+	order := make([]line, 0, len(movements))
+	for _, m := range movements {
+		l := line{productID: m.ProductID}
+		if m.VariantID != nil {
+			l.variantID = *m.VariantID
+		}
+		if _, ok := held[l]; !ok {
+			order = append(order, l)
+		}
+		held[l] += m.Delta
+	}
+
+	stock := make([]domain.StockMovement, 0)
+	for _, l := range order {
+		if held[l] >= 0 {
+			continue
+		}
+
+		m := domain.StockMovement{ProductID: l.productID, Delta: held[l]}
+		if !l.variantID.IsZero() {
+			variantID := l.variantID
+			m.VariantID = &variantID
+		}
+		m.ReferenceID = orderID
+		stock = append(stock, m)
+	}
+
+	return stock
+}
+
+// releaseMovements gives back the stock taken by reservation movements. Products that were
+// deleted since are skipped, and other failures are logged so the remaining stock is still released
+func (ps *ProductService) releaseMovements(ctx context.Context, reservations []domain.StockMovement, userID primitive.ObjectID, reason string) {
+	log := logger.FromCtx(ctx)
+	updated := make(map[primitive.ObjectID]*domain.Product)
+
+	for _, r := range reservations {
+		movement := domain.StockMovement{
+			ProductID:   r.ProductID,
+			VariantID:   r.VariantID,
+			Type:        domain.MovementRelease,
+			Delta:       -r.Delta,
+			Reason:      reason,
+			ActorID:     &userID,
+			ReferenceID: r.ReferenceID,
+		}
+
+		productResponse, cerr := ps.applyMovement(ctx, &movement, nil)
+		if cerr != nil {
+			log.Error("Error releasing reserved stock", zap.String("product_id", r.ProductID.Hex()),
+				zap.String("reference_id", r.ReferenceID), zap.Error(cerr))
+			continue
+		}
+		updated[productResponse.ID] = productResponse
+	}
+
+	for _, prod := range updated {
+		ps.publishProductUpdate(ctx, prod, false)
+		ps.refreshBundles(ctx, prod.ID)
+	}
+}
+
+// applyMovement changes the stock by the movement and records it in the ledger. There are no
+// transactions, so the stock change is reverted when the movement cannot be recorded
+func (ps *ProductService) applyMovement(ctx context.Context, movement *domain.StockMovement, expected *int32) (*domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	productResponse, cerr := ps.repo.AdjustStock(ctx, movement.ProductID, movement.VariantID, movement.Delta, expected)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			log.Error("Error adjusting stock", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	movement.QuantityAfter = quantityAfter(productResponse, movement.VariantID)
+
+	if _, cerr := ps.inventoryRepo.CreateMovement(ctx, movement); cerr != nil {
+		log.Error("Error recording stock movement", zap.Error(cerr))
+
+		if _, cerr := ps.repo.AdjustStock(ctx, movement.ProductID, movement.VariantID, -movement.Delta, nil); cerr != nil {
+			log.Error("Error reverting stock change of unrecorded movement", zap.String("product_id", movement.ProductID.Hex()),
+				zap.Int32("delta", movement.Delta), zap.Error(cerr))
+		}
+		return nil, domain.ErrInternal
+	}
+
+	productResponse = ps.syncStockStatus(ctx, productResponse)
+
+	// Alert subscribers when the product, or the variant, had no stock before the movement
+	variantBefore := movement.QuantityAfter - movement.Delta
+	if productResponse.Quantity-movement.Delta <= 0 && productResponse.Quantity > 0 {
+		ps.alertBackInStock(ctx, productResponse, nil)
+	}
+	if movement.VariantID != nil && variantBefore <= 0 && movement.QuantityAfter > 0 {
+		ps.alertBackInStock(ctx, productResponse, movement.VariantID)
+	}
+
+	return productResponse, nil
+}
+
+// syncStockStatus switches the product to out of stock when it has no stock left, and back to active
+// when it is replenished. Inactive products keep their status. Failures are logged and leave the status as is
+func (ps *ProductService) syncStockStatus(ctx context.Context, prod *domain.Product) *domain.Product {
+	updated, cerr := ps.repo.SyncStockStatus(ctx, prod.ID)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error updating product stock status", zap.String("product_id", prod.ID.Hex()), zap.Error(cerr))
+		return prod
+	}
+
+	if updated == nil {
+		return prod
+	}
+
+	logger.FromCtx(ctx).Info("Updated product stock status", zap.String("product_id", prod.ID.Hex()), zap.String("status", updated.Status.String()))
+	return updated
+}
+
+// alertBackInStock publishes the back-in-stock event of a product, or of one of its variants, to its
+// subscribers and removes their subscriptions. Subscriptions are kept when publishing fails
+func (ps *ProductService) alertBackInStock(ctx context.Context, prod *domain.Product, variantID *primitive.ObjectID) {
+	log := logger.FromCtx(ctx)
+
+	if prod.Status != domain.ProductStatusActive {
+		return
+	}
+
+	subscriptions, cerr := ps.inventoryRepo.ListSubscriptions(ctx, prod.ID, variantID)
+	if cerr != nil {
+		log.Error("Error listing back-in-stock subscriptions", zap.Error(cerr))
+		return
+	}
+
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	event := domain.BackInStockEvent{
+		Event:       domain.EventProductBackInStock,
+		ProductID:   prod.ID.Hex(),
+		ProductName: prod.Name,
+		Quantity:    quantityAfter(prod, variantID),
+		Subscribers: make([]domain.BackInStockSubscriber, 0, len(subscriptions)),
+		OccurredAt:  time.Now(),
+	}
+
+	if variantID != nil {
+		event.VariantID = variantID.Hex()
+		for _, v := range prod.Variants {
+			if v.ID == *variantID {
+				event.SKU = v.SKU
+			}
+		}
+	}
+
+	// Subscribers are alerted at their current email, and those whose account is gone are dropped.
+	// The emails saved with the subscriptions are used when the owner-service cannot be reached
+	userIDs := make([]primitive.ObjectID, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		userIDs = append(userIDs, sub.UserID)
+	}
+	users, cerr := ps.GetUsers(ctx, userIDs)
+	if cerr != nil {
+		log.Warn("Could not fetch the subscribers, alerting them at their saved emails", zap.Error(cerr))
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		ids = append(ids, sub.ID)
+
+		email := sub.Email
+		if users != nil {
+			user, ok := users[sub.UserID.Hex()]
+			if !ok {
+				continue
+			}
+			email = user.Email
+		}
+		event.Subscribers = append(event.Subscribers, domain.BackInStockSubscriber{UserID: sub.UserID.Hex(), Email: email})
+	}
+
+	if len(event.Subscribers) == 0 {
+		if cerr := ps.inventoryRepo.DeleteSubscriptionsByIDs(ctx, ids); cerr != nil {
+			log.Error("Error removing back-in-stock subscriptions of deleted users", zap.Error(cerr))
+		}
+		return
+	}
+
+	msg, err := util.Serialize(event)
+	if err != nil {
+		log.Error("Error serializing back-in-stock event", zap.Error(err))
+		return
+	}
+
+	queue := "product-back-in-stock"
+	headers := map[string]any{
+		"event":                            domain.EventProductBackInStock,
+		string(domain.CorrelationIDCtxKey): ctx.Value(domain.CorrelationIDCtxKey),
+	}
+
+	log.Info("Publishing back-in-stock event to message queue", zap.String("queue", queue), zap.Int("subscribers", len(event.Subscribers)))
+	if err := ps.producer.Publish(ctx, queue, msg, headers); err != nil {
+		log.Error("Could not publish back-in-stock event to the queue", zap.Error(err))
+		return
+	}
+
+	if cerr := ps.inventoryRepo.DeleteSubscriptionsByIDs(ctx, ids); cerr != nil {
+		log.Error("Error removing alerted back-in-stock subscriptions", zap.Error(cerr))
+	}
+}
+
+func (ps *ProductService) SubscribeToStock(ctx context.Context, id, userID primitive.ObjectID, email string, req *domain.SubscribeRequest) (*domain.StockSubscription, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if retProd.Status == domain.ProductStatusInactive {
+		return nil, domain.ErrDataNotFound
+	}
+
+	variantID, cerr := subscriptionVariant(retProd, req.VariantID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if quantityAfter(retProd, variantID) > 0 {
+		return nil, domain.NewBadRequestCError("the product is in stock")
+	}
+
+	subscription, cerr := ps.inventoryRepo.CreateSubscription(ctx, &domain.StockSubscription{
+		ProductID: id,
+		VariantID: variantID,
+		UserID:    userID,
+		Email:     email,
+	})
+	if cerr != nil {
+		log.Error("Error creating back-in-stock subscription", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return subscription, nil
+}
+
+func (ps *ProductService) UnsubscribeFromStock(ctx context.Context, id, userID primitive.ObjectID, req *domain.SubscribeRequest) domain.CError {
+	var variantID *primitive.ObjectID
+	if req.VariantID != "" {
+		parsed, err := primitive.ObjectIDFromHex(req.VariantID)
+		if err != nil {
+			return domain.NewBadRequestCError("Invalid variant id")
+		}
+		variantID = &parsed
+	}
+
+	cerr := ps.inventoryRepo.DeleteSubscription(ctx, id, variantID, userID)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error deleting back-in-stock subscription", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+		return cerr
+	}
+
+	return nil
+}
+
+// subscriptionVariant parses the variant of a subscription. Unlike stock changes, a subscription to a
+// product with variants may leave the variant out to be alerted when any of them is back in stock
+func subscriptionVariant(prod *domain.Product, variantIDHex string) (*primitive.ObjectID, domain.CError) {
+	if variantIDHex == "" {
+		return nil, nil
+	}
+
+	variantID, _, cerr := stockOf(prod, variantIDHex)
+	return variantID, cerr
+}
+
+// recordMovements records movements for stock changes that were already applied to the product,
+// such as the opening stock of a new product. Failures are logged and show up as a ledger mismatch
+func (ps *ProductService) recordMovements(ctx context.Context, prod *domain.Product, movements []domain.StockMovement) {
+	for _, m := range movements {
+		m.ProductID = prod.ID
+		m.QuantityAfter = quantityAfter(prod, m.VariantID)
+
+		if _, cerr := ps.inventoryRepo.CreateMovement(ctx, &m); cerr != nil {
+			logger.FromCtx(ctx).Error("Error recording stock movement", zap.String("product_id", prod.ID.Hex()), zap.Error(cerr))
+		}
+	}
+}
+
+// openingMovements returns the movements recording the stock of a new product, or of the new variants of a product
+func openingMovements(quantity int32, variants []domain.Variant, actorID *primitive.ObjectID, reason string) []domain.StockMovement {
+	movements := make([]domain.StockMovement, 0, len(variants))
+
+	if len(variants) == 0 && quantity != 0 {
+		movements = append(movements, domain.StockMovement{
+			Type:    domain.MovementInitial,
+			Delta:   quantity,
+			Reason:  reason,
+			ActorID: actorID,
+		})
+	}
+
+	for _, v := range variants {
+		variantID := v.ID
+		movements = append(movements, domain.StockMovement{
+			VariantID: &variantID,
+			Type:      domain.MovementInitial,
+			Delta:     v.Quantity,
+			Reason:    reason,
+			ActorID:   actorID,
+		})
+	}
+
+	return movements
+}
+
+// stockOf returns the parsed variant id and the current stock of the product, or of its variant
+func stockOf(prod *domain.Product, variantIDHex string) (*primitive.ObjectID, int32, domain.CError) {
+	if variantIDHex == "" {
+		if cerr := checkVariant(prod, nil); cerr != nil {
+			return nil, 0, cerr
+		}
+		return nil, prod.Quantity, nil
+	}
+
+	variantID, err := primitive.ObjectIDFromHex(variantIDHex)
+	if err != nil {
+		return nil, 0, domain.NewBadRequestCError("Invalid variant id")
+	}
+
+	if cerr := checkVariant(prod, &variantID); cerr != nil {
+		return nil, 0, cerr
+	}
+
+	return &variantID, quantityAfter(prod, &variantID), nil
+}
+
+// checkVariant checks that the stock of a product with variants is addressed through one of its variants
+func checkVariant(prod *domain.Product, variantID *primitive.ObjectID) domain.CError {
+	if variantID == nil {
+		if len(prod.Variants) > 0 {
+			return domain.NewBadRequestCError(fmt.Sprintf("a variant must be specified for '%s'", prod.Name))
+		}
+		return nil
+	}
+
+	for _, v := range prod.Variants {
+		if v.ID == *variantID {
+			return nil
+		}
+	}
+
+	return domain.NewBadRequestCError(fmt.Sprintf("the variant '%s' was not found for '%s'", variantID.Hex(), prod.Name))
+}
+
+// quantityAfter returns the stock of the product, or of its variant when variantID is set
+func quantityAfter(prod *domain.Product, variantID *primitive.ObjectID) int32 {
+	if variantID == nil {
+		return prod.Quantity
+	}
+
+	for _, v := range prod.Variants {
+		if v.ID == *variantID {
+			return v.Quantity
+		}
+	}
+
+	return 0
+}
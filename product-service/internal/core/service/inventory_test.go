@@ -0,0 +1,229 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHeldStock(t *testing.T) {
+	productA, productB := primitive.NewObjectID(), primitive.NewObjectID()
+	variant := primitive.NewObjectID()
+
+	reserve := func(productID primitive.ObjectID, variantID *primitive.ObjectID, quantity int32) domain.StockMovement {
+		return domain.StockMovement{ProductID: productID, VariantID: variantID, Type: domain.MovementReservation, Delta: -quantity}
+	}
+	release := func(productID primitive.ObjectID, variantID *primitive.ObjectID, quantity int32) domain.StockMovement {
+		return domain.StockMovement{ProductID: productID, VariantID: variantID, Type: domain.MovementRelease, Delta: quantity}
+	}
+	held := func(productID primitive.ObjectID, variantID *primitive.ObjectID, quantity int32) domain.StockMovement {
+		return domain.StockMovement{ProductID: productID, VariantID: variantID, Delta: -quantity, ReferenceID: "order-1"}
+	}
+
+	tests := []struct {
+		name      string
+		movements []domain.StockMovement
+		want      []domain.StockMovement
+	}{
+		{
+			name:      "no movements",
+			movements: nil,
+			want:      []domain.StockMovement{},
+		},
+		{
+			name:      "reserved",
+			movements: []domain.StockMovement{reserve(productA, nil, 5)},
+			want:      []domain.StockMovement{held(productA, nil, 5)},
+		},
+		{
+			name:      "partially released",
+			movements: []domain.StockMovement{reserve(productA, nil, 5), release(productA, nil, 2)},
+			want:      []domain.StockMovement{held(productA, nil, 3)},
+		},
+		{
+			name:      "fully released",
+			movements: []domain.StockMovement{reserve(productA, nil, 5), release(productA, nil, 5)},
+			want:      []domain.StockMovement{},
+		},
+		{
+			name: "reserved again after a release",
+			movements: []domain.StockMovement{
+				reserve(productA, nil, 5),
+				release(productA, nil, 5),
+				reserve(productA, nil, 4),
+			},
+			want: []domain.StockMovement{held(productA, nil, 4)},
+		},
+		{
+			name: "variants are held apart from their product",
+			movements: []domain.StockMovement{
+				reserve(productA, &variant, 2),
+				reserve(productA, nil, 1),
+				release(productA, &variant, 1),
+			},
+			want: []domain.StockMovement{held(productA, &variant, 1), held(productA, nil, 1)},
+		},
+		{
+			name: "only the products still held are returned, in reservation order",
+			movements: []domain.StockMovement{
+				reserve(productB, nil, 3),
+				reserve(productA, nil, 2),
+				release(productB, nil, 3),
+			},
+			want: []domain.StockMovement{held(productA, nil, 2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := heldStock("order-1", tt.movements)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("heldStock() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReservationMovements(t *testing.T) {
+	userID := primitive.NewObjectID()
+	productID, componentA, componentB := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	variant, otherVariant := primitive.NewObjectID(), primitive.NewObjectID()
+
+	plain := &domain.Product{ID: productID, Name: "Mug", Quantity: 10}
+	inactive := &domain.Product{ID: productID, Name: "Mug", Quantity: 10, Status: domain.ProductStatusInactive}
+	withVariants := &domain.Product{ID: productID, Name: "Shirt", Variants: []domain.Variant{{ID: variant, Quantity: 4}}}
+	bundle := &domain.Product{ID: productID, Name: "Gift box", Bundle: &domain.Bundle{
+		Components: []domain.BundleComponent{
+			{ProductID: componentA, Quantity: 2},
+			{ProductID: componentB, VariantID: &variant, Quantity: 1},
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		prod      *domain.Product
+		line      domain.StockLine
+		wantDelta map[primitive.ObjectID]int32
+		wantErr   bool
+	}{
+		{
+			name:      "product without variants",
+			prod:      plain,
+			line:      domain.StockLine{ProductID: productID, Quantity: 3},
+			wantDelta: map[primitive.ObjectID]int32{productID: -3},
+		},
+		{
+			name:    "inactive product",
+			prod:    inactive,
+			line:    domain.StockLine{ProductID: productID, Quantity: 1},
+			wantErr: true,
+		},
+		{
+			name:    "product without variants reserved through a variant",
+			prod:    plain,
+			line:    domain.StockLine{ProductID: productID, VariantID: &variant, Quantity: 1},
+			wantErr: true,
+		},
+		{
+			name:      "variant of a product",
+			prod:      withVariants,
+			line:      domain.StockLine{ProductID: productID, VariantID: &variant, Quantity: 2},
+			wantDelta: map[primitive.ObjectID]int32{productID: -2},
+		},
+		{
+			name:    "product with variants reserved without a variant",
+			prod:    withVariants,
+			line:    domain.StockLine{ProductID: productID, Quantity: 1},
+			wantErr: true,
+		},
+		{
+			name:    "unknown variant",
+			prod:    withVariants,
+			line:    domain.StockLine{ProductID: productID, VariantID: &otherVariant, Quantity: 1},
+			wantErr: true,
+		},
+		{
+			name:      "bundle reserves each component for every bundle",
+			prod:      bundle,
+			line:      domain.StockLine{ProductID: productID, Quantity: 3},
+			wantDelta: map[primitive.ObjectID]int32{componentA: -6, componentB: -3},
+		},
+		{
+			name:    "bundle reserved through a variant",
+			prod:    bundle,
+			line:    domain.StockLine{ProductID: productID, VariantID: &variant, Quantity: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movements, cerr := reservationMovements(tt.prod, tt.line, userID, "order-1")
+			if tt.wantErr {
+				if cerr == nil {
+					t.Fatalf("reservationMovements() returned %+v, want an error", movements)
+				}
+				return
+			}
+			if cerr != nil {
+				t.Fatalf("reservationMovements() failed: %v", cerr)
+			}
+
+			got := make(map[primitive.ObjectID]int32, len(movements))
+			for _, m := range movements {
+				if m.Type != domain.MovementReservation || m.ReferenceID != "order-1" || m.ActorID == nil || *m.ActorID != userID {
+					t.Errorf("movement %+v is not a reservation for the order by the user", m)
+				}
+				got[m.ProductID] += m.Delta
+			}
+			if !reflect.DeepEqual(got, tt.wantDelta) {
+				t.Errorf("reservationMovements() deltas = %v, want %v", got, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestStockOf(t *testing.T) {
+	variant := primitive.NewObjectID()
+	plain := &domain.Product{Name: "Mug", Quantity: 10}
+	withVariants := &domain.Product{Name: "Shirt", Quantity: 7, Variants: []domain.Variant{
+		{ID: primitive.NewObjectID(), Quantity: 3},
+		{ID: variant, Quantity: 4},
+	}}
+
+	tests := []struct {
+		name        string
+		prod        *domain.Product
+		variantID   string
+		wantVariant *primitive.ObjectID
+		wantStock   int32
+		wantErr     bool
+	}{
+		{name: "product without variants", prod: plain, wantStock: 10},
+		{name: "variant", prod: withVariants, variantID: variant.Hex(), wantVariant: &variant, wantStock: 4},
+		{name: "product with variants without a variant", prod: withVariants, wantErr: true},
+		{name: "invalid variant id", prod: withVariants, variantID: "not-an-id", wantErr: true},
+		{name: "unknown variant", prod: withVariants, variantID: primitive.NewObjectID().Hex(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variantID, stock, cerr := stockOf(tt.prod, tt.variantID)
+			if tt.wantErr {
+				if cerr == nil {
+					t.Fatalf("stockOf() = %v, %d, want an error", variantID, stock)
+				}
+				return
+			}
+			if cerr != nil {
+				t.Fatalf("stockOf() failed: %v", cerr)
+			}
+			if !reflect.DeepEqual(variantID, tt.wantVariant) || stock != tt.wantStock {
+				t.Errorf("stockOf() = %v, %d, want %v, %d", variantID, stock, tt.wantVariant, tt.wantStock)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.1
+// source: order.proto
+
+package order
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Order_HasDeliveredProduct_FullMethodName      = "/order.Order/HasDeliveredProduct"
+	Order_FrequentlyBoughtTogether_FullMethodName = "/order.Order/FrequentlyBoughtTogether"
+)
+
+// OrderClient is the client API for Order service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrderClient interface {
+	// HasDeliveredProduct reports whether a user has a delivered order containing a product
+	HasDeliveredProduct(ctx context.Context, in *DeliveredProductRequest, opts ...grpc.CallOption) (*DeliveredProductResponse, error)
+	// FrequentlyBoughtTogether lists the products most often in the same delivered orders as a product
+	FrequentlyBoughtTogether(ctx context.Context, in *FrequentlyBoughtTogetherRequest, opts ...grpc.CallOption) (*FrequentlyBoughtTogetherResponse, error)
+}
+
+type orderClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderClient(cc grpc.ClientConnInterface) OrderClient {
+	return &orderClient{cc}
+}
+
+func (c *orderClient) HasDeliveredProduct(ctx context.Context, in *DeliveredProductRequest, opts ...grpc.CallOption) (*DeliveredProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeliveredProductResponse)
+	err := c.cc.Invoke(ctx, Order_HasDeliveredProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderClient) FrequentlyBoughtTogether(ctx context.Context, in *FrequentlyBoughtTogetherRequest, opts ...grpc.CallOption) (*FrequentlyBoughtTogetherResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FrequentlyBoughtTogetherResponse)
+	err := c.cc.Invoke(ctx, Order_FrequentlyBoughtTogether_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OrderServer is the server API for Order service.
+// All implementations must embed UnimplementedOrderServer
+// for forward compatibility.
+type OrderServer interface {
+	// HasDeliveredProduct reports whether a user has a delivered order containing a product
+	HasDeliveredProduct(context.Context, *DeliveredProductRequest) (*DeliveredProductResponse, error)
+	// FrequentlyBoughtTogether lists the products most often in the same delivered orders as a product
+	FrequentlyBoughtTogether(context.Context, *FrequentlyBoughtTogetherRequest) (*FrequentlyBoughtTogetherResponse, error)
+	mustEmbedUnimplementedOrderServer()
+}
+
+// UnimplementedOrderServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderServer struct{}
+
+func (UnimplementedOrderServer) HasDeliveredProduct(context.Context, *DeliveredProductRequest) (*DeliveredProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasDeliveredProduct not implemented")
+}
+func (UnimplementedOrderServer) FrequentlyBoughtTogether(context.Context, *FrequentlyBoughtTogetherRequest) (*FrequentlyBoughtTogetherResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FrequentlyBoughtTogether not implemented")
+}
+func (UnimplementedOrderServer) mustEmbedUnimplementedOrderServer() {}
+func (UnimplementedOrderServer) testEmbeddedByValue()               {}
+
+// UnsafeOrderServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderServer will
+// result in compilation errors.
+type UnsafeOrderServer interface {
+	mustEmbedUnimplementedOrderServer()
+}
+
+func RegisterOrderServer(s grpc.ServiceRegistrar, srv OrderServer) {
+	// If the following call pancis, it indicates UnimplementedOrderServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Order_ServiceDesc, srv)
+}
+
+func _Order_HasDeliveredProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeliveredProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServer).HasDeliveredProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Order_HasDeliveredProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServer).HasDeliveredProduct(ctx, req.(*DeliveredProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Order_FrequentlyBoughtTogether_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FrequentlyBoughtTogetherRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServer).FrequentlyBoughtTogether(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Order_FrequentlyBoughtTogether_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServer).FrequentlyBoughtTogether(ctx, req.(*FrequentlyBoughtTogetherRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Order_ServiceDesc is the grpc.ServiceDesc for Order service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Order_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "order.Order",
+	HandlerType: (*OrderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HasDeliveredProduct",
+			Handler:    _Order_HasDeliveredProduct_Handler,
+		},
+		{
+			MethodName: "FrequentlyBoughtTogether",
+			Handler:    _Order_FrequentlyBoughtTogether_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "order.proto",
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pkg/grpcauth"
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/service/order"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func newOrderClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, order.OrderClient, error) {
+	conn, err := grpc.NewClient(conf.OrderUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(grpcauth.Token(conf.OrderToken)),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a new connection: %w", err)
+	}
+
+	orderClient := order.NewOrderClient(conn)
+	return conn, orderClient, nil
+}
+
+// hasDeliveredProduct checks with the order-service that the user has a delivered order containing the product
+func (ps *ProductService) hasDeliveredProduct(ctx context.Context, userID, productID primitive.ObjectID) (bool, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	grpcConn, grpcClient, err := newOrderClient(&config.GetConfig().Discovery)
+	if err != nil {
+		log.Error("Error creating order client", zap.Error(err))
+		return false, domain.ErrInternal
+	}
+	defer grpcConn.Close()
+
+	resp, err := grpcClient.HasDeliveredProduct(ctx, &order.DeliveredProductRequest{
+		UserId:    userID.Hex(),
+		ProductId: productID.Hex(),
+	})
+	if err != nil {
+		log.Error("Error checking delivered orders", zap.Error(err))
+		return false, domain.ErrInternal
+	}
+
+	return resp.Delivered, nil
+}
+
+// frequentlyBoughtTogether asks the order-service for the products most often in the same delivered orders as the product
+func (ps *ProductService) frequentlyBoughtTogether(ctx context.Context, productID primitive.ObjectID, limit int32) ([]*order.CoPurchasedProduct, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	grpcConn, grpcClient, err := newOrderClient(&config.GetConfig().Discovery)
+	if err != nil {
+		log.Error("Error creating order client", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+	defer grpcConn.Close()
+
+	resp, err := grpcClient.FrequentlyBoughtTogether(ctx, &order.FrequentlyBoughtTogetherRequest{
+		ProductId: productID.Hex(),
+		Limit:     limit,
+	})
+	if err != nil {
+		log.Error("Error getting products bought together", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	return resp.Products, nil
+}
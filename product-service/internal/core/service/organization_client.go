@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"pkg/grpcauth"
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	userv2 "product-service/internal/core/service/user/v2"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func newOrganizationClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, userv2.OrganizationClient, error) {
+	conn, err := grpc.NewClient(conf.OwnerUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(grpcauth.Token(conf.OwnerToken)),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create a new connection: %w", err)
+	}
+
+	orgClient := userv2.NewOrganizationClient(conn)
+	return conn, orgClient, nil
+}
+
+// authorizeMember checks with the owner-service that the user is a member of the organization
+// and that their role grants the permission. An empty permission only requires membership.
+// Memberships are not cached so that removed members lose access immediately
+func (ps *ProductService) authorizeMember(ctx context.Context, orgID, userID primitive.ObjectID, permission string) domain.CError {
+	log := logger.FromCtx(ctx)
+
+	grpcConn, grpcClient, err := newOrganizationClient(&config.GetConfig().Discovery)
+	if err != nil {
+		log.Error("Error creating organization client", zap.Error(err))
+		return domain.ErrInternal
+	}
+	defer grpcConn.Close()
+
+	membership, err := grpcClient.GetMembership(ctx, &userv2.GetMembershipRequest{
+		OrganizationId: orgID.Hex(),
+		UserId:         userID.Hex(),
+	})
+	if err != nil {
+		log.Error("Error fetching membership", zap.Error(err))
+		return domain.ErrInternal
+	}
+
+	if !membership.IsMember {
+		return domain.ErrForbidden
+	}
+
+	if permission != "" && !slices.Contains(membership.Permissions, permission) {
+		log.Info("Member lacks permission",
+			zap.String("organization_id", orgID.Hex()),
+			zap.String("role", userv2.OrganizationRole_name[int32(membership.Role)]),
+			zap.String("permission", permission),
+		)
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (ps *ProductService) GetOwnProduct(ctx context.Context, userID, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	retProd, cerr := ps.ensureOwnProduct(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if cerr := ps.attachBreadcrumbs(ctx, retProd); cerr != nil {
+		return nil, cerr
+	}
+
+	return retProd, nil
+}
+
+func (ps *ProductService) UpdateOwnProduct(ctx context.Context, userID, id primitive.ObjectID, version *int64, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError) {
+	if _, cerr := ps.ensureOwnMutableProduct(ctx, userID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	return ps.UpdateProduct(ctx, id, userID, version, prod)
+}
+
+func (ps *ProductService) DeleteOwnProduct(ctx context.Context, userID, id primitive.ObjectID) domain.CError {
+	if _, cerr := ps.ensureOwnMutableProduct(ctx, userID, id); cerr != nil {
+		return cerr
+	}
+
+	return ps.DeleteProduct(ctx, id)
+}
+
+func (ps *ProductService) AdjustOwnStock(ctx context.Context, userID, id primitive.ObjectID, req *domain.AdjustStockRequest) (*domain.Product, domain.CError) {
+	if _, cerr := ps.ensureOwnMutableProduct(ctx, userID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	return ps.AdjustStock(ctx, id, userID, req)
+}
+
+// ensureOwnProduct fetches a product owned by the user. Products of other owners are reported as not found
+func (ps *ProductService) ensureOwnProduct(ctx context.Context, userID, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if retProd.OwnerID != userID {
+		return nil, domain.ErrDataNotFound
+	}
+
+	return retProd, nil
+}
+
+// ensureOwnMutableProduct fetches a product the user owns and may change. Products managed by an
+// organization are changed through the organization, so the permissions of its members apply
+func (ps *ProductService) ensureOwnMutableProduct(ctx context.Context, userID, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	retProd, cerr := ps.ensureOwnProduct(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if retProd.OrganizationID != nil {
+		return nil, domain.NewCError(http.StatusForbidden, "the product is managed by an organization and is changed through it")
+	}
+
+	return retProd, nil
+}
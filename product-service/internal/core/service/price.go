@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func (ps *ProductService) SchedulePrice(ctx context.Context, id, userID primitive.ObjectID, req *domain.SchedulePriceRequest) (*domain.PriceSchedule, domain.CError) {
+	log := logger.FromCtx(ctx)
+	prod, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if prod.Bundle != nil && prod.Bundle.Pricing == domain.BundlePricingDiscount {
+		return nil, domain.NewBadRequestCError("the price of a bundle with discount pricing follows its components and cannot be scheduled")
+	}
+
+	if req.EndsAt != nil {
+		if !req.EndsAt.After(req.StartsAt) {
+			return nil, domain.NewBadRequestCError("ends_at must be after starts_at")
+		}
+		if !req.EndsAt.After(time.Now()) {
+			return nil, domain.NewBadRequestCError("ends_at must be in the future")
+		}
+	}
+
+	pending, cerr := ps.priceRepo.ListPriceSchedules(ctx, id, domain.PriceScheduleStatusScheduled, domain.PriceScheduleStatusActive)
+	if cerr != nil {
+		log.Error("Error listing price schedules", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	// A schedule cannot start while another one is active, so the price a schedule puts back when it
+	// ends is never one set by another schedule
+	for _, other := range pending {
+		if overlaps(req.StartsAt, req.EndsAt, other.StartsAt, other.EndsAt) {
+			return nil, domain.NewCError(http.StatusConflict, "the schedule overlaps the price schedule "+other.ID.Hex())
+		}
+	}
+
+	schedule, cerr := ps.priceRepo.CreatePriceSchedule(ctx, &domain.PriceSchedule{
+		ProductID: id,
+		Price:     req.Price,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    utcTime(req.EndsAt),
+		Status:    domain.PriceScheduleStatusScheduled,
+		CreatedBy: userID,
+	})
+	if cerr != nil {
+		log.Error("Error creating price schedule", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return schedule, nil
+}
+
+func (ps *ProductService) ListPriceSchedules(ctx context.Context, id primitive.ObjectID) ([]domain.PriceSchedule, domain.CError) {
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	schedules, cerr := ps.priceRepo.ListPriceSchedules(ctx, id)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing price schedules", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return schedules, nil
+}
+
+// CancelPriceSchedule cancels a schedule that has not completed. Cancelling an active schedule puts
+// the previous price back at once
+func (ps *ProductService) CancelPriceSchedule(ctx context.Context, id, scheduleID, userID primitive.ObjectID) (*domain.PriceSchedule, domain.CError) {
+	log := logger.FromCtx(ctx)
+	schedule, cerr := ps.priceRepo.GetPriceSchedule(ctx, id, scheduleID)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			log.Error("Error getting price schedule", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	switch schedule.Status {
+	case domain.PriceScheduleStatusScheduled:
+		schedule.Status = domain.PriceScheduleStatusCancelled
+		cerr = ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusScheduled)
+	case domain.PriceScheduleStatusActive:
+		cerr = ps.endPriceSchedule(ctx, schedule, userID, domain.PriceScheduleStatusCancelled)
+	default:
+		return nil, domain.NewBadRequestCError("the price schedule is already " + string(schedule.Status))
+	}
+
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.NewCError(http.StatusConflict, "the price schedule changed while it was cancelled, try again")
+		}
+		return nil, cerr
+	}
+
+	return schedule, nil
+}
+
+func (ps *ProductService) GetPriceHistory(ctx context.Context, id primitive.ObjectID) ([]domain.PriceChange, domain.CError) {
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	changes, cerr := ps.priceRepo.ListPriceChanges(ctx, id)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing price history", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return changes, nil
+}
+
+// RunPriceScheduler starts and ends due price schedules until the context is cancelled. Several
+// instances can run it at once, as a schedule only moves on from the status it was read in
+func (ps *ProductService) RunPriceScheduler(ctx context.Context) {
+	ticker := time.NewTicker(ps.priceInterval)
+	defer ticker.Stop()
+
+	for {
+		ps.applyDuePriceSchedules(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ps *ProductService) applyDuePriceSchedules(ctx context.Context) {
+	log := logger.FromCtx(ctx)
+	due, cerr := ps.priceRepo.ListDuePriceSchedules(ctx, time.Now())
+	if cerr != nil {
+		log.Error("Error listing due price schedules", zap.Error(cerr))
+		return
+	}
+
+	for i := range due {
+		schedule := &due[i]
+
+		var cerr domain.CError
+		if schedule.Status == domain.PriceScheduleStatusScheduled {
+			cerr = ps.startPriceSchedule(ctx, schedule)
+		} else {
+			cerr = ps.endPriceSchedule(ctx, schedule, schedule.CreatedBy, domain.PriceScheduleStatusCompleted)
+		}
+
+		// The schedule is still due, so it is tried again on the next run
+		if cerr != nil && cerr != domain.ErrDataNotFound {
+			log.Error("Error applying price schedule", zap.String("schedule_id", schedule.ID.Hex()),
+				zap.String("product_id", schedule.ProductID.Hex()), zap.Error(cerr))
+		}
+	}
+}
+
+// startPriceSchedule applies the price of a schedule that has started
+func (ps *ProductService) startPriceSchedule(ctx context.Context, schedule *domain.PriceSchedule) domain.CError {
+	prod, cerr := ps.repo.GetProductByID(ctx, schedule.ProductID)
+	if cerr == domain.ErrDataNotFound {
+		schedule.Status = domain.PriceScheduleStatusCancelled
+		schedule.Note = "the product was deleted"
+		return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusScheduled)
+	}
+	if cerr != nil {
+		return cerr
+	}
+
+	// A schedule that ended before it could be applied, while the service was down, is skipped
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(time.Now()) {
+		schedule.Status = domain.PriceScheduleStatusCompleted
+		schedule.Note = "the schedule ended before it was applied"
+		return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusScheduled)
+	}
+
+	previous := prod.Price
+	if cerr := ps.setPrice(ctx, prod, schedule.Price, schedule.CreatedBy, schedule.ID); cerr != nil {
+		return cerr
+	}
+
+	schedule.PreviousPrice = &previous
+	schedule.Status = domain.PriceScheduleStatusCompleted
+	if schedule.EndsAt != nil {
+		schedule.Status = domain.PriceScheduleStatusActive
+	}
+
+	return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusScheduled)
+}
+
+// endPriceSchedule puts back the price a product had before an active schedule. A price changed by
+// hand while the schedule was active is kept
+func (ps *ProductService) endPriceSchedule(ctx context.Context, schedule *domain.PriceSchedule, editorID primitive.ObjectID, status domain.PriceScheduleStatus) domain.CError {
+	schedule.Status = status
+
+	prod, cerr := ps.repo.GetProductByID(ctx, schedule.ProductID)
+	if cerr == domain.ErrDataNotFound {
+		schedule.Note = "the product was deleted"
+		return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusActive)
+	}
+	if cerr != nil {
+		return cerr
+	}
+
+	if schedule.PreviousPrice == nil || prod.Price != schedule.Price {
+		schedule.Note = "the price was changed while the schedule was active and was kept"
+		return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusActive)
+	}
+
+	if cerr := ps.setPrice(ctx, prod, *schedule.PreviousPrice, editorID, schedule.ID); cerr != nil {
+		return cerr
+	}
+
+	return ps.movePriceSchedule(ctx, schedule, domain.PriceScheduleStatusActive)
+}
+
+// setPrice changes the price of a product through a product update, so the change is recorded and
+// published like any other. An update made since the product was read fails the change
+func (ps *ProductService) setPrice(ctx context.Context, prod *domain.Product, price float64, editorID, scheduleID primitive.ObjectID) domain.CError {
+	req := domain.UpdateProductRequest{
+		Name:        prod.Name,
+		SKU:         &prod.SKU,
+		Description: prod.Description,
+		Price:       price,
+		Quantity:    prod.Quantity,
+		Status:      prod.Status.String(),
+	}
+
+	_, cerr := ps.updateProduct(ctx, prod.ID, editorID, &prod.Version, &req, updateOrigin{ScheduleID: &scheduleID})
+	if cerr != nil && cerr != errNoChanges {
+		return cerr
+	}
+
+	return nil
+}
+
+func (ps *ProductService) movePriceSchedule(ctx context.Context, schedule *domain.PriceSchedule, from domain.PriceScheduleStatus) domain.CError {
+	cerr := ps.priceRepo.UpdatePriceSchedule(ctx, schedule, from)
+	if cerr != nil && cerr.Code() == 500 {
+		logger.FromCtx(ctx).Error("Error updating price schedule", zap.Error(cerr))
+		return domain.ErrInternal
+	}
+
+	return cerr
+}
+
+// recordPriceChange adds a price to the history of a product. Failures are only logged, so they do
+// not fail the change
+func (ps *ProductService) recordPriceChange(ctx context.Context, prod *domain.Product, previous *float64, changedBy, scheduleID *primitive.ObjectID) {
+	_, cerr := ps.priceRepo.CreatePriceChange(ctx, &domain.PriceChange{
+		ProductID:     prod.ID,
+		Price:         prod.Price,
+		PreviousPrice: previous,
+		ChangedBy:     changedBy,
+		ScheduleID:    scheduleID,
+	})
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error recording price change", zap.String("product_id", prod.ID.Hex()), zap.Error(cerr))
+	}
+}
+
+// overlaps reports whether two schedules start at the same time or one starts while the other is
+// active. A schedule without an end is never active, as it only sets a price
+func overlaps(startA time.Time, endA *time.Time, startB time.Time, endB *time.Time) bool {
+	within := func(t, start time.Time, end *time.Time) bool {
+		return end != nil && !t.Before(start) && t.Before(*end)
+	}
+	return startA.Equal(startB) || within(startA, startB, endB) || within(startB, startA, endA)
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"product-service/internal/adapter/config"
@@ -21,40 +24,140 @@ import (
  * ProductService implements port.ProductService interface
  */
 type ProductService struct {
-	repo     port.ProductRepository
-	cache    port.CacheRepository
-	producer port.MessageQueueRepository
-	cacheTtl time.Duration
+	repo          port.ProductRepository
+	categoryRepo  port.CategoryRepository
+	inventoryRepo port.InventoryRepository
+	jobRepo       port.ImportJobRepository
+	revisionRepo  port.RevisionRepository
+	priceRepo     port.PriceRepository
+	reviewRepo    port.ReviewRepository
+	cache         port.CacheRepository
+	producer      port.MessageQueueRepository
+	media         port.MediaStore
+	cacheTtl      time.Duration
+	// maxImageSize and thumbnailSize limit image uploads and size their thumbnails
+	maxImageSize  int64
+	thumbnailSize int
+	// priceInterval is how often due price schedules are applied
+	priceInterval time.Duration
 }
 
 // NewProductService creates a new product service instance
-func NewProductService(repo port.ProductRepository, cache port.CacheRepository, producer port.MessageQueueRepository) *ProductService {
+func NewProductService(repo port.ProductRepository, categoryRepo port.CategoryRepository, inventoryRepo port.InventoryRepository, jobRepo port.ImportJobRepository, revisionRepo port.RevisionRepository, priceRepo port.PriceRepository, reviewRepo port.ReviewRepository, cache port.CacheRepository, producer port.MessageQueueRepository, media port.MediaStore) *ProductService {
 	cacheTtl, err := time.ParseDuration(config.GetConfig().Redis.Ttl)
 	if err != nil {
 		zap.L().Info("Error parsing cache ttl, defaulting to 24h", zap.Error(err))
 		cacheTtl = 24 * time.Hour
 	}
 
+	maxImageSize := config.GetConfig().Media.MaxUploadSize
+	if maxImageSize <= 0 {
+		maxImageSize = domain.DefaultMaxImageSize
+	}
+
+	thumbnailSize := config.GetConfig().Media.ThumbnailSize
+	if thumbnailSize <= 0 {
+		thumbnailSize = domain.DefaultThumbnailSize
+	}
+
+	priceInterval, err := time.ParseDuration(config.GetConfig().Scheduler.PriceInterval)
+	if err != nil || priceInterval <= 0 {
+		zap.L().Info("Error parsing price schedule interval, defaulting to 1m", zap.Error(err))
+		priceInterval = time.Minute
+	}
+
 	return &ProductService{
 		repo,
+		categoryRepo,
+		inventoryRepo,
+		jobRepo,
+		revisionRepo,
+		priceRepo,
+		reviewRepo,
 		cache,
 		producer,
+		media,
 		cacheTtl,
+		maxImageSize,
+		thumbnailSize,
+		priceInterval,
 	}
 }
 
+// errNoChanges is returned when an update leaves the product as it is
+var errNoChanges = domain.NewCError(http.StatusBadRequest, "There are no changes to update")
+
+var errBundleVariants = domain.NewBadRequestCError("a bundle cannot have variants, its components can be variants instead")
+
 func (ps *ProductService) CreateProduct(ctx context.Context, prod *domain.CreateProductRequest, userID primitive.ObjectID) (*domain.Product, domain.CError) {
+	return ps.createProduct(ctx, prod, userID, nil)
+}
+
+// createProduct creates a product owned by the user and, when orgID is set, managed by that organization
+func (ps *ProductService) createProduct(ctx context.Context, prod *domain.CreateProductRequest, userID primitive.ObjectID, orgID *primitive.ObjectID) (*domain.Product, domain.CError) {
 	log := logger.FromCtx(ctx)
+	categoryIDs, cerr := ps.resolveCategoryIDs(ctx, prod.CategoryIDs)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	variants, cerr := buildVariants(prod.Variants, nil)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	attributes, cerr := buildAttributes(prod.Attributes)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	sku := strings.TrimSpace(prod.SKU)
+	if cerr := ps.ensureSKUsAvailable(ctx, primitive.NilObjectID, sku, variants); cerr != nil {
+		return nil, cerr
+	}
+
+	var bundle *domain.Bundle
+	price, quantity := prod.Price, prod.Quantity
+	if prod.Bundle != nil {
+		if len(variants) > 0 {
+			return nil, errBundleVariants
+		}
+		bundle, price, quantity, cerr = ps.buildBundle(ctx, primitive.NilObjectID, userID, orgID, prod.Bundle, prod.Price)
+		if cerr != nil {
+			return nil, cerr
+		}
+	}
+
 	prodToCreate := domain.Product{
-		Name:        prod.Name,
-		Description: prod.Description,
-		Price:       prod.Price,
-		Quantity:    prod.Quantity,
-		Status:      domain.ProductStatusActive,
+		Name:           prod.Name,
+		SKU:            sku,
+		Description:    prod.Description,
+		Price:          price,
+		Quantity:       quantity,
+		Status:         domain.ProductStatusActive,
+		OrganizationID: orgID,
+		CategoryIDs:    categoryIDs,
+		Variants:       variants,
+		Tags:           buildTags(prod.Tags),
+		Attributes:     attributes,
+		Bundle:         bundle,
+	}
+
+	if len(variants) > 0 {
+		prodToCreate.Quantity = variantStock(variants)
+	}
+
+	if prod.Status == domain.ProductStatusInactive {
+		prodToCreate.Status = domain.ProductStatusInactive
+	} else if prodToCreate.Quantity <= 0 {
+		prodToCreate.Status = domain.ProductStatusOutOfStock
 	}
 
 	retUser, err := ps.GetUser(context.Background(), userID)
 	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return nil, err
+		}
 		log.Error("Error fetching user", zap.Error(err))
 		return nil, domain.ErrInternal
 	}
@@ -74,10 +177,46 @@ func (ps *ProductService) CreateProduct(ctx context.Context, prod *domain.Create
 		return nil, cerr
 	}
 
+	// The stock of a bundle is held by its components
+	if prodResponse.Bundle == nil {
+		ps.recordMovements(ctx, prodResponse, openingMovements(prodResponse.Quantity, prodResponse.Variants, &userID, "product created"))
+	}
+	ps.saveRevision(ctx, &domain.ProductRevision{
+		ProductID: prodResponse.ID,
+		Version:   prodResponse.Version,
+		Snapshot:  snapshotOf(prodResponse),
+		Changes:   make([]domain.FieldChange, 0),
+		EditorID:  &userID,
+	})
+	ps.recordPriceChange(ctx, prodResponse, nil, &userID, nil)
+	ps.publishProductEvent(ctx, product.EventProductCreated, product.ProductCreatedEvent{
+		Event:      product.EventProductCreated,
+		Product:    productForQueue(prodResponse, false),
+		OccurredAt: time.Now(),
+	})
+
+	if cerr := ps.attachBreadcrumbs(ctx, prodResponse); cerr != nil {
+		return nil, cerr
+	}
+
 	return prodResponse, nil
 }
 
 func (ps *ProductService) GetProduct(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	product, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if cerr := ps.attachBreadcrumbs(ctx, product); cerr != nil {
+		return nil, cerr
+	}
+
+	return product, nil
+}
+
+// getProduct fetches a product without resolving its breadcrumbs
+func (ps *ProductService) getProduct(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError) {
 	log := logger.FromCtx(ctx)
 	product, cerr := ps.repo.GetProductByID(ctx, id)
 	if cerr != nil {
@@ -91,38 +230,205 @@ func (ps *ProductService) GetProduct(ctx context.Context, id primitive.ObjectID)
 	return product, nil
 }
 
-func (ps *ProductService) ListProducts(ctx context.Context) ([]domain.Product, domain.CError) {
+func (ps *ProductService) ListProducts(ctx context.Context, filter *domain.ProductFilter) (*domain.ProductPage, domain.CError) {
 	log := logger.FromCtx(ctx)
-	users, cerr := ps.repo.ListProducts(ctx)
+	filter.Normalize()
+
+	if _, ok := domain.ProductSortFields[filter.SortBy]; !ok {
+		return nil, domain.NewBadRequestCError("invalid sort field specified: " + filter.SortBy)
+	}
+
+	if filter.After != nil && (filter.After.SortBy != filter.SortBy || filter.After.SortOrder != filter.SortOrder) {
+		return nil, domain.NewBadRequestCError("cursor does not match the requested sort")
+	}
+
+	if filter.CategoryID != nil {
+		category, cerr := ps.categoryRepo.GetCategoryByID(ctx, *filter.CategoryID)
+		if cerr != nil {
+			if cerr == domain.ErrDataNotFound {
+				return nil, domain.NewBadRequestCError("category does not exist")
+			}
+			log.Error("Error getting category", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+
+		subtree, cerr := ps.categoryRepo.ListSubtree(ctx, category)
+		if cerr != nil {
+			log.Error("Error listing category subtree", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+
+		filter.CategoryIDs = make([]primitive.ObjectID, len(subtree))
+		for i, c := range subtree {
+			filter.CategoryIDs[i] = c.ID
+		}
+	}
+
+	products, cerr := ps.repo.ListProducts(ctx, filter)
 	if cerr != nil {
 		log.Error("Error listing products", zap.Error(cerr))
 		return nil, domain.ErrInternal
 	}
 
-	return users, nil
+	page := domain.ProductPage{
+		Products: products,
+		Limit:    filter.Limit,
+	}
+
+	if int64(len(products)) > filter.Limit {
+		page.Products = products[:filter.Limit]
+		page.NextCursor = domain.NewProductCursor(filter, &page.Products[filter.Limit-1]).Encode()
+	}
+
+	// Facets do not change from one page to the next, so they are only counted for the first one
+	if filter.After == nil {
+		page.Facets, cerr = ps.repo.CountProductFacets(ctx, filter)
+		if cerr != nil {
+			log.Error("Error counting product facets", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+	}
+
+	if cerr := ps.attachBreadcrumbs(ctx, productPtrs(page.Products)...); cerr != nil {
+		return nil, cerr
+	}
+
+	return &page, nil
+}
+
+func (ps *ProductService) UpdateProduct(ctx context.Context, id, editorID primitive.ObjectID, version *int64, req *domain.UpdateProductRequest) (*domain.Product, domain.CError) {
+	return ps.updateProduct(ctx, id, editorID, version, req, updateOrigin{})
+}
+
+// updateOrigin tells what made an update other than a user editing the product
+type updateOrigin struct {
+	// RevertedFrom is the revision the update reverts the product to
+	RevertedFrom *primitive.ObjectID
+	// ScheduleID is the price schedule the update applies
+	ScheduleID *primitive.ObjectID
 }
 
-func (ps *ProductService) UpdateProduct(ctx context.Context, id primitive.ObjectID, req *domain.UpdateProductRequest) (*domain.Product, domain.CError) {
+// updateProduct applies an update and records the revision and the price change it produces
+func (ps *ProductService) updateProduct(ctx context.Context, id, editorID primitive.ObjectID, version *int64, req *domain.UpdateProductRequest, origin updateOrigin) (*domain.Product, domain.CError) {
 	log := logger.FromCtx(ctx)
-	retProd, cerr := ps.GetProduct(ctx, id)
+	retProd, cerr := ps.getProduct(ctx, id)
 	if cerr != nil {
 		return nil, cerr
 	}
 
+	if version != nil && *version != retProd.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	categoryIDs := retProd.CategoryIDs
+	categoriesAreUpdated := false
+	if req.CategoryIDs != nil {
+		categoryIDs, cerr = ps.resolveCategoryIDs(ctx, *req.CategoryIDs)
+		if cerr != nil {
+			return nil, cerr
+		}
+		categoriesAreUpdated = !sameCategories(categoryIDs, retProd.CategoryIDs)
+	}
+
+	variants := retProd.Variants
+	variantsAreUpdated := false
+	if req.Variants != nil {
+		variants, cerr = buildVariants(*req.Variants, retProd.Variants)
+		if cerr != nil {
+			return nil, cerr
+		}
+		variantsAreUpdated = !sameVariants(variants, retProd.Variants)
+	}
+
+	tags := retProd.Tags
+	tagsAreUpdated := false
+	if req.Tags != nil {
+		tags = buildTags(*req.Tags)
+		tagsAreUpdated = !sameTags(tags, retProd.Tags)
+	}
+
+	attributes := retProd.Attributes
+	attributesAreUpdated := false
+	if req.Attributes != nil {
+		attributes, cerr = buildAttributes(*req.Attributes)
+		if cerr != nil {
+			return nil, cerr
+		}
+		attributesAreUpdated = !sameAttributes(attributes, retProd.Attributes)
+	}
+
+	sku := retProd.SKU
+	if req.SKU != nil {
+		sku = strings.TrimSpace(*req.SKU)
+	}
+	skuIsUpdated := sku != retProd.SKU
+
+	if skuIsUpdated || variantsAreUpdated {
+		if cerr := ps.ensureSKUsAvailable(ctx, id, sku, variants); cerr != nil {
+			return nil, cerr
+		}
+	}
+
+	if req.Bundle != nil && retProd.Bundle == nil {
+		return nil, domain.NewBadRequestCError("only bundles have components, create a bundle instead")
+	}
+
+	bundle := retProd.Bundle
+	bundleIsUpdated := false
+	price := req.Price
+	var quantity int32
+	var movements []domain.StockMovement
+	if bundle != nil {
+		if len(variants) > 0 {
+			return nil, errBundleVariants
+		}
+
+		if req.Bundle != nil {
+			bundle, price, quantity, cerr = ps.buildBundle(ctx, id, retProd.OwnerID, retProd.OrganizationID, req.Bundle, req.Price)
+			if cerr != nil {
+				return nil, cerr
+			}
+		} else {
+			if bundle.Pricing == domain.BundlePricingFixed && req.Price <= 0 {
+				return nil, errBundlePrice
+			}
+			components, cerr := ps.bundleComponents(ctx, bundle)
+			if cerr != nil {
+				return nil, cerr
+			}
+			bundle, price, quantity = resolveBundle(bundle, req.Price, components)
+		}
+		bundleIsUpdated = !sameBundleComponents(bundle, retProd.Bundle)
+	} else {
+		quantity, movements, cerr = stockChanges(retProd, variants, req.Quantity)
+		if cerr != nil {
+			return nil, cerr
+		}
+	}
+
 	if req.Name == retProd.Name && req.Description == retProd.Description && req.Status == retProd.Status.String() &&
-		req.Price == retProd.Price && req.Quantity == retProd.Quantity {
-		return nil, domain.NewCError(http.StatusBadRequest, "There are no changes to update")
+		price == retProd.Price && quantity == retProd.Quantity && !skuIsUpdated && !categoriesAreUpdated && !variantsAreUpdated &&
+		!tagsAreUpdated && !attributesAreUpdated && !bundleIsUpdated {
+		return nil, errNoChanges
 	}
 
+	before := *retProd
+
 	var nameIsUpdated bool
 	if req.Name != retProd.Name {
 		nameIsUpdated = true
 	}
 
 	retProd.Name = req.Name
+	retProd.SKU = sku
 	retProd.Description = req.Description
-	retProd.Price = req.Price
-	retProd.Quantity = req.Quantity
+	retProd.Price = price
+	retProd.Quantity = quantity
+	retProd.CategoryIDs = categoryIDs
+	retProd.Variants = variants
+	retProd.Tags = tags
+	retProd.Attributes = attributes
+	retProd.Bundle = bundle
 
 	if status, ok := domain.StringToProductStatus[req.Status]; ok {
 		retProd.Status = status
@@ -137,30 +443,85 @@ func (ps *ProductService) UpdateProduct(ctx context.Context, id primitive.Object
 		return nil, cerr
 	}
 
-	// Start the process of publishing update to queue
-	// Error in this process does not affect the request
-	productToProduce := product.ProductUpdateForQueue{
-		Id:            productResponse.ID.Hex(),
-		Name:          productResponse.Name,
-		Description:   productResponse.Description,
-		Price:         productResponse.Price,
-		Quantity:      productResponse.Quantity,
-		OwnerId:       productResponse.OwnerID.Hex(),
-		OwnerEmail:    productResponse.OwnerEmail,
-		OwnerName:     productResponse.OwnerName,
-		OwnerPhone:    productResponse.OwnerPhone,
-		CreatedAt:     productResponse.CreatedAt.String(),
-		UpdatedAt:     productResponse.UpdatedAt.String(),
+	ps.recordMovements(ctx, productResponse, movements)
+	productResponse = ps.syncStockStatus(ctx, productResponse)
+	ps.recordRevision(ctx, &before, productResponse, editorID, origin.RevertedFrom)
+	if productResponse.Price != before.Price {
+		ps.recordPriceChange(ctx, productResponse, &before.Price, &editorID, origin.ScheduleID)
+	}
+	ps.publishProductUpdate(ctx, productResponse, nameIsUpdated)
+	ps.refreshBundles(ctx, productResponse.ID)
+
+	if before.Quantity <= 0 && productResponse.Quantity > 0 {
+		ps.alertBackInStock(ctx, productResponse, nil)
+	}
+
+	if cerr := ps.attachBreadcrumbs(ctx, productResponse); cerr != nil {
+		return nil, cerr
+	}
+
+	return productResponse, nil
+}
+
+// productForQueue converts a product into the message published about it
+func productForQueue(prod *domain.Product, nameIsUpdated bool) product.ProductUpdateForQueue {
+	msg := product.ProductUpdateForQueue{
+		Id:            prod.ID.Hex(),
+		Name:          prod.Name,
+		Description:   prod.Description,
+		Price:         prod.Price,
+		Quantity:      prod.Quantity,
+		OwnerId:       prod.OwnerID.Hex(),
+		OwnerEmail:    prod.OwnerEmail,
+		OwnerName:     prod.OwnerName,
+		OwnerPhone:    prod.OwnerPhone,
+		CreatedAt:     prod.CreatedAt.String(),
+		UpdatedAt:     prod.UpdatedAt.String(),
+		Variants:      variantsToProto(prod),
+		Images:        imagesToProto(prod),
+		Components:    componentsToProto(prod),
 		NameIsUpdated: nameIsUpdated,
 	}
 
-	status, _ := product.StringToProductStatus(productResponse.Status.String())
-	productToProduce.Status = status
+	status, _ := product.StringToProductStatus(prod.Status.String())
+	msg.Status = status
+
+	return msg
+}
+
+// publishProductEvent publishes a product event to the product-events queue.
+// Errors are only logged, so they do not affect the request
+func (ps *ProductService) publishProductEvent(ctx context.Context, eventType string, event any) {
+	log := logger.FromCtx(ctx)
+
+	msg, err := util.Serialize(event)
+	if err != nil {
+		log.Error("Error serializing product event", zap.String("event", eventType), zap.Error(err))
+		return
+	}
+
+	queue := "product-events"
+	headers := map[string]any{
+		"event":                            eventType,
+		string(domain.CorrelationIDCtxKey): ctx.Value(domain.CorrelationIDCtxKey),
+	}
+
+	log.Info("Publishing product event to message queue", zap.String("queue", queue), zap.String("event", eventType))
+	if err := ps.producer.Publish(ctx, queue, msg, headers); err != nil {
+		log.Error("Could not publish product event to the queue", zap.String("event", eventType), zap.Error(err))
+	}
+}
+
+// publishProductUpdate publishes the product to the product-updates queue.
+// Errors are only logged, so they do not affect the request
+func (ps *ProductService) publishProductUpdate(ctx context.Context, prod *domain.Product, nameIsUpdated bool) {
+	log := logger.FromCtx(ctx)
+	productToProduce := productForQueue(prod, nameIsUpdated)
 
 	sProd, err := util.Serialize(productToProduce)
 	if err != nil {
-		log.Error("Error serializing product to publish to queue", zap.Error(cerr))
-		return productResponse, nil
+		log.Error("Error serializing product to publish to queue", zap.Error(err))
+		return
 	}
 
 	queue := "product-updates"
@@ -175,12 +536,10 @@ func (ps *ProductService) UpdateProduct(ctx context.Context, id primitive.Object
 	err = ps.producer.Publish(ctx, queue, sProd, headers)
 	if err != nil {
 		log.Error("Could not publish product update to the queue", zap.Error(err))
-		return productResponse, nil
+		return
 	}
 
 	log.Info("Successfully published message about update to queue")
-
-	return productResponse, nil
 }
 
 func (ps *ProductService) DeleteProduct(ctx context.Context, id primitive.ObjectID) domain.CError {
@@ -194,20 +553,450 @@ func (ps *ProductService) DeleteProduct(ctx context.Context, id primitive.Object
 		return cerr
 	}
 
+	ps.publishProductEvent(ctx, product.EventProductDeleted, product.ProductDeletedEvent{
+		Event:      product.EventProductDeleted,
+		ProductID:  id.Hex(),
+		OccurredAt: time.Now(),
+	})
+	ps.refreshBundles(ctx, id)
+
 	return nil
 }
 
+func (ps *ProductService) CreateOrganizationProduct(ctx context.Context, orgID, userID primitive.ObjectID, prod *domain.CreateProductRequest) (*domain.Product, domain.CError) {
+	if cerr := ps.authorizeMember(ctx, orgID, userID, domain.PermWriteProducts); cerr != nil {
+		return nil, cerr
+	}
+
+	return ps.createProduct(ctx, prod, userID, &orgID)
+}
+
+func (ps *ProductService) ListOrganizationProducts(ctx context.Context, orgID, userID primitive.ObjectID) ([]domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+	if cerr := ps.authorizeMember(ctx, orgID, userID, ""); cerr != nil {
+		return nil, cerr
+	}
+
+	products, cerr := ps.repo.ListProductsByOrganization(ctx, orgID)
+	if cerr != nil {
+		log.Error("Error listing organization products", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	if cerr := ps.attachBreadcrumbs(ctx, productPtrs(products)...); cerr != nil {
+		return nil, cerr
+	}
+
+	return products, nil
+}
+
+func (ps *ProductService) UpdateOrganizationProduct(ctx context.Context, orgID, userID, id primitive.ObjectID, version *int64, prod *domain.UpdateProductRequest) (*domain.Product, domain.CError) {
+	if cerr := ps.authorizeMember(ctx, orgID, userID, domain.PermWriteProducts); cerr != nil {
+		return nil, cerr
+	}
+
+	if cerr := ps.ensureOrganizationProduct(ctx, orgID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	return ps.UpdateProduct(ctx, id, userID, version, prod)
+}
+
+func (ps *ProductService) DeleteOrganizationProduct(ctx context.Context, orgID, userID, id primitive.ObjectID) domain.CError {
+	if cerr := ps.authorizeMember(ctx, orgID, userID, domain.PermDeleteProducts); cerr != nil {
+		return cerr
+	}
+
+	if cerr := ps.ensureOrganizationProduct(ctx, orgID, id); cerr != nil {
+		return cerr
+	}
+
+	return ps.DeleteProduct(ctx, id)
+}
+
+// ensureOrganizationProduct reports products of other organizations as not found so their existence is not leaked
+func (ps *ProductService) ensureOrganizationProduct(ctx context.Context, orgID, id primitive.ObjectID) domain.CError {
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return cerr
+	}
+
+	if retProd.OrganizationID == nil || *retProd.OrganizationID != orgID {
+		return domain.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// resolveCategoryIDs parses and deduplicates category ids and checks that every category exists
+func (ps *ProductService) resolveCategoryIDs(ctx context.Context, ids []string) ([]primitive.ObjectID, domain.CError) {
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	categoryIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, v := range ids {
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return nil, domain.NewBadRequestCError("Invalid category id: " + v)
+		}
+		if !seen[id] {
+			seen[id] = true
+			categoryIDs = append(categoryIDs, id)
+		}
+	}
+
+	if len(categoryIDs) == 0 {
+		return categoryIDs, nil
+	}
+
+	categories, cerr := ps.categoryRepo.GetCategoriesByIDs(ctx, categoryIDs)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting categories", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	if len(categories) != len(categoryIDs) {
+		return nil, domain.NewBadRequestCError("some of the specified categories do not exist")
+	}
+
+	return categoryIDs, nil
+}
+
+// buildVariants turns variant requests into variants. Requests with an id keep the matching
+// existing variant id, any other request gets a new variant
+func buildVariants(reqs []domain.VariantRequest, existing []domain.Variant) ([]domain.Variant, domain.CError) {
+	existingIDs := make(map[primitive.ObjectID]bool, len(existing))
+	for _, v := range existing {
+		existingIDs[v.ID] = true
+	}
+
+	skus := make(map[string]bool, len(reqs))
+	combinations := make(map[string]bool, len(reqs))
+	ids := make(map[primitive.ObjectID]bool, len(reqs))
+	variants := make([]domain.Variant, 0, len(reqs))
+
+	for _, req := range reqs {
+		sku := strings.TrimSpace(req.SKU)
+		if skus[strings.ToLower(sku)] {
+			return nil, domain.NewBadRequestCError("duplicate variant sku: " + sku)
+		}
+		skus[strings.ToLower(sku)] = true
+
+		combination := optionsKey(req.Options)
+		if combinations[combination] {
+			return nil, domain.NewBadRequestCError("duplicate variant options for sku: " + sku)
+		}
+		combinations[combination] = true
+
+		variant := domain.Variant{
+			ID:       primitive.NewObjectID(),
+			SKU:      sku,
+			Options:  req.Options,
+			Price:    req.Price,
+			Quantity: req.Quantity,
+		}
+
+		if req.ID != "" {
+			id, err := primitive.ObjectIDFromHex(req.ID)
+			if err != nil || !existingIDs[id] {
+				return nil, domain.NewBadRequestCError("variant does not exist: " + req.ID)
+			}
+			if ids[id] {
+				return nil, domain.NewBadRequestCError("duplicate variant id: " + req.ID)
+			}
+			ids[id] = true
+			variant.ID = id
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// buildTags lowercases and trims tags, dropping duplicates, and sorts them
+func buildTags(reqs []string) []string {
+	seen := make(map[string]bool, len(reqs))
+	tags := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		tag := strings.ToLower(strings.TrimSpace(req))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+	return tags
+}
+
+// buildAttributes lowercases and trims attribute names and trims their values. A product has one
+// value per attribute name, and its attributes are sorted by name
+func buildAttributes(reqs []domain.ProductAttribute) ([]domain.ProductAttribute, domain.CError) {
+	names := make(map[string]bool, len(reqs))
+	attributes := make([]domain.ProductAttribute, 0, len(reqs))
+	for _, req := range reqs {
+		attribute := domain.ProductAttribute{
+			Name:  strings.ToLower(strings.TrimSpace(req.Name)),
+			Value: strings.TrimSpace(req.Value),
+		}
+		if attribute.Name == "" || attribute.Value == "" {
+			return nil, domain.NewBadRequestCError("attribute names and values must not be blank")
+		}
+		if names[attribute.Name] {
+			return nil, domain.NewBadRequestCError("duplicate attribute: " + attribute.Name)
+		}
+		names[attribute.Name] = true
+		attributes = append(attributes, attribute)
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+	return attributes, nil
+}
+
+// ensureSKUsAvailable checks that the SKU of a product, and the SKUs of its variants, are not used by
+// another product. A product with variants is identified by their SKUs and cannot have its own
+func (ps *ProductService) ensureSKUsAvailable(ctx context.Context, id primitive.ObjectID, sku string, variants []domain.Variant) domain.CError {
+	if sku != "" && len(variants) > 0 {
+		return domain.NewBadRequestCError("a product with variants is identified by the skus of its variants")
+	}
+
+	skus := make([]string, 0, len(variants)+1)
+	if sku != "" {
+		skus = append(skus, sku)
+	}
+	for _, v := range variants {
+		skus = append(skus, v.SKU)
+	}
+
+	for _, sku := range skus {
+		prod, cerr := ps.repo.GetProductBySKU(ctx, sku)
+		if cerr != nil {
+			if cerr == domain.ErrDataNotFound {
+				continue
+			}
+			logger.FromCtx(ctx).Error("Error getting product by sku", zap.Error(cerr))
+			return domain.ErrInternal
+		}
+
+		if prod.ID != id {
+			return domain.NewCError(http.StatusConflict, "sku is already in use: "+sku)
+		}
+	}
+
+	return nil
+}
+
+// stockChanges works out the stock of an updated product and the movements recording the change.
+// Stock is changed through stock adjustments, so an update only adds the stock of new variants
+// and takes out the stock of removed ones
+func stockChanges(retProd *domain.Product, variants []domain.Variant, quantity int32) (int32, []domain.StockMovement, domain.CError) {
+	errStockChange := domain.NewBadRequestCError("Stock cannot be changed through a product update, use a stock adjustment instead")
+
+	existing := make(map[primitive.ObjectID]domain.Variant, len(retProd.Variants))
+	for _, v := range retProd.Variants {
+		existing[v.ID] = v
+	}
+
+	added := make([]domain.Variant, 0)
+	for _, v := range variants {
+		old, ok := existing[v.ID]
+		if !ok {
+			added = append(added, v)
+			continue
+		}
+		if v.Quantity != old.Quantity {
+			return 0, nil, errStockChange
+		}
+		delete(existing, v.ID)
+	}
+
+	var movements []domain.StockMovement
+	if len(added) > 0 {
+		movements = openingMovements(0, added, nil, "variant added")
+	}
+	for _, v := range retProd.Variants {
+		if _, removed := existing[v.ID]; removed && v.Quantity != 0 {
+			variantID := v.ID
+			movements = append(movements, domain.StockMovement{
+				VariantID: &variantID,
+				Type:      domain.MovementRemoval,
+				Delta:     -v.Quantity,
+				Reason:    "variant removed",
+			})
+		}
+	}
+
+	if len(variants) > 0 {
+		// The stock of a product without variants moves to its new variants
+		if len(retProd.Variants) == 0 && retProd.Quantity != 0 {
+			movements = append(movements, domain.StockMovement{
+				Type:   domain.MovementRemoval,
+				Delta:  -retProd.Quantity,
+				Reason: "stock moved to variants",
+			})
+		}
+		return variantStock(variants), movements, nil
+	}
+
+	if len(retProd.Variants) == 0 {
+		if quantity != retProd.Quantity {
+			return 0, nil, errStockChange
+		}
+		return quantity, movements, nil
+	}
+
+	// All variants were removed, so the product holds its own stock again
+	if quantity != 0 {
+		movements = append(movements, domain.StockMovement{
+			Type:   domain.MovementInitial,
+			Delta:  quantity,
+			Reason: "variants removed",
+		})
+	}
+	return quantity, movements, nil
+}
+
+// optionsKey returns a key identifying a combination of option values regardless of their order
+func optionsKey(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, strings.ToLower(k))
+	}
+	sort.Strings(keys)
+
+	lowered := make(map[string]string, len(options))
+	for k, v := range options {
+		lowered[strings.ToLower(k)] = strings.ToLower(v)
+	}
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + "=" + lowered[k] + ";")
+	}
+	return b.String()
+}
+
+// variantStock returns the total stock of the variants
+func variantStock(variants []domain.Variant) int32 {
+	var total int32
+	for _, v := range variants {
+		total += v.Quantity
+	}
+	return total
+}
+
+// attachBreadcrumbs fills in the breadcrumb trail of every category of the products
+func (ps *ProductService) attachBreadcrumbs(ctx context.Context, products ...*domain.Product) domain.CError {
+	var ids []primitive.ObjectID
+	for _, p := range products {
+		if p.CategoryIDs == nil {
+			p.CategoryIDs = []primitive.ObjectID{}
+		}
+		ids = append(ids, p.CategoryIDs...)
+	}
+
+	trails, cerr := categoryTrails(ctx, ps.categoryRepo, ids)
+	if cerr != nil {
+		return cerr
+	}
+
+	for _, p := range products {
+		p.Breadcrumbs = make([][]domain.Breadcrumb, 0, len(p.CategoryIDs))
+		for _, id := range p.CategoryIDs {
+			if trail, ok := trails[id]; ok {
+				p.Breadcrumbs = append(p.Breadcrumbs, trail)
+			}
+		}
+	}
+
+	return nil
+}
+
+// productPtrs returns pointers to the products of a slice so they can be filled in place
+func productPtrs(products []domain.Product) []*domain.Product {
+	ptrs := make([]*domain.Product, len(products))
+	for i := range products {
+		ptrs[i] = &products[i]
+	}
+	return ptrs
+}
+
+// sameCategories reports whether both lists hold the same categories, in any order
+func sameCategories(a, b []primitive.ObjectID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[primitive.ObjectID]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameTags reports whether both lists hold the same tags. Tags are kept sorted
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameAttributes reports whether both lists hold the same attributes. Attributes are kept sorted by name
+func sameAttributes(a, b []domain.ProductAttribute) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameVariants reports whether both lists hold the same variants in the same order
+func sameVariants(a, b []domain.Variant) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
 func (ps *ProductService) UpdateProductsFromQueue(log *zap.Logger, msg []byte) error {
 	log.Info("Received a new message", zap.String("update", string(msg)))
 
-	var update domain.UserUpdateForQueue
+	var update domain.UserUpdatedEvent
 	err := util.Deserialize(msg, &update)
 	if err != nil {
 		log.Error("Could not deserialize message for updating product owner", zap.Error(err))
 		return err
 	}
 
-	updatedProducts, err := ps.repo.UpdateProductOwner(context.Background(), &update)
+	if update.User == nil {
+		log.Error("Received user update without a user profile", zap.Int32("schema_version", update.SchemaVersion))
+		return fmt.Errorf("user update has no user profile")
+	}
+
+	updatedProducts, err := ps.repo.UpdateProductOwner(context.Background(), update.User)
 	if err != nil {
 		log.Error("Could not update products owner", zap.Error(err))
 		return err
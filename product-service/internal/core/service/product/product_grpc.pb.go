@@ -19,8 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Product_Get_FullMethodName     = "/product.Product/Get"
-	Product_GetMany_FullMethodName = "/product.Product/GetMany"
+	Product_Get_FullMethodName           = "/product.Product/Get"
+	Product_GetMany_FullMethodName       = "/product.Product/GetMany"
+	Product_ReserveStock_FullMethodName  = "/product.Product/ReserveStock"
+	Product_ReleaseStock_FullMethodName  = "/product.Product/ReleaseStock"
+	Product_WatchProducts_FullMethodName = "/product.Product/WatchProducts"
 )
 
 // ProductClient is the client API for Product service.
@@ -29,6 +32,12 @@ const (
 type ProductClient interface {
 	Get(ctx context.Context, in *ProductRequest, opts ...grpc.CallOption) (*ProductResponse, error)
 	GetMany(ctx context.Context, in *ProductsRequest, opts ...grpc.CallOption) (*ProductsResponse, error)
+	// ReserveStock takes the stock of every line for an order, or of none of them when one does not have enough stock
+	ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*StockResponse, error)
+	// ReleaseStock gives back the stock reserved for an order
+	ReleaseStock(ctx context.Context, in *ReleaseStockRequest, opts ...grpc.CallOption) (*StockResponse, error)
+	// WatchProducts streams every change made to products after the resume token, in order
+	WatchProducts(ctx context.Context, in *WatchProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProductChange], error)
 }
 
 type productClient struct {
@@ -59,12 +68,57 @@ func (c *productClient) GetMany(ctx context.Context, in *ProductsRequest, opts .
 	return out, nil
 }
 
+func (c *productClient) ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*StockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StockResponse)
+	err := c.cc.Invoke(ctx, Product_ReserveStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productClient) ReleaseStock(ctx context.Context, in *ReleaseStockRequest, opts ...grpc.CallOption) (*StockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StockResponse)
+	err := c.cc.Invoke(ctx, Product_ReleaseStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productClient) WatchProducts(ctx context.Context, in *WatchProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProductChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Product_ServiceDesc.Streams[0], Product_WatchProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchProductsRequest, ProductChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Product_WatchProductsClient = grpc.ServerStreamingClient[ProductChange]
+
 // ProductServer is the server API for Product service.
 // All implementations must embed UnimplementedProductServer
 // for forward compatibility.
 type ProductServer interface {
 	Get(context.Context, *ProductRequest) (*ProductResponse, error)
 	GetMany(context.Context, *ProductsRequest) (*ProductsResponse, error)
+	// ReserveStock takes the stock of every line for an order, or of none of them when one does not have enough stock
+	ReserveStock(context.Context, *ReserveStockRequest) (*StockResponse, error)
+	// ReleaseStock gives back the stock reserved for an order
+	ReleaseStock(context.Context, *ReleaseStockRequest) (*StockResponse, error)
+	// WatchProducts streams every change made to products after the resume token, in order
+	WatchProducts(*WatchProductsRequest, grpc.ServerStreamingServer[ProductChange]) error
 	mustEmbedUnimplementedProductServer()
 }
 
@@ -81,6 +135,15 @@ func (UnimplementedProductServer) Get(context.Context, *ProductRequest) (*Produc
 func (UnimplementedProductServer) GetMany(context.Context, *ProductsRequest) (*ProductsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMany not implemented")
 }
+func (UnimplementedProductServer) ReserveStock(context.Context, *ReserveStockRequest) (*StockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveStock not implemented")
+}
+func (UnimplementedProductServer) ReleaseStock(context.Context, *ReleaseStockRequest) (*StockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseStock not implemented")
+}
+func (UnimplementedProductServer) WatchProducts(*WatchProductsRequest, grpc.ServerStreamingServer[ProductChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProducts not implemented")
+}
 func (UnimplementedProductServer) mustEmbedUnimplementedProductServer() {}
 func (UnimplementedProductServer) testEmbeddedByValue()                 {}
 
@@ -138,6 +201,53 @@ func _Product_GetMany_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Product_ReserveStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServer).ReserveStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Product_ReserveStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServer).ReserveStock(ctx, req.(*ReserveStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Product_ReleaseStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServer).ReleaseStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Product_ReleaseStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServer).ReleaseStock(ctx, req.(*ReleaseStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Product_WatchProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchProductsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProductServer).WatchProducts(m, &grpc.GenericServerStream[WatchProductsRequest, ProductChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Product_WatchProductsServer = grpc.ServerStreamingServer[ProductChange]
+
 // Product_ServiceDesc is the grpc.ServiceDesc for Product service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,7 +263,21 @@ var Product_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMany",
 			Handler:    _Product_GetMany_Handler,
 		},
+		{
+			MethodName: "ReserveStock",
+			Handler:    _Product_ReserveStock_Handler,
+		},
+		{
+			MethodName: "ReleaseStock",
+			Handler:    _Product_ReleaseStock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchProducts",
+			Handler:       _Product_WatchProducts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "product.proto",
 }
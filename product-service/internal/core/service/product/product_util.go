@@ -1,6 +1,9 @@
 package product
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 func ProductStatusToString(ps ProductStatus) string {
 	switch ps {
@@ -29,17 +32,40 @@ func StringToProductStatus(status string) (ProductStatus, error) {
 }
 
 type ProductUpdateForQueue struct {
-	Id            string        `json:"id,omitempty"`
-	Name          string        `json:"name,omitempty"`
-	Description   string        `json:"description,omitempty"`
-	Price         float64       `json:"price,omitempty"`
-	Quantity      int32         `json:"quantity,omitempty"`
-	Status        ProductStatus `json:"status,omitempty"`
-	OwnerId       string        `json:"owner_id,omitempty"`
-	OwnerName     string        `json:"owner_name,omitempty"`
-	OwnerPhone    string        `json:"owner_phone,omitempty"`
-	OwnerEmail    string        `json:"owner_email,omitempty"`
-	CreatedAt     string        `json:"created_at,omitempty"`
-	UpdatedAt     string        `json:"updated_at,omitempty"`
-	NameIsUpdated bool          `json:"name_is_updated"`
+	Id            string             `json:"id,omitempty"`
+	Name          string             `json:"name,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	Price         float64            `json:"price,omitempty"`
+	Quantity      int32              `json:"quantity,omitempty"`
+	Status        ProductStatus      `json:"status,omitempty"`
+	OwnerId       string             `json:"owner_id,omitempty"`
+	OwnerName     string             `json:"owner_name,omitempty"`
+	OwnerPhone    string             `json:"owner_phone,omitempty"`
+	OwnerEmail    string             `json:"owner_email,omitempty"`
+	CreatedAt     string             `json:"created_at,omitempty"`
+	UpdatedAt     string             `json:"updated_at,omitempty"`
+	Variants      []*ProductVariant  `json:"variants,omitempty"`
+	Images        []*ProductImage    `json:"images,omitempty"`
+	Components    []*BundleComponent `json:"components,omitempty"`
+	NameIsUpdated bool               `json:"name_is_updated"`
+}
+
+// Types of the events published to the "product-events" queue. The type is also sent in the event header
+const (
+	EventProductCreated = "product.created"
+	EventProductDeleted = "product.deleted"
+)
+
+// ProductCreatedEvent is published to the "product-events" queue when a product is created
+type ProductCreatedEvent struct {
+	Event      string                `json:"event"`
+	Product    ProductUpdateForQueue `json:"product"`
+	OccurredAt time.Time             `json:"occurred_at"`
+}
+
+// ProductDeletedEvent is published to the "product-events" queue when a product is deleted
+type ProductDeletedEvent struct {
+	Event      string    `json:"event"`
+	ProductID  string    `json:"product_id"`
+	OccurredAt time.Time `json:"occurred_at"`
 }
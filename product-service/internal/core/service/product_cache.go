@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/adapter/config"
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+	"product-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// The cache is shared with other services, so every key of the product cache starts with "catalog"
+const (
+	// catalogVersionKey holds the counter incremented by every change to any product. Listing pages
+	// are cached under the counter they were read at, so a change leaves them unused
+	catalogVersionKey = "catalog:version"
+	// catalogEpochKey holds the counter incremented by changes made to many products at once. Cached
+	// products are only used while it is unchanged
+	catalogEpochKey = "catalog:epoch"
+	// productListCacheTtl frees listing pages that are no longer read. Pages change with every
+	// product change, so they are kept for a shorter time than products
+	productListCacheTtl = 5 * time.Minute
+)
+
+// Cached values are encoded in BSON like in the database, as some stored fields, such as the keys of
+// product images, are left out of JSON
+
+// cachedProduct is a product in the cache with the counters it was read at. It is only used while
+// the counter of the product and the catalog epoch are unchanged
+type cachedProduct struct {
+	Version int64          `bson:"v"`
+	Epoch   int64          `bson:"e"`
+	Product domain.Product `bson:"p"`
+}
+
+// cachedProductPage is a listing page in the cache
+type cachedProductPage struct {
+	Products []domain.Product `bson:"p"`
+}
+
+// cachedProductFacets are the facet counts of a listing in the cache
+type cachedProductFacets struct {
+	Facets domain.ProductFacets `bson:"f"`
+}
+
+/**
+ * CachedProductRepository implements port.ProductRepository interface. It reads products and
+ * listing pages through the cache and invalidates them on every change made through it.
+ * Methods it does not override read from the wrapped repository
+ */
+type CachedProductRepository struct {
+	port.ProductRepository
+	cache port.CacheRepository
+	ttl   time.Duration
+	// group coalesces concurrent misses of the same key into a single read of the repository
+	group singleflight.Group
+}
+
+// NewCachedProductRepository wraps a product repository with the cache
+func NewCachedProductRepository(repo port.ProductRepository, cache port.CacheRepository) *CachedProductRepository {
+	ttl, err := time.ParseDuration(config.GetConfig().Redis.Ttl)
+	if err != nil {
+		zap.L().Info("Error parsing cache ttl, defaulting to 24h", zap.Error(err))
+		ttl = 24 * time.Hour
+	}
+
+	return &CachedProductRepository{
+		ProductRepository: repo,
+		cache:             cache,
+		ttl:               ttl,
+	}
+}
+
+func productCacheKey(id primitive.ObjectID) string {
+	return util.GenerateCacheKey("catalog:product", id.Hex())
+}
+
+func productVersionCacheKey(id primitive.ObjectID) string {
+	return util.GenerateCacheKey("catalog:product-version", id.Hex())
+}
+
+// GetProductByID reads a product through the cache
+func (cr *CachedProductRepository) GetProductByID(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+	key := productCacheKey(id)
+
+	values, err := cr.cache.MGet(ctx, []string{key, productVersionCacheKey(id), catalogEpochKey})
+	if err != nil {
+		log.Warn("Error reading product from cache", zap.String("product_id", id.Hex()), zap.Error(err))
+		return cr.ProductRepository.GetProductByID(ctx, id)
+	}
+
+	version, epoch := cacheCounter(values[1]), cacheCounter(values[2])
+	if prod := validCachedProduct(values[0], version, epoch); prod != nil {
+		return prod, nil
+	}
+
+	flight := fmt.Sprintf("%s:%d:%d", key, version, epoch)
+	v, err, _ := cr.group.Do(flight, func() (any, error) {
+		// The read is shared by every caller waiting on it, so it is not cancelled with the first one
+		prod, cerr := cr.ProductRepository.GetProductByID(context.WithoutCancel(ctx), id)
+		if cerr != nil {
+			return nil, cerr
+		}
+
+		cr.store(ctx, key, cachedProduct{Version: version, Epoch: epoch, Product: *prod}, cr.ttl)
+		return prod, nil
+	})
+	if err != nil {
+		return nil, err.(domain.CError)
+	}
+
+	// Callers change the product they get, so each one gets its own copy
+	prod := *v.(*domain.Product)
+	return &prod, nil
+}
+
+// GetProductsByIDs reads the products through the cache, fetching only those missing from it
+func (cr *CachedProductRepository) GetProductsByIDs(ctx context.Context, productIds []primitive.ObjectID) ([]domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	keys := make([]string, 0, 2*len(productIds)+1)
+	keys = append(keys, catalogEpochKey)
+	for _, id := range productIds {
+		keys = append(keys, productCacheKey(id), productVersionCacheKey(id))
+	}
+
+	values, err := cr.cache.MGet(ctx, keys)
+	if err != nil {
+		log.Warn("Error reading products from cache", zap.Error(err))
+		return cr.ProductRepository.GetProductsByIDs(ctx, productIds)
+	}
+
+	epoch := cacheCounter(values[0])
+	versions := make(map[primitive.ObjectID]int64, len(productIds))
+	prods := make([]domain.Product, 0, len(productIds))
+	missing := make([]primitive.ObjectID, 0)
+	for i, id := range productIds {
+		version := cacheCounter(values[2+2*i])
+		versions[id] = version
+
+		prod := validCachedProduct(values[1+2*i], version, epoch)
+		if prod == nil {
+			missing = append(missing, id)
+			continue
+		}
+
+		// Like the repository, only products that can be ordered are returned
+		if prod.Status == domain.ProductStatusActive || prod.Status == domain.ProductStatusOutOfStock {
+			prods = append(prods, *prod)
+		}
+	}
+
+	if len(missing) > 0 {
+		ids := make([]string, len(missing))
+		for i, id := range missing {
+			ids[i] = id.Hex()
+		}
+		sort.Strings(ids)
+
+		flight := fmt.Sprintf("catalog:products-by-ids:%d:%s", epoch, strings.Join(ids, ","))
+		v, err, _ := cr.group.Do(flight, func() (any, error) {
+			fetched, cerr := cr.ProductRepository.GetProductsByIDs(context.WithoutCancel(ctx), missing)
+			if cerr != nil {
+				return nil, cerr
+			}
+
+			for _, prod := range fetched {
+				entry := cachedProduct{Version: versions[prod.ID], Epoch: epoch, Product: prod}
+				cr.store(ctx, productCacheKey(prod.ID), entry, cr.ttl)
+			}
+			return fetched, nil
+		})
+		if err != nil {
+			return nil, err.(domain.CError)
+		}
+
+		prods = append(prods, v.([]domain.Product)...)
+	}
+
+	// The repository returns the newest products first
+	sort.SliceStable(prods, func(i, j int) bool {
+		return prods[i].CreatedAt.After(prods[j].CreatedAt)
+	})
+
+	return prods, nil
+}
+
+// ListProducts reads a listing page through the cache. Pages are cached under the catalog version,
+// so any change to a product leaves them unused
+func (cr *CachedProductRepository) ListProducts(ctx context.Context, filter *domain.ProductFilter) ([]domain.Product, domain.CError) {
+	key, ok := cr.listingKey(ctx, "products", filter)
+	if !ok {
+		return cr.ProductRepository.ListProducts(ctx, filter)
+	}
+
+	cached, err := cr.cache.MGet(ctx, []string{key})
+	if err == nil && cached[0] != nil {
+		var page cachedProductPage
+		if err := bson.Unmarshal(cached[0], &page); err == nil {
+			return page.Products, nil
+		}
+	}
+
+	v, err, _ := cr.group.Do(key, func() (any, error) {
+		prods, cerr := cr.ProductRepository.ListProducts(context.WithoutCancel(ctx), filter)
+		if cerr != nil {
+			return nil, cerr
+		}
+
+		cr.store(ctx, key, cachedProductPage{Products: prods}, productListCacheTtl)
+		return prods, nil
+	})
+	if err != nil {
+		return nil, err.(domain.CError)
+	}
+
+	prods := v.([]domain.Product)
+	return append(make([]domain.Product, 0, len(prods)), prods...), nil
+}
+
+// CountProductFacets reads the facet counts of a listing through the cache, under the catalog version like listing pages
+func (cr *CachedProductRepository) CountProductFacets(ctx context.Context, filter *domain.ProductFilter) (*domain.ProductFacets, domain.CError) {
+	key, ok := cr.listingKey(ctx, "facets", filter)
+	if !ok {
+		return cr.ProductRepository.CountProductFacets(ctx, filter)
+	}
+
+	cached, err := cr.cache.MGet(ctx, []string{key})
+	if err == nil && cached[0] != nil {
+		var entry cachedProductFacets
+		if err := bson.Unmarshal(cached[0], &entry); err == nil {
+			return &entry.Facets, nil
+		}
+	}
+
+	v, err, _ := cr.group.Do(key, func() (any, error) {
+		facets, cerr := cr.ProductRepository.CountProductFacets(context.WithoutCancel(ctx), filter)
+		if cerr != nil {
+			return nil, cerr
+		}
+
+		cr.store(ctx, key, cachedProductFacets{Facets: *facets}, productListCacheTtl)
+		return facets, nil
+	})
+	if err != nil {
+		return nil, err.(domain.CError)
+	}
+
+	return v.(*domain.ProductFacets), nil
+}
+
+// listingKey returns the cache key of a listing of the kind for the filter at the current catalog
+// version. It is not ok when the key cannot be built, and the listing is then read from the repository
+func (cr *CachedProductRepository) listingKey(ctx context.Context, kind string, filter *domain.ProductFilter) (string, bool) {
+	log := logger.FromCtx(ctx)
+
+	values, err := cr.cache.MGet(ctx, []string{catalogVersionKey})
+	if err != nil {
+		log.Warn("Error reading catalog version from cache", zap.Error(err))
+		return "", false
+	}
+
+	serialFilter, err := util.Serialize(filter)
+	if err != nil {
+		log.Warn("Error serializing product filter", zap.Error(err))
+		return "", false
+	}
+
+	hash := sha256.Sum256(serialFilter)
+	return fmt.Sprintf("catalog:%s:%d:%s", kind, cacheCounter(values[0]), hex.EncodeToString(hash[:])), true
+}
+
+func (cr *CachedProductRepository) CreateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
+	created, cerr := cr.ProductRepository.CreateProduct(ctx, prod)
+	if cerr == nil {
+		cr.invalidate(ctx)
+	}
+	return created, cerr
+}
+
+func (cr *CachedProductRepository) UpdateProduct(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
+	updated, cerr := cr.ProductRepository.UpdateProduct(ctx, prod)
+	if cerr == nil {
+		cr.invalidate(ctx, updated.ID)
+	}
+	return updated, cerr
+}
+
+func (cr *CachedProductRepository) DeleteProduct(ctx context.Context, id primitive.ObjectID) domain.CError {
+	cerr := cr.ProductRepository.DeleteProduct(ctx, id)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return cerr
+}
+
+func (cr *CachedProductRepository) UpdateProductOwner(ctx context.Context, owner *domain.UserProfile) (int64, domain.CError) {
+	count, cerr := cr.ProductRepository.UpdateProductOwner(ctx, owner)
+	if cerr == nil && count > 0 {
+		cr.invalidateAll(ctx)
+	}
+	return count, cerr
+}
+
+func (cr *CachedProductRepository) AdjustStock(ctx context.Context, id primitive.ObjectID, variantID *primitive.ObjectID, delta int32, expected *int32) (*domain.Product, domain.CError) {
+	prod, cerr := cr.ProductRepository.AdjustStock(ctx, id, variantID, delta, expected)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return prod, cerr
+}
+
+func (cr *CachedProductRepository) SyncStockStatus(ctx context.Context, id primitive.ObjectID) (*domain.Product, domain.CError) {
+	prod, cerr := cr.ProductRepository.SyncStockStatus(ctx, id)
+	if cerr == nil && prod != nil {
+		cr.invalidate(ctx, id)
+	}
+	return prod, cerr
+}
+
+func (cr *CachedProductRepository) AddProductImage(ctx context.Context, id primitive.ObjectID, image *domain.ProductImage) (*domain.Product, domain.CError) {
+	prod, cerr := cr.ProductRepository.AddProductImage(ctx, id, image)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return prod, cerr
+}
+
+func (cr *CachedProductRepository) RemoveProductImage(ctx context.Context, id, imageID primitive.ObjectID) (*domain.Product, domain.CError) {
+	prod, cerr := cr.ProductRepository.RemoveProductImage(ctx, id, imageID)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return prod, cerr
+}
+
+func (cr *CachedProductRepository) SetProductImages(ctx context.Context, id primitive.ObjectID, images []domain.ProductImage) (*domain.Product, domain.CError) {
+	prod, cerr := cr.ProductRepository.SetProductImages(ctx, id, images)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return prod, cerr
+}
+
+func (cr *CachedProductRepository) ReplaceCategory(ctx context.Context, from primitive.ObjectID, to *primitive.ObjectID) (int64, domain.CError) {
+	count, cerr := cr.ProductRepository.ReplaceCategory(ctx, from, to)
+	if cerr == nil && count > 0 {
+		cr.invalidateAll(ctx)
+	}
+	return count, cerr
+}
+
+func (cr *CachedProductRepository) AdjustRating(ctx context.Context, id primitive.ObjectID, countDelta, totalDelta int64) domain.CError {
+	cerr := cr.ProductRepository.AdjustRating(ctx, id, countDelta, totalDelta)
+	if cerr == nil {
+		cr.invalidate(ctx, id)
+	}
+	return cerr
+}
+
+func (cr *CachedProductRepository) SyncBundle(ctx context.Context, prod *domain.Product) (*domain.Product, domain.CError) {
+	updated, cerr := cr.ProductRepository.SyncBundle(ctx, prod)
+	if cerr == nil {
+		cr.invalidate(ctx, updated.ID)
+	}
+	return updated, cerr
+}
+
+// invalidate increments the counters of the products and the catalog version, so the cached
+// products and listing pages are no longer used. Counters outlive the entries they guard, so an
+// expired counter cannot make an old entry valid again
+func (cr *CachedProductRepository) invalidate(ctx context.Context, ids ...primitive.ObjectID) {
+	log := logger.FromCtx(ctx)
+
+	for _, id := range ids {
+		if _, err := cr.cache.Incr(ctx, productVersionCacheKey(id), 2*cr.ttl); err != nil {
+			log.Error("Error invalidating cached product", zap.String("product_id", id.Hex()), zap.Error(err))
+		}
+	}
+
+	if _, err := cr.cache.Incr(ctx, catalogVersionKey, 2*cr.ttl); err != nil {
+		log.Error("Error invalidating cached product listings", zap.Error(err))
+	}
+}
+
+// invalidateAll makes every cached product and listing page unused, after a change to many products
+func (cr *CachedProductRepository) invalidateAll(ctx context.Context) {
+	if _, err := cr.cache.Incr(ctx, catalogEpochKey, 2*cr.ttl); err != nil {
+		logger.FromCtx(ctx).Error("Error invalidating cached products", zap.Error(err))
+	}
+
+	cr.invalidate(ctx)
+}
+
+// store saves a value in the cache. Failures are only logged, as the value is read from the
+// repository again
+func (cr *CachedProductRepository) store(ctx context.Context, key string, value any, ttl time.Duration) {
+	log := logger.FromCtx(ctx)
+
+	serial, err := bson.Marshal(value)
+	if err != nil {
+		log.Warn("Error serializing value to cache", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	if err := cr.cache.Set(ctx, key, serial, ttl); err != nil {
+		log.Warn("Error saving value to cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// validCachedProduct returns the cached product when it was read at the given counters
+func validCachedProduct(value []byte, version, epoch int64) *domain.Product {
+	if value == nil {
+		return nil
+	}
+
+	var entry cachedProduct
+	if err := bson.Unmarshal(value, &entry); err != nil || entry.Version != version || entry.Epoch != epoch {
+		return nil
+	}
+
+	return &entry.Product
+}
+
+// cacheCounter parses a counter read from the cache. A counter that is not set is zero
+func cacheCounter(value []byte) int64 {
+	if value == nil {
+		return 0
+	}
+
+	counter, _ := strconv.ParseInt(string(value), 10, 64)
+	return counter
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"net/http"
+	"time"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func (ps *ProductService) AddProductImage(ctx context.Context, id primitive.ObjectID, upload *domain.ImageUpload) (*domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	if int64(len(upload.Data)) > ps.maxImageSize {
+		return nil, domain.NewCError(http.StatusRequestEntityTooLarge, fmt.Sprintf("image is larger than the limit of %d bytes", ps.maxImageSize))
+	}
+
+	// The content type is sniffed from the bytes rather than trusted from the request
+	contentType := http.DetectContentType(upload.Data)
+	ext, ok := domain.ImageExtensions[contentType]
+	if !ok {
+		return nil, domain.NewCError(http.StatusUnsupportedMediaType, "unsupported image type: "+contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(upload.Data))
+	if err != nil {
+		return nil, domain.NewBadRequestCError("image could not be read: " + err.Error())
+	}
+	if cfg.Width*cfg.Height > domain.MaxImagePixels {
+		return nil, domain.NewBadRequestCError(fmt.Sprintf("image dimensions %dx%d are too large", cfg.Width, cfg.Height))
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(upload.Data))
+	if err != nil {
+		return nil, domain.NewBadRequestCError("image could not be read: " + err.Error())
+	}
+
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+	if len(retProd.Images) >= domain.MaxProductImages {
+		return nil, domain.NewBadRequestCError(fmt.Sprintf("a product can have at most %d images", domain.MaxProductImages))
+	}
+
+	thumbnail, thumbnailType, err := util.EncodeThumbnail(util.Thumbnail(img, ps.thumbnailSize), format)
+	if err != nil {
+		log.Error("Error encoding thumbnail", zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	productImage := domain.ProductImage{
+		ID:          primitive.NewObjectID(),
+		ContentType: contentType,
+		Size:        int64(len(upload.Data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		CreatedAt:   time.Now(),
+	}
+	productImage.Key = fmt.Sprintf("products/%s/%s%s", id.Hex(), productImage.ID.Hex(), ext)
+	productImage.ThumbnailKey = fmt.Sprintf("products/%s/%s_thumb%s", id.Hex(), productImage.ID.Hex(), domain.ImageExtensions[thumbnailType])
+
+	productImage.URL, err = ps.media.Put(ctx, productImage.Key, contentType, upload.Data)
+	if err != nil {
+		log.Error("Error storing image", zap.String("key", productImage.Key), zap.Error(err))
+		return nil, domain.ErrInternal
+	}
+
+	productImage.ThumbnailURL, err = ps.media.Put(ctx, productImage.ThumbnailKey, thumbnailType, thumbnail)
+	if err != nil {
+		log.Error("Error storing thumbnail", zap.String("key", productImage.ThumbnailKey), zap.Error(err))
+		ps.deleteImageFiles(ctx, &productImage)
+		return nil, domain.ErrInternal
+	}
+
+	productResponse, cerr := ps.repo.AddProductImage(ctx, id, &productImage)
+	if cerr != nil {
+		ps.deleteImageFiles(ctx, &productImage)
+		if cerr == domain.ErrDataNotFound {
+			// The product was deleted or reached the image limit since it was read
+			return nil, domain.NewCError(http.StatusConflict, "image could not be added to the product")
+		}
+		log.Error("Error adding product image", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	ps.publishProductUpdate(ctx, productResponse, false)
+
+	if cerr := ps.attachBreadcrumbs(ctx, productResponse); cerr != nil {
+		return nil, cerr
+	}
+
+	return productResponse, nil
+}
+
+func (ps *ProductService) DeleteProductImage(ctx context.Context, id, imageID primitive.ObjectID) (*domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	var productImage *domain.ProductImage
+	for i := range retProd.Images {
+		if retProd.Images[i].ID == imageID {
+			productImage = &retProd.Images[i]
+		}
+	}
+	if productImage == nil {
+		return nil, domain.ErrDataNotFound
+	}
+
+	productResponse, cerr := ps.repo.RemoveProductImage(ctx, id, imageID)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			log.Error("Error removing product image", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	// The image is no longer referenced, so failing to delete its files only leaves them orphaned
+	ps.deleteImageFiles(ctx, productImage)
+	ps.publishProductUpdate(ctx, productResponse, false)
+
+	if cerr := ps.attachBreadcrumbs(ctx, productResponse); cerr != nil {
+		return nil, cerr
+	}
+
+	return productResponse, nil
+}
+
+func (ps *ProductService) ReorderProductImages(ctx context.Context, id primitive.ObjectID, req *domain.ReorderImagesRequest) (*domain.Product, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	retProd, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if len(req.ImageIDs) != len(retProd.Images) {
+		return nil, domain.NewBadRequestCError("every image of the product must be listed exactly once")
+	}
+
+	byID := make(map[string]domain.ProductImage, len(retProd.Images))
+	for _, img := range retProd.Images {
+		byID[img.ID.Hex()] = img
+	}
+
+	images := make([]domain.ProductImage, 0, len(req.ImageIDs))
+	for _, imageID := range req.ImageIDs {
+		img, ok := byID[imageID]
+		if !ok {
+			return nil, domain.NewBadRequestCError("every image of the product must be listed exactly once")
+		}
+		delete(byID, imageID)
+		images = append(images, img)
+	}
+
+	productResponse, cerr := ps.repo.SetProductImages(ctx, id, images)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			log.Error("Error reordering product images", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	ps.publishProductUpdate(ctx, productResponse, false)
+
+	if cerr := ps.attachBreadcrumbs(ctx, productResponse); cerr != nil {
+		return nil, cerr
+	}
+
+	return productResponse, nil
+}
+
+// deleteImageFiles removes the files of an image from the media store, logging any failure
+func (ps *ProductService) deleteImageFiles(ctx context.Context, img *domain.ProductImage) {
+	for _, key := range []string{img.Key, img.ThumbnailKey} {
+		if err := ps.media.Delete(ctx, key); err != nil {
+			logger.FromCtx(ctx).Error("Error deleting media file", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func (ps *ProductService) ListRevisions(ctx context.Context, id primitive.ObjectID, before *primitive.ObjectID, limit int64) (*domain.RevisionPage, domain.CError) {
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	if limit < 1 {
+		limit = domain.DefaultPageSize
+	}
+	if limit > domain.MaxPageSize {
+		limit = domain.MaxPageSize
+	}
+
+	revisions, cerr := ps.revisionRepo.ListRevisions(ctx, id, before, limit+1)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing product revisions", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	page := domain.RevisionPage{Revisions: revisions}
+	if int64(len(revisions)) > limit {
+		page.Revisions = revisions[:limit]
+		page.NextBefore = page.Revisions[limit-1].ID.Hex()
+	}
+
+	return &page, nil
+}
+
+func (ps *ProductService) DiffRevisions(ctx context.Context, id, fromID, toID primitive.ObjectID) (*domain.RevisionDiff, domain.CError) {
+	from, cerr := ps.getRevision(ctx, id, fromID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	to, cerr := ps.getRevision(ctx, id, toID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	return &domain.RevisionDiff{
+		From:    from.ID,
+		To:      to.ID,
+		Changes: diffSnapshots(&from.Snapshot, &to.Snapshot),
+	}, nil
+}
+
+func (ps *ProductService) RevertProduct(ctx context.Context, id, revisionID, editorID primitive.ObjectID, version *int64) (*domain.Product, domain.CError) {
+	revision, cerr := ps.getRevision(ctx, id, revisionID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	current, cerr := ps.getProduct(ctx, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	snapshot := revision.Snapshot
+	req := domain.UpdateProductRequest{
+		Name:        snapshot.Name,
+		SKU:         &snapshot.SKU,
+		Description: snapshot.Description,
+		Price:       snapshot.Price,
+		Quantity:    current.Quantity,
+		Status:      snapshot.Status.String(),
+	}
+
+	// Whether the product is out of stock follows its current stock
+	if snapshot.Status == domain.ProductStatusOutOfStock {
+		req.Status = domain.ProductStatusActive.String()
+	}
+
+	categoryIDs := make([]string, 0, len(snapshot.CategoryIDs))
+	for _, categoryID := range snapshot.CategoryIDs {
+		categoryIDs = append(categoryIDs, categoryID.Hex())
+	}
+	req.CategoryIDs = &categoryIDs
+
+	// Variants that still exist keep their stock. Variants removed since the revision come back as new
+	// variants without stock
+	stock := make(map[primitive.ObjectID]int32, len(current.Variants))
+	for _, v := range current.Variants {
+		stock[v.ID] = v.Quantity
+	}
+
+	variants := make([]domain.VariantRequest, 0, len(snapshot.Variants))
+	for _, v := range snapshot.Variants {
+		variant := domain.VariantRequest{SKU: v.SKU, Options: v.Options, Price: v.Price}
+		if quantity, ok := stock[v.ID]; ok {
+			variant.ID = v.ID.Hex()
+			variant.Quantity = quantity
+		}
+		variants = append(variants, variant)
+	}
+	req.Variants = &variants
+
+	// A revision recorded before products had tags and attributes leaves them as they are
+	if snapshot.Tags != nil {
+		req.Tags = &snapshot.Tags
+	}
+	if snapshot.Attributes != nil {
+		req.Attributes = &snapshot.Attributes
+	}
+
+	// A product cannot become a bundle or stop being one, so only the components of a bundle are reverted
+	if snapshot.Bundle != nil && current.Bundle != nil {
+		req.Bundle = bundleRequestOf(snapshot.Bundle)
+	}
+
+	return ps.updateProduct(ctx, id, editorID, version, &req, updateOrigin{RevertedFrom: &revision.ID})
+}
+
+func (ps *ProductService) getRevision(ctx context.Context, productID, id primitive.ObjectID) (*domain.ProductRevision, domain.CError) {
+	revision, cerr := ps.revisionRepo.GetRevision(ctx, productID, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting product revision", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return revision, nil
+}
+
+// recordRevision records the revision produced by a change of a product. The history of a product
+// created before revisions were recorded starts with its state before the change.
+// Failures are only logged, so they do not fail the change
+func (ps *ProductService) recordRevision(ctx context.Context, before, after *domain.Product, editorID primitive.ObjectID, revertedFrom *primitive.ObjectID) {
+	log := logger.FromCtx(ctx)
+	previous := snapshotOf(before)
+
+	_, cerr := ps.revisionRepo.GetLatestRevision(ctx, after.ID)
+	if cerr == domain.ErrDataNotFound {
+		ps.saveRevision(ctx, &domain.ProductRevision{
+			ProductID: before.ID,
+			Version:   before.Version,
+			Snapshot:  previous,
+			Changes:   make([]domain.FieldChange, 0),
+		})
+	} else if cerr != nil {
+		log.Error("Error getting latest product revision", zap.Error(cerr))
+	}
+
+	next := snapshotOf(after)
+	ps.saveRevision(ctx, &domain.ProductRevision{
+		ProductID:    after.ID,
+		Version:      after.Version,
+		Snapshot:     next,
+		Changes:      diffSnapshots(&previous, &next),
+		EditorID:     &editorID,
+		RevertedFrom: revertedFrom,
+	})
+}
+
+func (ps *ProductService) saveRevision(ctx context.Context, revision *domain.ProductRevision) {
+	if _, cerr := ps.revisionRepo.CreateRevision(ctx, revision); cerr != nil {
+		logger.FromCtx(ctx).Error("Error recording product revision", zap.String("product_id", revision.ProductID.Hex()),
+			zap.Int64("version", revision.Version), zap.Error(cerr))
+	}
+}
+
+// snapshotOf returns the fields of a product tracked by its revisions
+func snapshotOf(prod *domain.Product) domain.ProductSnapshot {
+	snapshot := domain.ProductSnapshot{
+		Name:        prod.Name,
+		SKU:         prod.SKU,
+		Description: prod.Description,
+		Price:       prod.Price,
+		Status:      prod.Status,
+		CategoryIDs: make([]primitive.ObjectID, 0, len(prod.CategoryIDs)),
+		Variants:    make([]domain.VariantSnapshot, 0, len(prod.Variants)),
+		Tags:        make([]string, 0, len(prod.Tags)),
+		Attributes:  make([]domain.ProductAttribute, 0, len(prod.Attributes)),
+		Bundle:      prod.Bundle,
+	}
+
+	snapshot.CategoryIDs = append(snapshot.CategoryIDs, prod.CategoryIDs...)
+	snapshot.Tags = append(snapshot.Tags, prod.Tags...)
+	snapshot.Attributes = append(snapshot.Attributes, prod.Attributes...)
+	for _, v := range prod.Variants {
+		snapshot.Variants = append(snapshot.Variants, domain.VariantSnapshot{
+			ID:      v.ID,
+			SKU:     v.SKU,
+			Options: v.Options,
+			Price:   v.Price,
+		})
+	}
+
+	return snapshot
+}
+
+// diffSnapshots lists the fields that differ between two snapshots
+func diffSnapshots(from, to *domain.ProductSnapshot) []domain.FieldChange {
+	changes := make([]domain.FieldChange, 0)
+	add := func(field string, fromValue, toValue any) {
+		changes = append(changes, domain.FieldChange{Field: field, From: rawJSON(fromValue), To: rawJSON(toValue)})
+	}
+
+	if from.Name != to.Name {
+		add("name", from.Name, to.Name)
+	}
+	if from.SKU != to.SKU {
+		add("sku", from.SKU, to.SKU)
+	}
+	if from.Description != to.Description {
+		add("description", from.Description, to.Description)
+	}
+	if from.Price != to.Price {
+		add("price", from.Price, to.Price)
+	}
+	if from.Status != to.Status {
+		add("status", from.Status, to.Status)
+	}
+	if !sameCategories(from.CategoryIDs, to.CategoryIDs) {
+		add("category_ids", from.CategoryIDs, to.CategoryIDs)
+	}
+	if !sameTags(from.Tags, to.Tags) {
+		add("tags", from.Tags, to.Tags)
+	}
+	if !sameAttributes(from.Attributes, to.Attributes) {
+		add("attributes", from.Attributes, to.Attributes)
+	}
+	if !sameBundle(from.Bundle, to.Bundle) {
+		add("bundle", from.Bundle, to.Bundle)
+	}
+
+	fromVariants := make(map[primitive.ObjectID]domain.VariantSnapshot, len(from.Variants))
+	for _, v := range from.Variants {
+		fromVariants[v.ID] = v
+	}
+
+	for _, v := range to.Variants {
+		old, ok := fromVariants[v.ID]
+		if !ok {
+			add("variants."+v.ID.Hex(), nil, v)
+			continue
+		}
+		if !reflect.DeepEqual(old, v) {
+			add("variants."+v.ID.Hex(), old, v)
+		}
+		delete(fromVariants, v.ID)
+	}
+
+	for _, v := range from.Variants {
+		if _, removed := fromVariants[v.ID]; removed {
+			add("variants."+v.ID.Hex(), v, nil)
+		}
+	}
+
+	return changes
+}
+
+// rawJSON encodes a value of a field change. The values are plain data, so encoding does not fail
+func rawJSON(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	kept, removed, added := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	price := 12.5
+
+	base := func() *domain.ProductSnapshot {
+		return &domain.ProductSnapshot{
+			Name:        "Mug",
+			Description: "A mug",
+			Price:       10,
+			Status:      domain.ProductStatusActive,
+			Tags:        []string{"kitchen"},
+			Variants: []domain.VariantSnapshot{
+				{ID: kept, SKU: "MUG-S", Options: map[string]string{"size": "s"}},
+				{ID: removed, SKU: "MUG-L", Options: map[string]string{"size": "l"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		change     func(s *domain.ProductSnapshot)
+		wantFields []string
+	}{
+		{
+			name:       "no change",
+			change:     func(s *domain.ProductSnapshot) {},
+			wantFields: []string{},
+		},
+		{
+			name: "fields",
+			change: func(s *domain.ProductSnapshot) {
+				s.Name = "Big mug"
+				s.Price = 11
+				s.Tags = []string{"kitchen", "gift"}
+			},
+			wantFields: []string{"name", "price", "tags"},
+		},
+		{
+			name: "variants changed, added and removed",
+			change: func(s *domain.ProductSnapshot) {
+				s.Variants = []domain.VariantSnapshot{
+					{ID: kept, SKU: "MUG-S", Options: map[string]string{"size": "s"}, Price: &price},
+					{ID: added, SKU: "MUG-M", Options: map[string]string{"size": "m"}},
+				}
+			},
+			wantFields: []string{"variants." + kept.Hex(), "variants." + added.Hex(), "variants." + removed.Hex()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := base()
+			tt.change(to)
+
+			changes := diffSnapshots(base(), to)
+
+			fields := make([]string, 0, len(changes))
+			for _, c := range changes {
+				fields = append(fields, c.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("diffSnapshots() fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestDiffSnapshotsVariantValues(t *testing.T) {
+	added, removed := primitive.NewObjectID(), primitive.NewObjectID()
+	from := &domain.ProductSnapshot{Variants: []domain.VariantSnapshot{{ID: removed, SKU: "OLD"}}}
+	to := &domain.ProductSnapshot{Variants: []domain.VariantSnapshot{{ID: added, SKU: "NEW"}}}
+
+	changes := diffSnapshots(from, to)
+	if len(changes) != 2 {
+		t.Fatalf("diffSnapshots() = %+v, want 2 changes", changes)
+	}
+
+	// An added variant has no previous value and a removed one has no new value
+	if string(changes[0].From) != "null" || string(changes[1].To) != "null" {
+		t.Errorf("diffSnapshots() = %s -> %s, %s -> %s, want null for the missing sides",
+			changes[0].From, changes[0].To, changes[1].From, changes[1].To)
+	}
+
+	var variant domain.VariantSnapshot
+	if err := json.Unmarshal(changes[0].To, &variant); err != nil || variant.SKU != "NEW" {
+		t.Errorf("added variant = %s, want the variant with SKU NEW", changes[0].To)
+	}
+}
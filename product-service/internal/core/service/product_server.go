@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"net/http"
+	"pkg/grpcauth"
+	"product-service/internal/core/domain"
 	"product-service/internal/core/port"
 	"product-service/internal/core/service/product"
 	"time"
@@ -14,20 +17,26 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var _ product.ProductServer = (*grpcServer)(nil)
 
 type Config struct {
+	// Clients are the services allowed to call the gRPC server, by name
+	Clients map[string]grpcauth.Client
 }
 
 type grpcServer struct {
 	product.UnimplementedProductServer
 	config      *Config
 	productRepo port.ProductRepository
+	inventory   port.InventoryService
+	feed        port.ProductFeedRepository
 }
 
-func NewGRPCServer(config *Config, userRepo port.ProductRepository, opts ...grpc.ServerOption) (*grpc.Server, error) {
+func NewGRPCServer(config *Config, userRepo port.ProductRepository, inventory port.InventoryService, feed port.ProductFeedRepository, opts ...grpc.ServerOption) (*grpc.Server, error) {
 
 	logger := zap.L().Named("grpc_server")
 	zapOpts := []grpc_zap.Option{
@@ -45,11 +54,17 @@ func NewGRPCServer(config *Config, userRepo port.ProductRepository, opts ...grpc
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
 			grpc_ctxtags.UnaryServerInterceptor(),
 			grpc_zap.UnaryServerInterceptor(logger, zapOpts...),
+			grpcauth.UnaryServerInterceptor(config.Clients),
+		)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(
+			grpc_ctxtags.StreamServerInterceptor(),
+			grpc_zap.StreamServerInterceptor(logger, zapOpts...),
+			grpcauth.StreamServerInterceptor(config.Clients),
 		)),
 	)
 
 	gsrv := grpc.NewServer(opts...)
-	srv, err := newgrpcServer(config, userRepo)
+	srv, err := newgrpcServer(config, userRepo, inventory, feed)
 	if err != nil {
 		return nil, err
 	}
@@ -58,10 +73,12 @@ func NewGRPCServer(config *Config, userRepo port.ProductRepository, opts ...grpc
 	return gsrv, nil
 }
 
-func newgrpcServer(config *Config, productRepo port.ProductRepository) (srv *grpcServer, err error) {
+func newgrpcServer(config *Config, productRepo port.ProductRepository, inventory port.InventoryService, feed port.ProductFeedRepository) (srv *grpcServer, err error) {
 	srv = &grpcServer{
 		config:      config,
 		productRepo: productRepo,
+		inventory:   inventory,
+		feed:        feed,
 	}
 	return srv, nil
 }
@@ -85,25 +102,7 @@ func (s *grpcServer) Get(ctx context.Context, req *product.ProductRequest) (*pro
 		return nil, err
 	}
 
-	prod := product.ProductResponse{
-		Id:          retProd.ID.Hex(),
-		Name:        retProd.Name,
-		Description: retProd.Description,
-		Price:       retProd.Price,
-		Quantity:    retProd.Quantity,
-		OwnerId:     retProd.OwnerID.Hex(),
-		OwnerName:   retProd.OwnerName,
-		OwnerPhone:  retProd.OwnerPhone,
-		OwnerEmail:  retProd.OwnerEmail,
-		CreatedAt:   retProd.CreatedAt.String(),
-		UpdatedAt:   retProd.CreatedAt.String(),
-	}
-
-	if status, err := product.StringToProductStatus(retProd.Status.String()); err == nil {
-		prod.Status = status
-	}
-
-	return &prod, nil
+	return productToProto(retProd), nil
 }
 
 func (s *grpcServer) GetMany(ctx context.Context, req *product.ProductsRequest) (*product.ProductsResponse, error) {
@@ -129,26 +128,196 @@ func (s *grpcServer) GetMany(ctx context.Context, req *product.ProductsRequest)
 	}
 
 	prodResp := make([]*product.ProductResponse, 0, len(products))
-	for _, prod := range products {
-		p := &product.ProductResponse{
-			Id:          prod.ID.Hex(),
-			Name:        prod.Name,
-			Description: prod.Description,
-			Price:       prod.Price,
-			Quantity:    prod.Quantity,
-			OwnerId:     prod.OwnerID.Hex(),
-			OwnerName:   prod.OwnerName,
-			OwnerPhone:  prod.OwnerPhone,
-			OwnerEmail:  prod.OwnerEmail,
-			CreatedAt:   prod.CreatedAt.String(),
-			UpdatedAt:   prod.UpdatedAt.String(),
+	for i := range products {
+		prodResp = append(prodResp, productToProto(&products[i]))
+	}
+
+	return &product.ProductsResponse{Products: prodResp}, nil
+}
+
+func (s *grpcServer) ReserveStock(ctx context.Context, req *product.ReserveStockRequest) (*product.StockResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received ReserveStock request", zap.String("order_id", req.OrderId))
+
+	userID, err := primitive.ObjectIDFromHex(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	lines := make([]domain.StockLine, 0, len(req.Lines))
+	for _, l := range req.Lines {
+		productID, err := primitive.ObjectIDFromHex(l.ProductId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid product id: "+l.ProductId)
 		}
 
-		if status, err := product.StringToProductStatus(prod.Status.String()); err == nil {
-			p.Status = status
+		line := domain.StockLine{ProductID: productID, Quantity: l.Quantity}
+		if l.VariantId != "" {
+			variantID, err := primitive.ObjectIDFromHex(l.VariantId)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid variant id: "+l.VariantId)
+			}
+			line.VariantID = &variantID
 		}
-		prodResp = append(prodResp, p)
+		lines = append(lines, line)
 	}
 
-	return &product.ProductsResponse{Products: prodResp}, nil
+	if cerr := s.inventory.ReserveStock(ctx, req.OrderId, userID, lines); cerr != nil {
+		logger.Error("Failed to reserve stock", zap.Error(cerr))
+		return nil, statusFromCError(cerr)
+	}
+
+	return &product.StockResponse{}, nil
+}
+
+func (s *grpcServer) ReleaseStock(ctx context.Context, req *product.ReleaseStockRequest) (*product.StockResponse, error) {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received ReleaseStock request", zap.String("order_id", req.OrderId))
+
+	userID, err := primitive.ObjectIDFromHex(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	if cerr := s.inventory.ReleaseStock(ctx, req.OrderId, userID); cerr != nil {
+		logger.Error("Failed to release stock", zap.Error(cerr))
+		return nil, statusFromCError(cerr)
+	}
+
+	return &product.StockResponse{}, nil
+}
+
+// WatchProducts streams the changes made to products until the client goes away. The resume token
+// of the last change received lets a client that reconnects carry on without missing changes
+func (s *grpcServer) WatchProducts(req *product.WatchProductsRequest, stream product.Product_WatchProductsServer) error {
+	logger := zap.L().Named("grpc_server")
+	logger.Info("Received WatchProducts request", zap.String("resume_token", req.ResumeToken))
+
+	cerr := s.feed.WatchProducts(stream.Context(), req.ResumeToken, func(change *domain.ProductChange) error {
+		return stream.Send(productChangeToProto(change))
+	})
+	if cerr != nil {
+		logger.Error("Failed to watch products", zap.Error(cerr))
+		return statusFromCError(cerr)
+	}
+
+	return nil
+}
+
+// statusFromCError converts a custom error into a gRPC status carrying its message
+func statusFromCError(cerr domain.CError) error {
+	switch cerr.Code() {
+	case http.StatusBadRequest:
+		return status.Error(codes.InvalidArgument, cerr.Error())
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, cerr.Error())
+	case http.StatusConflict:
+		return status.Error(codes.FailedPrecondition, cerr.Error())
+	case http.StatusGone:
+		return status.Error(codes.OutOfRange, cerr.Error())
+	default:
+		return status.Error(codes.Internal, cerr.Error())
+	}
+}
+
+// productToProto maps a product to its gRPC response
+func productToProto(prod *domain.Product) *product.ProductResponse {
+	resp := &product.ProductResponse{
+		Id:          prod.ID.Hex(),
+		Name:        prod.Name,
+		Description: prod.Description,
+		Price:       prod.Price,
+		Quantity:    prod.Quantity,
+		OwnerId:     prod.OwnerID.Hex(),
+		OwnerName:   prod.OwnerName,
+		OwnerPhone:  prod.OwnerPhone,
+		OwnerEmail:  prod.OwnerEmail,
+		CreatedAt:   prod.CreatedAt.String(),
+		UpdatedAt:   prod.UpdatedAt.String(),
+		Variants:    variantsToProto(prod),
+		Images:      imagesToProto(prod),
+		Components:  componentsToProto(prod),
+	}
+
+	if status, err := product.StringToProductStatus(prod.Status.String()); err == nil {
+		resp.Status = status
+	}
+
+	return resp
+}
+
+// productChangeToProto maps a change of the product feed
+func productChangeToProto(change *domain.ProductChange) *product.ProductChange {
+	resp := &product.ProductChange{
+		ResumeToken: change.Token,
+		ProductId:   change.ProductID.Hex(),
+		OccurredAt:  change.OccurredAt.String(),
+	}
+
+	switch change.Type {
+	case domain.ProductChangeCreated:
+		resp.Type = product.ProductChangeType_PRODUCT_CHANGE_TYPE_CREATED
+	case domain.ProductChangeUpdated:
+		resp.Type = product.ProductChangeType_PRODUCT_CHANGE_TYPE_UPDATED
+	case domain.ProductChangeDeleted:
+		resp.Type = product.ProductChangeType_PRODUCT_CHANGE_TYPE_DELETED
+	}
+
+	if change.Product != nil {
+		resp.Product = productToProto(change.Product)
+	}
+
+	return resp
+}
+
+// variantsToProto maps the variants of a product, resolving the price of each variant
+func variantsToProto(prod *domain.Product) []*product.ProductVariant {
+	variants := make([]*product.ProductVariant, 0, len(prod.Variants))
+	for _, v := range prod.Variants {
+		variants = append(variants, &product.ProductVariant{
+			Id:       v.ID.Hex(),
+			Sku:      v.SKU,
+			Options:  v.Options,
+			Price:    v.EffectivePrice(prod.Price),
+			Quantity: v.Quantity,
+		})
+	}
+	return variants
+}
+
+// imagesToProto maps the images of a product in display order
+func imagesToProto(prod *domain.Product) []*product.ProductImage {
+	images := make([]*product.ProductImage, 0, len(prod.Images))
+	for _, img := range prod.Images {
+		images = append(images, &product.ProductImage{
+			Id:           img.ID.Hex(),
+			Url:          img.URL,
+			ThumbnailUrl: img.ThumbnailURL,
+			Width:        int32(img.Width),
+			Height:       int32(img.Height),
+		})
+	}
+	return images
+}
+
+// componentsToProto maps the components of a bundle. Other products have none
+func componentsToProto(prod *domain.Product) []*product.BundleComponent {
+	if prod.Bundle == nil {
+		return nil
+	}
+
+	components := make([]*product.BundleComponent, 0, len(prod.Bundle.Components))
+	for _, c := range prod.Bundle.Components {
+		component := &product.BundleComponent{
+			ProductId: c.ProductID.Hex(),
+			Name:      c.Name,
+			Sku:       c.SKU,
+			Quantity:  c.Quantity,
+		}
+		if c.VariantID != nil {
+			component.VariantId = c.VariantID.Hex()
+		}
+		components = append(components, component)
+	}
+	return components
 }
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"testing"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStockChanges(t *testing.T) {
+	small, large, added := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	plain := &domain.Product{Quantity: 10}
+	withVariants := &domain.Product{Variants: []domain.Variant{
+		{ID: small, Quantity: 3},
+		{ID: large, Quantity: 4},
+	}}
+
+	// movement is the part of a stock movement an update decides
+	type movement struct {
+		variantID *primitive.ObjectID
+		kind      domain.MovementType
+		delta     int32
+	}
+
+	tests := []struct {
+		name          string
+		prod          *domain.Product
+		variants      []domain.Variant
+		quantity      int32
+		wantQuantity  int32
+		wantMovements []movement
+		wantErr       bool
+	}{
+		{
+			name:         "unchanged product without variants",
+			prod:         plain,
+			quantity:     10,
+			wantQuantity: 10,
+		},
+		{
+			name:     "quantity of a product without variants changed",
+			prod:     plain,
+			quantity: 12,
+			wantErr:  true,
+		},
+		{
+			name:         "unchanged variants",
+			prod:         withVariants,
+			variants:     []domain.Variant{{ID: small, Quantity: 3}, {ID: large, Quantity: 4}},
+			wantQuantity: 7,
+		},
+		{
+			name:     "quantity of a variant changed",
+			prod:     withVariants,
+			variants: []domain.Variant{{ID: small, Quantity: 5}, {ID: large, Quantity: 4}},
+			wantErr:  true,
+		},
+		{
+			name:          "variant added with its stock",
+			prod:          withVariants,
+			variants:      []domain.Variant{{ID: small, Quantity: 3}, {ID: large, Quantity: 4}, {ID: added, Quantity: 2}},
+			wantQuantity:  9,
+			wantMovements: []movement{{variantID: &added, kind: domain.MovementInitial, delta: 2}},
+		},
+		{
+			name:          "variant removed with its stock",
+			prod:          withVariants,
+			variants:      []domain.Variant{{ID: small, Quantity: 3}},
+			wantQuantity:  3,
+			wantMovements: []movement{{variantID: &large, kind: domain.MovementRemoval, delta: -4}},
+		},
+		{
+			name:         "stock of a product moved to its first variants",
+			prod:         plain,
+			variants:     []domain.Variant{{ID: added, Quantity: 6}},
+			wantQuantity: 6,
+			wantMovements: []movement{
+				{variantID: &added, kind: domain.MovementInitial, delta: 6},
+				{kind: domain.MovementRemoval, delta: -10},
+			},
+		},
+		{
+			name:         "all variants removed",
+			prod:         withVariants,
+			quantity:     5,
+			wantQuantity: 5,
+			wantMovements: []movement{
+				{variantID: &small, kind: domain.MovementRemoval, delta: -3},
+				{variantID: &large, kind: domain.MovementRemoval, delta: -4},
+				{kind: domain.MovementInitial, delta: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quantity, movements, cerr := stockChanges(tt.prod, tt.variants, tt.quantity)
+			if tt.wantErr {
+				if cerr == nil {
+					t.Fatalf("stockChanges() = %d, %+v, want an error", quantity, movements)
+				}
+				return
+			}
+			if cerr != nil {
+				t.Fatalf("stockChanges() failed: %v", cerr)
+			}
+
+			if quantity != tt.wantQuantity {
+				t.Errorf("stockChanges() quantity = %d, want %d", quantity, tt.wantQuantity)
+			}
+
+			if len(movements) != len(tt.wantMovements) {
+				t.Fatalf("stockChanges() movements = %+v, want %+v", movements, tt.wantMovements)
+			}
+			for i, m := range movements {
+				want := tt.wantMovements[i]
+				if !sameObjectID(m.VariantID, want.variantID) || m.Type != want.kind || m.Delta != want.delta {
+					t.Errorf("movement %d = %+v, want %+v", i, m, want)
+				}
+			}
+		})
+	}
+}
+
+func sameObjectID(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
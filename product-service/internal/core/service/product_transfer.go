@@ -0,0 +1,592 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// importProgressInterval is the number of rows imported between saves of the job progress
+const importProgressInterval = 100
+
+// importReason is the reason of the stock counts made by imports
+const importReason = "bulk import"
+
+// importRecord is a row read from an import file, or the reason it could not be read
+type importRecord struct {
+	row  int
+	data domain.ImportRow
+	err  string
+}
+
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importUpdated
+	importUnchanged
+)
+
+func (ps *ProductService) ImportProducts(ctx context.Context, userID primitive.ObjectID, format domain.TransferFormat, data []byte) (*domain.ImportJob, domain.CError) {
+	var records []importRecord
+	var cerr domain.CError
+	switch format {
+	case domain.TransferFormatCSV:
+		records, cerr = parseCSVImport(data)
+	case domain.TransferFormatNDJSON:
+		records, cerr = parseNDJSONImport(data)
+	default:
+		return nil, domain.NewBadRequestCError("invalid import format: " + string(format))
+	}
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if len(records) == 0 {
+		return nil, domain.NewBadRequestCError("the file has no products")
+	}
+
+	job, cerr := ps.jobRepo.CreateImportJob(ctx, &domain.ImportJob{
+		Format:    format,
+		Status:    domain.ImportJobStatusPending,
+		CreatedBy: userID,
+		Total:     len(records),
+		Errors:    make([]domain.ImportRowError, 0),
+	})
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error creating import job", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	// The job outlives the request, and is updated by it while the response is written
+	running := *job
+	go ps.runImport(context.WithoutCancel(ctx), &running, records)
+
+	return job, nil
+}
+
+func (ps *ProductService) GetImportJob(ctx context.Context, id primitive.ObjectID) (*domain.ImportJob, domain.CError) {
+	job, cerr := ps.jobRepo.GetImportJob(ctx, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting import job", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return job, nil
+}
+
+// runImport imports the rows of a job one after the other, saving its progress as it goes
+func (ps *ProductService) runImport(ctx context.Context, job *domain.ImportJob, records []importRecord) {
+	log := logger.FromCtx(ctx).With(zap.String("job_id", job.ID.Hex()))
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Product import stopped unexpectedly", zap.Any("panic", r))
+			job.Status = domain.ImportJobStatusFailed
+			job.Error = "the import stopped unexpectedly"
+			ps.finishImport(ctx, job)
+		}
+	}()
+
+	job.Status = domain.ImportJobStatusRunning
+	ps.saveImportJob(ctx, job)
+
+	validate := validator.New()
+	for i := range records {
+		outcome, cerr := ps.importRow(ctx, job, validate, &records[i])
+		if cerr != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, domain.ImportRowError{
+				Row:     records[i].row,
+				ID:      records[i].data.ID,
+				SKU:     records[i].data.SKU,
+				Message: cerr.Error(),
+			})
+		} else {
+			switch outcome {
+			case importCreated:
+				job.Created++
+			case importUpdated:
+				job.Updated++
+			case importUnchanged:
+				job.Unchanged++
+			}
+		}
+
+		job.Processed++
+		if job.Processed%importProgressInterval == 0 {
+			ps.saveImportJob(ctx, job)
+		}
+	}
+
+	job.Status = domain.ImportJobStatusCompleted
+	ps.finishImport(ctx, job)
+
+	log.Info("Finished product import", zap.Int("created", job.Created), zap.Int("updated", job.Updated),
+		zap.Int("unchanged", job.Unchanged), zap.Int("failed", job.Failed))
+}
+
+func (ps *ProductService) finishImport(ctx context.Context, job *domain.ImportJob) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	ps.saveImportJob(ctx, job)
+}
+
+// saveImportJob saves the progress of a job. Failures are only logged, so they do not stop the import
+func (ps *ProductService) saveImportJob(ctx context.Context, job *domain.ImportJob) {
+	if cerr := ps.jobRepo.UpdateImportJob(ctx, job); cerr != nil {
+		logger.FromCtx(ctx).Error("Error saving import job", zap.String("job_id", job.ID.Hex()), zap.Error(cerr))
+	}
+}
+
+// importRow creates or updates the product of a row
+func (ps *ProductService) importRow(ctx context.Context, job *domain.ImportJob, validate *validator.Validate, rec *importRecord) (importOutcome, domain.CError) {
+	if rec.err != "" {
+		return 0, domain.NewBadRequestCError(rec.err)
+	}
+
+	row := &rec.data
+	row.ID = strings.TrimSpace(row.ID)
+	row.SKU = strings.TrimSpace(row.SKU)
+	if err := validate.Struct(row); err != nil {
+		return 0, domain.NewBadRequestCError(validationMessage(err))
+	}
+
+	existing, cerr := ps.findImportTarget(ctx, row)
+	if cerr != nil {
+		return 0, cerr
+	}
+
+	if existing == nil {
+		return importCreated, ps.importNewProduct(ctx, job, row)
+	}
+
+	return ps.importExistingProduct(ctx, job, row, existing)
+}
+
+// findImportTarget returns the product a row updates, or nil when the row creates a new product
+func (ps *ProductService) findImportTarget(ctx context.Context, row *domain.ImportRow) (*domain.Product, domain.CError) {
+	if row.ID != "" {
+		id, _ := primitive.ObjectIDFromHex(row.ID)
+		prod, cerr := ps.getProduct(ctx, id)
+		if cerr == domain.ErrDataNotFound {
+			return nil, domain.NewBadRequestCError("product not found: " + row.ID)
+		}
+		return prod, cerr
+	}
+
+	if row.SKU == "" {
+		return nil, nil
+	}
+
+	prod, cerr := ps.repo.GetProductBySKU(ctx, row.SKU)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, nil
+		}
+		logger.FromCtx(ctx).Error("Error getting product by sku", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return prod, nil
+}
+
+func (ps *ProductService) importNewProduct(ctx context.Context, job *domain.ImportJob, row *domain.ImportRow) domain.CError {
+	if row.Price == nil {
+		return domain.NewBadRequestCError("price is required for a new product")
+	}
+
+	req := domain.CreateProductRequest{
+		Name:        row.Name,
+		SKU:         row.SKU,
+		Description: row.Description,
+		Price:       *row.Price,
+	}
+
+	if row.Variants != nil && len(*row.Variants) > 0 {
+		req.Variants = *row.Variants
+	} else if row.Quantity == nil {
+		return domain.NewBadRequestCError("quantity is required for a new product without variants")
+	} else {
+		req.Quantity = *row.Quantity
+	}
+
+	if row.CategoryIDs != nil {
+		req.CategoryIDs = *row.CategoryIDs
+	}
+
+	if row.Status == domain.ProductStatusInactive.String() {
+		req.Status = domain.ProductStatusInactive
+	}
+
+	_, cerr := ps.createProduct(ctx, &req, job.CreatedBy, nil)
+	return cerr
+}
+
+func (ps *ProductService) importExistingProduct(ctx context.Context, job *domain.ImportJob, row *domain.ImportRow, existing *domain.Product) (importOutcome, domain.CError) {
+	req := domain.UpdateProductRequest{
+		Name:        row.Name,
+		Description: row.Description,
+		Price:       existing.Price,
+		Quantity:    existing.Quantity,
+		Status:      existing.Status.String(),
+		CategoryIDs: row.CategoryIDs,
+	}
+
+	if row.Price != nil {
+		req.Price = *row.Price
+	}
+
+	var counts []domain.AdjustStockRequest
+	if row.Variants != nil {
+		variants, variantCounts := variantStockCounts(existing, *row.Variants)
+		req.Variants, counts = &variants, variantCounts
+	}
+
+	hasVariants := len(existing.Variants) > 0
+	if row.Variants != nil {
+		hasVariants = len(*row.Variants) > 0
+	}
+
+	// The SKU of a row matched to a variant is not the SKU of its product
+	if row.SKU != "" && !hasVariants {
+		req.SKU = &row.SKU
+	}
+
+	switch row.Status {
+	case domain.ProductStatusInactive.String():
+		req.Status = row.Status
+	case domain.ProductStatusActive.String(), domain.ProductStatusOutOfStock.String():
+		if existing.Status == domain.ProductStatusInactive {
+			req.Status = domain.ProductStatusActive.String()
+		}
+	}
+
+	outcome := importUpdated
+	prod, cerr := ps.UpdateProduct(ctx, existing.ID, job.CreatedBy, &existing.Version, &req)
+	if cerr == errNoChanges {
+		outcome, prod = importUnchanged, existing
+	} else if cerr != nil {
+		return 0, cerr
+	}
+
+	if row.Quantity != nil && len(prod.Variants) == 0 && *row.Quantity != prod.Quantity {
+		_, cerr := ps.AdjustStock(ctx, prod.ID, job.CreatedBy, &domain.AdjustStockRequest{
+			Type:        string(domain.MovementStockCount),
+			Quantity:    row.Quantity,
+			Reason:      importReason,
+			ReferenceID: job.ID.Hex(),
+		})
+		if cerr != nil {
+			return 0, cerr
+		}
+		outcome = importUpdated
+	}
+
+	for i := range counts {
+		counts[i].Reason = importReason
+		counts[i].ReferenceID = job.ID.Hex()
+		if _, cerr := ps.AdjustStock(ctx, prod.ID, job.CreatedBy, &counts[i]); cerr != nil {
+			return 0, cerr
+		}
+		outcome = importUpdated
+	}
+
+	return outcome, nil
+}
+
+// variantStockCounts keeps the stock of the existing variants of a row, as an update cannot change
+// it, and returns the stock counts setting those whose quantity differs. New variants keep their
+// quantity as their opening stock
+func variantStockCounts(existing *domain.Product, reqs []domain.VariantRequest) ([]domain.VariantRequest, []domain.AdjustStockRequest) {
+	stock := make(map[primitive.ObjectID]int32, len(existing.Variants))
+	for _, v := range existing.Variants {
+		stock[v.ID] = v.Quantity
+	}
+
+	variants := make([]domain.VariantRequest, len(reqs))
+	var counts []domain.AdjustStockRequest
+	for i, req := range reqs {
+		variants[i] = req
+
+		id, err := primitive.ObjectIDFromHex(req.ID)
+		quantity, ok := stock[id]
+		if err != nil || !ok || req.Quantity == quantity {
+			continue
+		}
+
+		counted := req.Quantity
+		counts = append(counts, domain.AdjustStockRequest{
+			VariantID: id.Hex(),
+			Type:      string(domain.MovementStockCount),
+			Quantity:  &counted,
+		})
+		variants[i].Quantity = quantity
+	}
+
+	return variants, counts
+}
+
+// validationMessage joins the messages of a validation error
+func validationMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err.Error()
+	}
+
+	msgs := make([]string, 0, len(validationErrs))
+	for _, e := range validationErrs {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, " - ")
+}
+
+// parseCSVImport reads the rows of a CSV import. The first line is the header naming the columns,
+// which can be in any order. Rows that cannot be read are kept with the reason, to be reported
+func parseCSVImport(data []byte) ([]importRecord, domain.CError) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, domain.NewBadRequestCError("the file is empty")
+		}
+		return nil, domain.NewBadRequestCError("invalid csv header: " + err.Error())
+	}
+
+	known := make(map[string]bool, len(domain.TransferColumns))
+	for _, name := range domain.TransferColumns {
+		known[name] = true
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !known[name] {
+			return nil, domain.NewBadRequestCError("unknown column: " + name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, domain.NewBadRequestCError("duplicate column: " + name)
+		}
+		columns[name] = i
+	}
+
+	for _, name := range []string{"name", "description", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, domain.NewBadRequestCError("missing column: " + name)
+		}
+	}
+
+	records := make([]importRecord, 0)
+	for row := 1; ; row++ {
+		fields, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if row > domain.MaxImportRows {
+			return nil, domain.NewBadRequestCError(fmt.Sprintf("the file has more than %d products", domain.MaxImportRows))
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				err = parseErr.Err
+			}
+			records = append(records, importRecord{row: row, err: "invalid csv row: " + err.Error()})
+			continue
+		}
+
+		rec := importRecord{row: row}
+		rec.data, rec.err = csvImportRow(columns, fields)
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// csvImportRow converts the fields of a CSV row to an import row
+func csvImportRow(columns map[string]int, fields []string) (domain.ImportRow, string) {
+	get := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok {
+			return "", false
+		}
+		return strings.TrimSpace(fields[i]), true
+	}
+
+	var row domain.ImportRow
+	row.ID, _ = get("id")
+	row.SKU, _ = get("sku")
+	row.Name, _ = get("name")
+	row.Description, _ = get("description")
+	row.Status, _ = get("status")
+
+	if price, ok := get("price"); ok && price != "" {
+		parsedPrice, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			return row, "price must be a number"
+		}
+		row.Price = &parsedPrice
+	}
+
+	if quantity, ok := get("quantity"); ok && quantity != "" {
+		parsedQuantity, err := strconv.ParseInt(quantity, 10, 32)
+		if err != nil {
+			return row, "quantity must be a whole number"
+		}
+		q := int32(parsedQuantity)
+		row.Quantity = &q
+	}
+
+	// An empty cell removes all the categories of the product
+	if categories, ok := get("category_ids"); ok {
+		categoryIDs := make([]string, 0)
+		for _, id := range strings.Split(categories, "|") {
+			if id = strings.TrimSpace(id); id != "" {
+				categoryIDs = append(categoryIDs, id)
+			}
+		}
+		row.CategoryIDs = &categoryIDs
+	}
+
+	return row, ""
+}
+
+// parseNDJSONImport reads the rows of an NDJSON import, one JSON object per line. Rows are numbered
+// by line and blank lines are skipped
+func parseNDJSONImport(data []byte) ([]importRecord, domain.CError) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), int(domain.MaxImportSize))
+
+	records := make([]importRecord, 0)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		if len(records) == domain.MaxImportRows {
+			return nil, domain.NewBadRequestCError(fmt.Sprintf("the file has more than %d products", domain.MaxImportRows))
+		}
+
+		rec := importRecord{row: line}
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec.data); err != nil {
+			rec.err = "invalid json: " + err.Error()
+		} else if dec.More() {
+			rec.err = "invalid json: a line must hold a single object"
+		}
+		records = append(records, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, domain.NewBadRequestCError("invalid ndjson file: " + err.Error())
+	}
+
+	return records, nil
+}
+
+func (ps *ProductService) ExportProducts(ctx context.Context, format domain.TransferFormat, w io.Writer) domain.CError {
+	var write func(*domain.Product) error
+	var flush func() error
+
+	switch format {
+	case domain.TransferFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(domain.TransferColumns); err != nil {
+			return domain.NewInternalCError(err.Error())
+		}
+		write = func(prod *domain.Product) error {
+			return cw.Write(exportCSVRecord(exportRow(prod)))
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case domain.TransferFormatNDJSON:
+		enc := json.NewEncoder(w)
+		write = func(prod *domain.Product) error {
+			return enc.Encode(exportRow(prod))
+		}
+		flush = func() error { return nil }
+	default:
+		return domain.NewBadRequestCError("invalid export format: " + string(format))
+	}
+
+	cerr := ps.repo.ExportProducts(ctx, write)
+	if err := flush(); err != nil && cerr == nil {
+		cerr = domain.NewInternalCError(err.Error())
+	}
+
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error exporting products", zap.Error(cerr))
+		return domain.ErrInternal
+	}
+
+	return nil
+}
+
+// exportRow converts a product to the row an import reads back
+func exportRow(prod *domain.Product) *domain.ExportRow {
+	row := domain.ExportRow{
+		ID:          prod.ID.Hex(),
+		SKU:         prod.SKU,
+		Name:        prod.Name,
+		Description: prod.Description,
+		Price:       prod.Price,
+		Quantity:    prod.Quantity,
+		Status:      prod.Status.String(),
+		CategoryIDs: make([]string, 0, len(prod.CategoryIDs)),
+	}
+
+	for _, id := range prod.CategoryIDs {
+		row.CategoryIDs = append(row.CategoryIDs, id.Hex())
+	}
+
+	for _, v := range prod.Variants {
+		row.Variants = append(row.Variants, domain.VariantRequest{
+			ID:       v.ID.Hex(),
+			SKU:      v.SKU,
+			Options:  v.Options,
+			Price:    v.Price,
+			Quantity: v.Quantity,
+		})
+	}
+
+	return &row
+}
+
+// exportCSVRecord converts an export row to CSV fields in the order of domain.TransferColumns.
+// Variants are only exported in NDJSON
+func exportCSVRecord(row *domain.ExportRow) []string {
+	return []string{
+		row.ID,
+		row.SKU,
+		row.Name,
+		row.Description,
+		strconv.FormatFloat(row.Price, 'f', -1, 64),
+		strconv.FormatInt(int64(row.Quantity), 10),
+		row.Status,
+		strings.Join(row.CategoryIDs, "|"),
+	}
+}
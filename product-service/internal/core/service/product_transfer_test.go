@@ -0,0 +1,159 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseCSVImport(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+	quantity := func(q int32) *int32 { return &q }
+
+	// row is the part of an import record the parsing decides
+	type row struct {
+		row      int
+		name     string
+		price    *float64
+		quantity *int32
+		err      string
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantRows []row
+		wantErr  string
+	}{
+		{
+			name: "columns in any order, with a byte order mark",
+			data: "\ufeffPrice,name,description,quantity\n9.5,Mug,A mug,4\n",
+			wantRows: []row{
+				{row: 1, name: "Mug", price: price(9.5), quantity: quantity(4)},
+			},
+		},
+		{
+			name: "price and quantity left out",
+			data: "name,description,price,quantity\nMug,A mug,,\n",
+			wantRows: []row{
+				{row: 1, name: "Mug"},
+			},
+		},
+		{
+			name: "rows that cannot be read are kept with the reason",
+			data: "name,description,price,quantity\nMug,A mug,cheap,1\nCup,A cup,2,1.5\nPlate,A plate,3\nBowl,A bowl,4,2\n",
+			wantRows: []row{
+				{row: 1, name: "Mug", err: "price must be a number"},
+				{row: 2, name: "Cup", price: price(2), err: "quantity must be a whole number"},
+				{row: 3, err: "invalid csv row: wrong number of fields"},
+				{row: 4, name: "Bowl", price: price(4), quantity: quantity(2)},
+			},
+		},
+		{name: "empty file", data: "", wantErr: "the file is empty"},
+		{name: "unknown column", data: "name,description,price,colour\n", wantErr: "unknown column: colour"},
+		{name: "duplicate column", data: "name,description,price,Name\n", wantErr: "duplicate column: name"},
+		{name: "missing column", data: "name,description\n", wantErr: "missing column: price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, cerr := parseCSVImport([]byte(tt.data))
+			if tt.wantErr != "" {
+				if cerr == nil || !strings.Contains(cerr.Error(), tt.wantErr) {
+					t.Fatalf("parseCSVImport() error = %v, want %q", cerr, tt.wantErr)
+				}
+				return
+			}
+			if cerr != nil {
+				t.Fatalf("parseCSVImport() failed: %v", cerr)
+			}
+
+			got := make([]row, 0, len(records))
+			for _, r := range records {
+				got = append(got, row{row: r.row, name: r.data.Name, price: r.data.Price, quantity: r.data.Quantity, err: r.err})
+			}
+			if !reflect.DeepEqual(got, tt.wantRows) {
+				t.Errorf("parseCSVImport() = %+v, want %+v", got, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestNDJSONRoundTripVariantStock(t *testing.T) {
+	small, large := primitive.NewObjectID(), primitive.NewObjectID()
+	prod := &domain.Product{
+		ID:     primitive.NewObjectID(),
+		Name:   "Shirt",
+		Price:  20,
+		Status: domain.ProductStatusActive,
+		Variants: []domain.Variant{
+			{ID: small, SKU: "SHIRT-S", Options: map[string]string{"size": "s"}, Quantity: 3},
+			{ID: large, SKU: "SHIRT-L", Options: map[string]string{"size": "l"}, Quantity: 5},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		edit       func(row *domain.ExportRow)
+		wantCounts map[primitive.ObjectID]int32
+	}{
+		{name: "unchanged export"},
+		{
+			name:       "variant quantity changed",
+			edit:       func(row *domain.ExportRow) { row.Variants[0].Quantity = 8 },
+			wantCounts: map[primitive.ObjectID]int32{small: 8},
+		},
+		{
+			name: "variant added",
+			edit: func(row *domain.ExportRow) {
+				row.Variants = append(row.Variants, domain.VariantRequest{SKU: "SHIRT-M", Options: map[string]string{"size": "m"}, Quantity: 2})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := exportRow(prod)
+			if tt.edit != nil {
+				tt.edit(row)
+			}
+
+			var buf bytes.Buffer
+			if err := json.NewEncoder(&buf).Encode(row); err != nil {
+				t.Fatal(err)
+			}
+			records, cerr := parseNDJSONImport(buf.Bytes())
+			if cerr != nil || len(records) != 1 || records[0].err != "" {
+				t.Fatalf("parseNDJSONImport() = %+v, %v, want the exported row", records, cerr)
+			}
+
+			variants, counts := variantStockCounts(prod, *records[0].data.Variants)
+
+			built, cerr := buildVariants(variants, prod.Variants)
+			if cerr != nil {
+				t.Fatalf("buildVariants() failed: %v", cerr)
+			}
+			if _, _, cerr := stockChanges(prod, built, prod.Quantity); cerr != nil {
+				t.Fatalf("stockChanges() failed: %v", cerr)
+			}
+
+			got := make(map[primitive.ObjectID]int32, len(counts))
+			for _, c := range counts {
+				id, err := primitive.ObjectIDFromHex(c.VariantID)
+				if err != nil || c.Type != string(domain.MovementStockCount) || c.Quantity == nil {
+					t.Fatalf("variantStockCounts() count = %+v, want a stock count of a variant", c)
+				}
+				got[id] = *c.Quantity
+			}
+			if len(got) != len(tt.wantCounts) || (len(got) > 0 && !reflect.DeepEqual(got, tt.wantCounts)) {
+				t.Errorf("variantStockCounts() counts = %v, want %v", got, tt.wantCounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// recommendationCandidates is the number of products bought together asked from the order-service, more
+// than are returned since those that cannot be bought now are left out
+const recommendationCandidates = 50
+
+// GetRecommendations returns the products most often bought together with a product, from the most
+// often, leaving out the products that are inactive or out of stock
+func (ps *ProductService) GetRecommendations(ctx context.Context, id primitive.ObjectID, limit int) ([]domain.Recommendation, domain.CError) {
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	if limit <= 0 {
+		limit = domain.DefaultRecommendations
+	}
+	limit = min(limit, domain.MaxRecommendations)
+
+	candidates, cerr := ps.frequentlyBoughtTogether(ctx, id, recommendationCandidates)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	recommendations := make([]domain.Recommendation, 0, limit)
+	if len(candidates) == 0 {
+		return recommendations, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(candidates))
+	for _, c := range candidates {
+		candidateID, err := primitive.ObjectIDFromHex(c.ProductId)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, candidateID)
+	}
+
+	prods, cerr := ps.repo.GetProductsByIDs(ctx, ids)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting recommended products", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	products := make(map[string]*domain.Product, len(prods))
+	for i := range prods {
+		products[prods[i].ID.Hex()] = &prods[i]
+	}
+
+	for _, c := range candidates {
+		prod, ok := products[c.ProductId]
+		if !ok || prod.Status != domain.ProductStatusActive || prod.Quantity <= 0 {
+			continue
+		}
+
+		recommendations = append(recommendations, domain.Recommendation{Product: prod, Orders: c.Orders})
+		if len(recommendations) == limit {
+			break
+		}
+	}
+
+	return recommendations, nil
+}
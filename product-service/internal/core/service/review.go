@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// errReviewChanged is returned when a review changed between being read and being saved
+var errReviewChanged = domain.NewCError(http.StatusConflict, "the review changed while it was saved, try again")
+
+// CreateReview reviews a product. Only customers with a delivered order of the product can review it,
+// which is checked with the order-service
+func (ps *ProductService) CreateReview(ctx context.Context, id, userID primitive.ObjectID, req *domain.ReviewRequest) (*domain.Review, domain.CError) {
+	log := logger.FromCtx(ctx)
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	delivered, cerr := ps.hasDeliveredProduct(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+	if !delivered {
+		return nil, domain.NewCError(http.StatusForbidden, "only customers with a delivered order of the product can review it")
+	}
+
+	review, cerr := ps.reviewRepo.CreateReview(ctx, &domain.Review{
+		ProductID: id,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Text:      req.Text,
+		Status:    domain.ReviewStatusPublished,
+	})
+	if cerr != nil {
+		if cerr == domain.ErrConflictingData {
+			return nil, domain.NewCError(http.StatusConflict, "you have already reviewed this product, update your review instead")
+		}
+		log.Error("Error creating review", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	ps.adjustRating(ctx, nil, review)
+	return review, nil
+}
+
+func (ps *ProductService) ListReviews(ctx context.Context, id primitive.ObjectID, includeHidden bool, before *primitive.ObjectID, limit int64) (*domain.ReviewPage, domain.CError) {
+	if _, cerr := ps.getProduct(ctx, id); cerr != nil {
+		return nil, cerr
+	}
+
+	if limit < 1 {
+		limit = domain.DefaultPageSize
+	}
+	if limit > domain.MaxPageSize {
+		limit = domain.MaxPageSize
+	}
+
+	reviews, cerr := ps.reviewRepo.ListReviews(ctx, id, includeHidden, before, limit+1)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing reviews", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	page := domain.ReviewPage{Reviews: reviews}
+	if int64(len(reviews)) > limit {
+		page.Reviews = reviews[:limit]
+		page.NextBefore = page.Reviews[limit-1].ID.Hex()
+	}
+
+	return &page, nil
+}
+
+func (ps *ProductService) UpdateReview(ctx context.Context, id, reviewID, userID primitive.ObjectID, req *domain.ReviewRequest) (*domain.Review, domain.CError) {
+	review, cerr := ps.getOwnReview(ctx, id, reviewID, userID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	updated := *review
+	updated.Rating = req.Rating
+	updated.Text = req.Text
+
+	return ps.saveReview(ctx, &updated, review)
+}
+
+func (ps *ProductService) DeleteReview(ctx context.Context, id, reviewID, userID primitive.ObjectID) domain.CError {
+	review, cerr := ps.getOwnReview(ctx, id, reviewID, userID)
+	if cerr != nil {
+		return cerr
+	}
+
+	if cerr := ps.reviewRepo.DeleteReview(ctx, review); cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return errReviewChanged
+		}
+		logger.FromCtx(ctx).Error("Error deleting review", zap.Error(cerr))
+		return domain.ErrInternal
+	}
+
+	ps.adjustRating(ctx, review, nil)
+	return nil
+}
+
+// ModerateReview hides a review, taking it out of the rating of the product, or publishes a hidden
+// review again
+func (ps *ProductService) ModerateReview(ctx context.Context, id, reviewID, adminID primitive.ObjectID, req *domain.ModerateReviewRequest) (*domain.Review, domain.CError) {
+	review, cerr := ps.getReview(ctx, id, reviewID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	updated := *review
+	updated.Status = domain.ReviewStatus(req.Status)
+	updated.ModeratedBy = &adminID
+	updated.ModerationNote = req.Note
+
+	return ps.saveReview(ctx, &updated, review)
+}
+
+func (ps *ProductService) getReview(ctx context.Context, id, reviewID primitive.ObjectID) (*domain.Review, domain.CError) {
+	review, cerr := ps.reviewRepo.GetReview(ctx, id, reviewID)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting review", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return review, nil
+}
+
+// getOwnReview gets a review the user wrote. Reviews are public, so the reviews of other users are
+// forbidden rather than hidden
+func (ps *ProductService) getOwnReview(ctx context.Context, id, reviewID, userID primitive.ObjectID) (*domain.Review, domain.CError) {
+	review, cerr := ps.getReview(ctx, id, reviewID)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if review.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	return review, nil
+}
+
+// saveReview saves a change to a review and applies it to the rating of the product
+func (ps *ProductService) saveReview(ctx context.Context, review, previous *domain.Review) (*domain.Review, domain.CError) {
+	updated, cerr := ps.reviewRepo.UpdateReview(ctx, review, previous)
+	if cerr != nil {
+		if cerr == domain.ErrDataNotFound {
+			return nil, errReviewChanged
+		}
+		logger.FromCtx(ctx).Error("Error updating review", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	ps.adjustRating(ctx, previous, updated)
+	return updated, nil
+}
+
+// adjustRating applies the change of a review to the rating of its product, from before to after. A
+// review that is created has no before and one that is deleted has no after. The review is already
+// saved, so a failure is only logged
+func (ps *ProductService) adjustRating(ctx context.Context, before, after *domain.Review) {
+	var count, total int64
+	var productID primitive.ObjectID
+
+	if before != nil {
+		productID = before.ProductID
+		if before.Status == domain.ReviewStatusPublished {
+			count--
+			total -= int64(before.Rating)
+		}
+	}
+	if after != nil {
+		productID = after.ProductID
+		if after.Status == domain.ReviewStatusPublished {
+			count++
+			total += int64(after.Rating)
+		}
+	}
+
+	if count == 0 && total == 0 {
+		return
+	}
+
+	if cerr := ps.repo.AdjustRating(ctx, productID, count, total); cerr != nil {
+		logger.FromCtx(ctx).Error("Error adjusting product rating", zap.String("product_id", productID.Hex()), zap.Error(cerr))
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"product-service/internal/core/domain"
+	userv2 "product-service/internal/core/service/user/v2"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTokenVerifier verifies every token locally, or fails every verification with cerr
+type fakeTokenVerifier struct {
+	cerr domain.CError
+}
+
+func (v fakeTokenVerifier) VerifyToken(tokenString string) (domain.Claims, domain.CError) {
+	if v.cerr != nil {
+		return domain.Claims{}, v.cerr
+	}
+	return domain.Claims{ID: "token-id"}, nil
+}
+
+// fakeUserClient answers the token checks of the owner-service with err
+type fakeUserClient struct {
+	userv2.UserClient
+	err   error
+	calls int
+}
+
+func (c *fakeUserClient) VerifyToken(ctx context.Context, in *userv2.VerifyTokenRequest, opts ...grpc.CallOption) (*userv2.VerifyTokenResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &userv2.VerifyTokenResponse{UserId: "user-id"}, nil
+}
+
+func TestTokenServiceVerifyToken(t *testing.T) {
+	const token = "header.payload.signature"
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	revoked := status.Error(codes.Unauthenticated, "access token has been revoked")
+
+	tests := []struct {
+		name        string
+		localErr    domain.CError
+		last        *tokenCheck
+		ownerErr    error
+		wantErr     domain.CError
+		wantCalls   int
+		wantRevoked bool
+	}{
+		{name: "unchecked token accepted by the owner-service", wantCalls: 1},
+		{name: "unchecked token revoked", ownerErr: revoked, wantErr: domain.ErrRevokedToken, wantCalls: 1, wantRevoked: true},
+		{name: "invalid token is not checked", localErr: domain.ErrExpiredToken, wantErr: domain.ErrExpiredToken},
+		{
+			name: "fresh check is reused",
+			last: &tokenCheck{checkedAt: now.Add(-tokenCheckTTL + time.Second)},
+		},
+		{
+			name:        "fresh revocation is reused",
+			last:        &tokenCheck{revoked: true, checkedAt: now.Add(-time.Second)},
+			wantErr:     domain.ErrRevokedToken,
+			wantRevoked: true,
+		},
+		{
+			name:      "expired check is checked again",
+			last:      &tokenCheck{checkedAt: now.Add(-tokenCheckTTL)},
+			wantCalls: 1,
+		},
+		{
+			name:        "token revoked since the last check",
+			last:        &tokenCheck{checkedAt: now.Add(-time.Minute)},
+			ownerErr:    revoked,
+			wantErr:     domain.ErrRevokedToken,
+			wantCalls:   1,
+			wantRevoked: true,
+		},
+		{
+			name:      "owner-service down within the grace period",
+			last:      &tokenCheck{checkedAt: now.Add(-tokenCheckGrace + time.Second)},
+			ownerErr:  unavailable,
+			wantCalls: 1,
+		},
+		{
+			name:      "owner-service down past the grace period",
+			last:      &tokenCheck{checkedAt: now.Add(-tokenCheckGrace)},
+			ownerErr:  unavailable,
+			wantErr:   domain.ErrAuthUnavailable,
+			wantCalls: 1,
+		},
+		{
+			name:        "owner-service down after a revocation",
+			last:        &tokenCheck{revoked: true, checkedAt: now.Add(-time.Minute)},
+			ownerErr:    unavailable,
+			wantErr:     domain.ErrAuthUnavailable,
+			wantCalls:   1,
+			wantRevoked: true,
+		},
+		{name: "owner-service down", ownerErr: unavailable, wantErr: domain.ErrAuthUnavailable, wantCalls: 1},
+		{name: "owner-service too slow", ownerErr: status.Error(codes.DeadlineExceeded, "deadline exceeded"), wantErr: domain.ErrAuthUnavailable, wantCalls: 1},
+		{name: "service not allowed to check tokens", ownerErr: status.Error(codes.PermissionDenied, "denied"), wantErr: domain.ErrInternal, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeUserClient{err: tt.ownerErr}
+			ts := newTokenService(fakeTokenVerifier{cerr: tt.localErr}, client)
+			ts.now = func() time.Time { return now }
+
+			key := sha256.Sum256([]byte(token))
+			if tt.last != nil {
+				ts.checks[key] = *tt.last
+			}
+
+			claims, cerr := ts.VerifyToken(token)
+			if cerr != tt.wantErr {
+				t.Fatalf("VerifyToken() error = %v, want %v", cerr, tt.wantErr)
+			}
+			if cerr == nil && claims.ID != "token-id" {
+				t.Errorf("VerifyToken() claims = %+v, want the claims of the token", claims)
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("owner-service checks = %d, want %d", client.calls, tt.wantCalls)
+			}
+
+			if check, ok := ts.checks[key]; (ok || tt.wantRevoked) && check.revoked != tt.wantRevoked {
+				t.Errorf("cached check revoked = %v, want %v", check.revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestTokenServiceSaveCheck(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	ts := newTokenService(fakeTokenVerifier{}, &fakeUserClient{})
+
+	stale := sha256.Sum256([]byte("stale"))
+	ts.checks[stale] = tokenCheck{checkedAt: now.Add(-tokenCheckGrace)}
+	for i := 1; i < tokenCheckCacheSize; i++ {
+		ts.checks[sha256.Sum256([]byte{byte(i), byte(i >> 8)})] = tokenCheck{checkedAt: now.Add(-time.Second)}
+	}
+
+	key := sha256.Sum256([]byte("new"))
+	ts.saveCheck(key, tokenCheck{checkedAt: now})
+
+	if _, ok := ts.checks[stale]; ok {
+		t.Error("saveCheck() kept a check past the grace period in a full cache")
+	}
+	if _, ok := ts.checks[key]; !ok {
+		t.Error("saveCheck() did not save the new check")
+	}
+	if len(ts.checks) != tokenCheckCacheSize {
+		t.Errorf("saveCheck() left %d checks, want %d", len(ts.checks), tokenCheckCacheSize)
+	}
+}
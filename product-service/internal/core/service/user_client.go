@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+
+	"pkg/grpcauth"
 	"product-service/internal/adapter/config"
 	"product-service/internal/adapter/logger"
 	"product-service/internal/core/domain"
-	"product-service/internal/core/service/user"
+	userv2 "product-service/internal/core/service/user/v2"
 	"product-service/internal/core/util"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,37 +18,65 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func newUserClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, user.UserClient, error) {
-	conn, err := grpc.NewClient(conf.OwnerUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func newUserClient(conf *config.DiscoveryConfiguration) (*grpc.ClientConn, userv2.UserClient, error) {
+	conn, err := grpc.NewClient(conf.OwnerUrl,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(grpcauth.Token(conf.OwnerToken)),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create a new connection: %w", err)
 	}
 
-	userClient := user.NewUserClient(conn)
+	userClient := userv2.NewUserClient(conn)
 	return conn, userClient, nil
 }
 
-func (ps *ProductService) GetUser(ctx context.Context, userId primitive.ObjectID) (*user.UserResponse, domain.CError) {
-	log := logger.FromCtx(ctx)
-	userIdString := userId.Hex()
+// GetUser fetches the profile of a user
+func (ps *ProductService) GetUser(ctx context.Context, userId primitive.ObjectID) (*userv2.UserProfile, domain.CError) {
+	users, cerr := ps.GetUsers(ctx, []primitive.ObjectID{userId})
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	user, ok := users[userId.Hex()]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return user, nil
+}
 
-	log.Info("Checking if user exists in cache", zap.String("user_id", userIdString))
+// GetUsers fetches the profiles of users keyed by id. Profiles missing from the cache are fetched
+// from the owner-service in one request, and users that do not exist or were deleted are left out
+func (ps *ProductService) GetUsers(ctx context.Context, userIds []primitive.ObjectID) (map[string]*userv2.UserProfile, domain.CError) {
+	log := logger.FromCtx(ctx)
 
-	cacheKey := util.GenerateCacheKey("user", userIdString)
-	cachedUser, err := ps.cache.Get(ctx, cacheKey)
-	if err == nil {
-		log.Info("User with id found in cache", zap.String("user_id", userIdString))
+	users := make(map[string]*userv2.UserProfile, len(userIds))
+	missing := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		userIdString := userId.Hex()
+		if _, ok := users[userIdString]; ok || slices.Contains(missing, userIdString) {
+			continue
+		}
 
-		var user user.UserResponse
-		err := util.Deserialize(cachedUser, &user)
+		cachedUser, err := ps.cache.Get(ctx, util.GenerateCacheKey("user-profile", userIdString))
 		if err != nil {
+			missing = append(missing, userIdString)
+			continue
+		}
+
+		var user userv2.UserProfile
+		if err := util.Deserialize(cachedUser, &user); err != nil {
 			log.Error("Error deserializing found user in cache", zap.Error(err))
 			return nil, domain.ErrInternal
 		}
+		users[userIdString] = &user
+	}
 
-		return &user, nil
+	log.Info("Checked users in cache", zap.Int("found", len(users)), zap.Int("missing", len(missing)))
+	if len(missing) == 0 {
+		return users, nil
 	}
-	log.Info("User not found in cache")
 
 	grpcConn, grpcClient, err := newUserClient(&config.GetConfig().Discovery)
 	if err != nil {
@@ -54,31 +85,29 @@ func (ps *ProductService) GetUser(ctx context.Context, userId primitive.ObjectID
 	}
 	defer grpcConn.Close()
 
-	log.Info("Created new grpc user client")
-	log.Info("Making request to fetch user", zap.String("user_id", userIdString))
+	log.Info("Making request to fetch users", zap.Strings("user_ids", missing))
 
-	user, err := grpcClient.Get(context.Background(), &user.UserRequest{UserId: userIdString})
+	resp, err := grpcClient.GetMany(ctx, &userv2.GetUsersRequest{UserIds: missing})
 	if err != nil {
-		log.Error("Error fetching user", zap.Error(err))
+		log.Error("Error fetching users", zap.Error(err))
 		return nil, domain.ErrInternal
 	}
 
-	log.Info("Successfully fetched user")
-	log.Info("Saving returned user to cache")
+	log.Info("Successfully fetched users", zap.Int("count", len(resp.Users)))
 
-	serialUser, err := util.Serialize(user)
-	if err != nil {
-		log.Error("Error serializing user", zap.Error(err))
-		return nil, domain.ErrInternal
-	}
+	for _, user := range resp.Users {
+		users[user.Id] = user
 
-	err = ps.cache.Set(ctx, cacheKey, serialUser, ps.cacheTtl)
-	if err != nil {
-		log.Error("Error saving returned user to cache", zap.Error(err))
-		return nil, domain.ErrInternal
-	}
+		serialUser, err := util.Serialize(user)
+		if err != nil {
+			log.Error("Error serializing user", zap.Error(err))
+			continue
+		}
 
-	log.Info("Successfully saved user to cache")
+		if err := ps.cache.Set(ctx, util.GenerateCacheKey("user-profile", user.Id), serialUser, ps.cacheTtl); err != nil {
+			log.Error("Error saving returned user to cache", zap.Error(err))
+		}
+	}
 
-	return user, nil
+	return users, nil
 }
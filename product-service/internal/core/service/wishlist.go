@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"product-service/internal/adapter/logger"
+	"product-service/internal/core/domain"
+	"product-service/internal/core/port"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// shareTokenBytes is the number of random bytes of a share token
+const shareTokenBytes = 16
+
+var errTooManyWishlists = domain.NewCError(http.StatusConflict, fmt.Sprintf("a user can have at most %d wishlists", domain.MaxWishlists))
+
+/**
+ * WishlistService implements port.WishlistService interface
+ */
+type WishlistService struct {
+	repo        port.WishlistRepository
+	productRepo port.ProductRepository
+}
+
+// NewWishlistService creates a new wishlist service instance
+func NewWishlistService(repo port.WishlistRepository, productRepo port.ProductRepository) *WishlistService {
+	return &WishlistService{
+		repo,
+		productRepo,
+	}
+}
+
+func (ws *WishlistService) CreateWishlist(ctx context.Context, userID primitive.ObjectID, req *domain.WishlistRequest) (*domain.WishlistView, domain.CError) {
+	log := logger.FromCtx(ctx)
+
+	count, cerr := ws.repo.CountWishlists(ctx, userID)
+	if cerr != nil {
+		log.Error("Error counting wishlists", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+	if count >= domain.MaxWishlists {
+		return nil, errTooManyWishlists
+	}
+
+	wishlist, cerr := ws.repo.CreateWishlist(ctx, &domain.Wishlist{UserID: userID, Name: req.Name})
+	if cerr != nil {
+		log.Error("Error creating wishlist", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+func (ws *WishlistService) ListWishlists(ctx context.Context, userID primitive.ObjectID) ([]domain.WishlistView, domain.CError) {
+	wishlists, cerr := ws.repo.ListWishlists(ctx, userID)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error listing wishlists", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	var items []domain.WishlistItem
+	for _, w := range wishlists {
+		items = append(items, w.Items...)
+	}
+	products, cerr := ws.wishlistProducts(ctx, items)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	views := make([]domain.WishlistView, 0, len(wishlists))
+	for i := range wishlists {
+		views = append(views, *viewOf(&wishlists[i], products, true))
+	}
+
+	return views, nil
+}
+
+func (ws *WishlistService) GetWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError) {
+	wishlist, cerr := ws.getWishlist(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+func (ws *WishlistService) GetSharedWishlist(ctx context.Context, token string) (*domain.WishlistView, domain.CError) {
+	wishlist, cerr := ws.repo.GetWishlistByShareToken(ctx, token)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting shared wishlist", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	return ws.view(ctx, wishlist, false)
+}
+
+func (ws *WishlistService) RenameWishlist(ctx context.Context, userID, id primitive.ObjectID, req *domain.WishlistRequest) (*domain.WishlistView, domain.CError) {
+	if _, cerr := ws.getWishlist(ctx, userID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	wishlist, cerr := ws.repo.RenameWishlist(ctx, id, req.Name)
+	if cerr != nil {
+		return nil, ws.updateError(ctx, "Error renaming wishlist", cerr)
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+func (ws *WishlistService) DeleteWishlist(ctx context.Context, userID, id primitive.ObjectID) domain.CError {
+	if _, cerr := ws.getWishlist(ctx, userID, id); cerr != nil {
+		return cerr
+	}
+
+	if cerr := ws.repo.DeleteWishlist(ctx, id); cerr != nil {
+		return ws.updateError(ctx, "Error deleting wishlist", cerr)
+	}
+
+	return nil
+}
+
+// AddWishlistItem saves a product that is sold in a wishlist, or changes its quantity when the wishlist already holds it.
+// Products that are out of stock can be saved
+func (ws *WishlistService) AddWishlistItem(ctx context.Context, userID, id primitive.ObjectID, req *domain.WishlistItemRequest) (*domain.WishlistView, domain.CError) {
+	if _, cerr := ws.getWishlist(ctx, userID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	item := domain.WishlistItem{Quantity: req.Quantity}
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	item.ProductID, _ = primitive.ObjectIDFromHex(req.ProductID)
+	if req.VariantID != "" {
+		variantID, _ := primitive.ObjectIDFromHex(req.VariantID)
+		item.VariantID = &variantID
+	}
+
+	products, cerr := ws.wishlistProducts(ctx, []domain.WishlistItem{item})
+	if cerr != nil {
+		return nil, cerr
+	}
+	prod, ok := products[item.ProductID]
+	if !ok {
+		return nil, domain.NewBadRequestCError("product does not exist or is inactive: " + req.ProductID)
+	}
+	if cerr := checkVariant(prod, item.VariantID); cerr != nil {
+		return nil, cerr
+	}
+
+	wishlist, cerr := ws.repo.SaveWishlistItem(ctx, id, &item)
+	if cerr != nil {
+		return nil, ws.updateError(ctx, "Error saving wishlist item", cerr)
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+func (ws *WishlistService) RemoveWishlistItem(ctx context.Context, userID, id, productID primitive.ObjectID, variantID *primitive.ObjectID) (*domain.WishlistView, domain.CError) {
+	if _, cerr := ws.getWishlist(ctx, userID, id); cerr != nil {
+		return nil, cerr
+	}
+
+	wishlist, cerr := ws.repo.RemoveWishlistItem(ctx, id, productID, variantID)
+	if cerr != nil {
+		return nil, ws.updateError(ctx, "Error removing wishlist item", cerr)
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+// ShareWishlist gives the wishlist a share token, keeping the one it has so links already sent keep working
+func (ws *WishlistService) ShareWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError) {
+	wishlist, cerr := ws.getWishlist(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if wishlist.ShareToken == "" {
+		b := make([]byte, shareTokenBytes)
+		if _, err := rand.Read(b); err != nil {
+			logger.FromCtx(ctx).Error("Error generating share token", zap.Error(err))
+			return nil, domain.ErrInternal
+		}
+
+		wishlist, cerr = ws.repo.SetShareToken(ctx, id, base64.RawURLEncoding.EncodeToString(b))
+		if cerr != nil {
+			return nil, ws.updateError(ctx, "Error sharing wishlist", cerr)
+		}
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+// UnshareWishlist removes the share token of the wishlist, so the links sent stop working
+func (ws *WishlistService) UnshareWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistView, domain.CError) {
+	wishlist, cerr := ws.getWishlist(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	if wishlist.ShareToken != "" {
+		wishlist, cerr = ws.repo.SetShareToken(ctx, id, "")
+		if cerr != nil {
+			return nil, ws.updateError(ctx, "Error unsharing wishlist", cerr)
+		}
+	}
+
+	return ws.view(ctx, wishlist, true)
+}
+
+// OrderWishlist builds the order of the items of the wishlist that can be ordered now. The items stay in
+// the wishlist, since the order is only placed once it is sent to the order-service
+func (ws *WishlistService) OrderWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.WishlistOrder, domain.CError) {
+	wishlist, cerr := ws.getWishlist(ctx, userID, id)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	view, cerr := ws.view(ctx, wishlist, true)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	order := domain.WishlistOrder{
+		Order:   domain.OrderRequest{Products: make([]domain.OrderRequestLine, 0, len(view.Items))},
+		Skipped: make([]domain.WishlistItemView, 0),
+	}
+
+	for _, item := range view.Items {
+		if !item.Available {
+			order.Skipped = append(order.Skipped, item)
+			continue
+		}
+
+		line := domain.OrderRequestLine{ProductID: item.ProductID.Hex(), Quantity: int(item.Quantity)}
+		if item.VariantID != nil {
+			line.VariantID = item.VariantID.Hex()
+		}
+		order.Order.Products = append(order.Order.Products, line)
+	}
+
+	return &order, nil
+}
+
+// getWishlist fetches a wishlist of the user. The wishlists of other users are not found, so their ids are not disclosed
+func (ws *WishlistService) getWishlist(ctx context.Context, userID, id primitive.ObjectID) (*domain.Wishlist, domain.CError) {
+	wishlist, cerr := ws.repo.GetWishlist(ctx, id)
+	if cerr != nil {
+		if cerr.Code() == 500 {
+			logger.FromCtx(ctx).Error("Error getting wishlist", zap.Error(cerr))
+			return nil, domain.ErrInternal
+		}
+		return nil, cerr
+	}
+
+	if wishlist.UserID != userID {
+		return nil, domain.ErrDataNotFound
+	}
+
+	return wishlist, nil
+}
+
+// updateError hides the details of unexpected errors of a wishlist update
+func (ws *WishlistService) updateError(ctx context.Context, msg string, cerr domain.CError) domain.CError {
+	if cerr.Code() == 500 {
+		logger.FromCtx(ctx).Error(msg, zap.Error(cerr))
+		return domain.ErrInternal
+	}
+	return cerr
+}
+
+// view fetches the products of a wishlist and shows it with their live price and stock
+func (ws *WishlistService) view(ctx context.Context, wishlist *domain.Wishlist, owner bool) (*domain.WishlistView, domain.CError) {
+	products, cerr := ws.wishlistProducts(ctx, wishlist.Items)
+	if cerr != nil {
+		return nil, cerr
+	}
+
+	return viewOf(wishlist, products, owner), nil
+}
+
+// wishlistProducts fetches the products of wishlist items that are active or out of stock
+func (ws *WishlistService) wishlistProducts(ctx context.Context, items []domain.WishlistItem) (map[primitive.ObjectID]*domain.Product, domain.CError) {
+	products := make(map[primitive.ObjectID]*domain.Product)
+	if len(items) == 0 {
+		return products, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	prods, cerr := ws.productRepo.GetProductsByIDs(ctx, ids)
+	if cerr != nil {
+		logger.FromCtx(ctx).Error("Error getting wishlist products", zap.Error(cerr))
+		return nil, domain.ErrInternal
+	}
+
+	for i := range prods {
+		products[prods[i].ID] = &prods[i]
+	}
+
+	return products, nil
+}
+
+// viewOf shows a wishlist with the products of its items as they are now. The share token is only shown to the owner
+func viewOf(wishlist *domain.Wishlist, products map[primitive.ObjectID]*domain.Product, owner bool) *domain.WishlistView {
+	view := domain.WishlistView{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Items:     make([]domain.WishlistItemView, 0, len(wishlist.Items)),
+		CreatedAt: wishlist.CreatedAt,
+		UpdatedAt: wishlist.UpdatedAt,
+	}
+	if owner {
+		view.ShareToken = wishlist.ShareToken
+	}
+
+	for _, item := range wishlist.Items {
+		view.Items = append(view.Items, itemViewOf(item, products[item.ProductID]))
+	}
+
+	return &view
+}
+
+// itemViewOf shows a wishlist item with the live price and stock of its product, which is nil when it is no longer sold.
+// An item is available when there is enough stock to order its quantity
+func itemViewOf(item domain.WishlistItem, prod *domain.Product) domain.WishlistItemView {
+	view := domain.WishlistItemView{
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  item.Quantity,
+		AddedAt:   item.AddedAt,
+	}
+
+	if prod == nil {
+		view.Reason = "the product is no longer sold"
+		return view
+	}
+
+	view.Name, view.SKU, view.Price, view.InStock = prod.Name, prod.SKU, prod.Price, prod.Quantity
+	if item.VariantID != nil {
+		var variant *domain.Variant
+		for i := range prod.Variants {
+			if prod.Variants[i].ID == *item.VariantID {
+				variant = &prod.Variants[i]
+			}
+		}
+		if variant == nil {
+			view.InStock = 0
+			view.Reason = "the variant is no longer sold"
+			return view
+		}
+		view.SKU, view.Price, view.InStock = variant.SKU, variant.EffectivePrice(prod.Price), variant.Quantity
+	} else if len(prod.Variants) > 0 {
+		view.Reason = "a variant must be chosen"
+		return view
+	}
+
+	switch {
+	case view.InStock <= 0:
+		view.InStock = 0
+		view.Reason = "out of stock"
+	case view.InStock < item.Quantity:
+		view.Reason = fmt.Sprintf("only %d in stock", view.InStock)
+	default:
+		view.Available = true
+	}
+
+	return view
+}